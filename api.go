@@ -4,6 +4,8 @@ package webrtc
 
 import (
 	"github.com/pion/logging"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/stats"
 )
 
 // API bundles the global functions of the WebRTC and ORTC API.
@@ -11,8 +13,19 @@ import (
 // defaultAPI object. Note that the global version of the API
 // may be phased out in the future.
 type API struct {
-	settingEngine *SettingEngine
-	mediaEngine   *MediaEngine
+	settingEngine       *SettingEngine
+	mediaEngine         *MediaEngine
+	interceptorRegistry *interceptor.Registry
+
+	interceptor interceptor.Interceptor // Generated per PeerConnection
+
+	// statsInterceptor records the statistics of the RTP streams of a PeerConnection, it is
+	// part of its Interceptor. It is nil for Senders and Receivers created directly against the API
+	statsInterceptor *stats.Interceptor
+
+	// sendsFEC is true if the Interceptor of a PeerConnection sends FEC packets,
+	// Senders only get a FEC stream then
+	sendsFEC bool
 }
 
 // NewAPI Creates a new API object for keeping semi-global settings to WebRTC objects
@@ -35,6 +48,16 @@ func NewAPI(options ...func(*API)) *API {
 		a.mediaEngine = &MediaEngine{}
 	}
 
+	if a.interceptorRegistry == nil {
+		a.interceptorRegistry = &interceptor.Registry{}
+	}
+
+	// Senders and Receivers created directly against the API (ORTC) don't
+	// have a PeerConnection to own an Interceptor, so they use a NoOp
+	if a.interceptor == nil {
+		a.interceptor = &interceptor.NoOp{}
+	}
+
 	return a
 }
 
@@ -57,3 +80,11 @@ func WithSettingEngine(s SettingEngine) func(a *API) {
 		a.settingEngine = &s
 	}
 }
+
+// WithInterceptorRegistry allows providing Interceptors to the API.
+// Settings should not be changed after passing the registry to an API.
+func WithInterceptorRegistry(interceptorRegistry *interceptor.Registry) func(a *API) {
+	return func(a *API) {
+		a.interceptorRegistry = interceptorRegistry
+	}
+}
@@ -161,13 +161,13 @@ func CertificateFromX509(privateKey crypto.PrivateKey, certificate *x509.Certifi
 }
 
 func (c Certificate) collectStats(report *statsReportCollector) error {
-	report.Collecting()
-
 	fingerPrintAlgo, err := c.GetFingerprints()
 	if err != nil {
 		return err
 	}
 
+	report.Collecting()
+
 	base64Certificate := base64.RawURLEncoding.EncodeToString(c.x509Cert.Raw)
 
 	stats := CertificateStats{
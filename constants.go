@@ -15,5 +15,15 @@ const (
 	// mid and rid values
 	simulcastProbeCount = 10
 
+	// repairStreamChannelSize is the amount of unwrapped RTX packets, and packets
+	// recovered with FEC, that are buffered for a TrackRemote before they are dropped
+	repairStreamChannelSize = 64
+
+	sdesRepairRTPStreamIDURI = "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id"
+
+	// sdpSemanticTokenFECFramework groups a media stream with the FEC stream protecting it
+	// https://tools.ietf.org/html/rfc5956#section-4.1
+	sdpSemanticTokenFECFramework = "FEC-FR"
+
 	mediaSectionApplication = "application"
 )
@@ -1,5 +1,7 @@
 package webrtc
 
+import "encoding/json"
+
 // DataChannelState indicates the state of a data channel.
 type DataChannelState int
 
@@ -59,3 +61,19 @@ func (t DataChannelState) String() string {
 		return ErrUnknownType.Error()
 	}
 }
+
+// MarshalJSON enables JSON marshaling of a DataChannelState
+func (t DataChannelState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON enables JSON unmarshaling of a DataChannelState
+func (t *DataChannelState) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*t = newDataChannelState(s)
+	return nil
+}
@@ -37,6 +37,9 @@ type DTLSTransport struct {
 	state                 DTLSTransportState
 	srtpProtectionProfile srtp.ProtectionProfile
 
+	// remoteCertificateStatsID identifies the stats of remoteCertificate
+	remoteCertificateStatsID string
+
 	onStateChangeHandler func(DTLSTransportState)
 
 	conn *dtls.Conn
@@ -351,6 +354,7 @@ func (t *DTLSTransport) Start(remoteParameters DTLSParameters) error {
 		return errNoRemoteCertificate
 	}
 	t.remoteCertificate = remoteCerts[0]
+	t.remoteCertificateStatsID = fmt.Sprintf("certificate-%d", time.Now().UnixNano())
 
 	parsedRemoteCert, err := x509.ParseCertificate(t.remoteCertificate)
 	if err != nil {
@@ -424,3 +428,70 @@ func (t *DTLSTransport) ensureICEConn() error {
 
 	return nil
 }
+
+func srtpCipherName(profile srtp.ProtectionProfile) string {
+	switch profile {
+	case srtp.ProtectionProfileAes128CmHmacSha1_80:
+		return "AES_CM_128_HMAC_SHA1_80"
+	case srtp.ProtectionProfileAeadAes128Gcm:
+		return "AEAD_AES_128_GCM"
+	default:
+		return ""
+	}
+}
+
+// collectStats collects the stats of the DTLSTransport, along with the ones of the
+// ICETransport it runs over
+func (t *DTLSTransport) collectStats(collector *statsReportCollector) {
+	var stats TransportStats
+	if iceTransport := t.ICETransport(); iceTransport != nil {
+		stats = iceTransport.newTransportStats()
+	} else {
+		stats = TransportStats{
+			Timestamp: statsTimestampNow(),
+			Type:      StatsTypeTransport,
+			ID:        "iceTransport",
+		}
+	}
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	collector.Collecting()
+
+	stats.DTLSState = t.state
+	if t.conn != nil {
+		// DTLS 1.2 is the only version pion/dtls negotiates
+		stats.TLSVersion = "FEFD"
+		if len(t.certificates) > 0 {
+			stats.LocalCertificateID = t.certificates[0].statsID
+		}
+		if t.remoteCertificate != nil {
+			stats.RemoteCertificateID = t.remoteCertificateStatsID
+		}
+		stats.SRTPCipher = srtpCipherName(t.srtpProtectionProfile)
+	}
+
+	collector.Collect(stats.ID, stats)
+}
+
+// collectRemoteCertificateStats collects the stats of the certificate of the remote
+// DTLSTransport
+func (t *DTLSTransport) collectRemoteCertificateStats(collector *statsReportCollector) {
+	t.lock.RLock()
+	remoteCertificate := t.remoteCertificate
+	statsID := t.remoteCertificateStatsID
+	t.lock.RUnlock()
+
+	if remoteCertificate == nil {
+		return
+	}
+
+	x509Cert, err := x509.ParseCertificate(remoteCertificate)
+	if err != nil {
+		return
+	}
+
+	certificate := Certificate{x509Cert: x509Cert, statsID: statsID}
+	_ = certificate.collectStats(collector)
+}
@@ -1,5 +1,7 @@
 package webrtc
 
+import "encoding/json"
+
 // DTLSTransportState indicates the DTLS transport establishment state.
 type DTLSTransportState int
 
@@ -69,3 +71,19 @@ func (t DTLSTransportState) String() string {
 		return ErrUnknownType.Error()
 	}
 }
+
+// MarshalJSON enables JSON marshaling of a DTLSTransportState
+func (t DTLSTransportState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON enables JSON unmarshaling of a DTLSTransportState
+func (t *DTLSTransportState) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*t = newDTLSTransportState(s)
+	return nil
+}
@@ -0,0 +1,281 @@
+// +build !js
+
+package webrtc
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/codecs/telephoneevent"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
+)
+
+const (
+	dtmfMinDuration         = 40 * time.Millisecond
+	dtmfMaxDuration         = 6000 * time.Millisecond
+	dtmfDefaultDuration     = 100 * time.Millisecond
+	dtmfMinInterToneGap     = 30 * time.Millisecond
+	dtmfMaxInterToneGap     = 6000 * time.Millisecond
+	dtmfDefaultInterToneGap = 70 * time.Millisecond
+	dtmfPause               = 2 * time.Second
+
+	// dtmfVolume is the power level the tones are sent with, -10 dBm0
+	dtmfVolume = 10
+
+	// telephoneEventInterval is the time between the packets of an event, updating its duration
+	telephoneEventInterval = 50 * time.Millisecond
+
+	// telephoneEventEndPackets is the number of times the last packet of an event is sent
+	telephoneEventEndPackets = 3
+
+	// telephoneEventMaxDuration is the longest duration of a packet, longer
+	// events are split into segments with their own timestamps
+	telephoneEventMaxDuration = 0xffff
+)
+
+// DTMFSender sends DTMF tones as telephone events (RFC 4733) on the stream of the track of a RTPSender.
+// https://www.w3.org/TR/webrtc/#rtcdtmfsender
+type DTMFSender struct {
+	sender *RTPSender
+	log    logging.LeveledLogger
+
+	mu                  sync.Mutex
+	toneBuffer          string
+	duration            time.Duration
+	interToneGap        time.Duration
+	playing             bool
+	onToneChangeHandler func(tone string)
+
+	// eventEnd is the timestamp the last event sent ended at. The timestamps of the track can
+	// lag behind the clock, an event must not start before the previous one ended
+	eventEnd     uint32
+	hasSentEvent bool
+}
+
+func newDTMFSender(sender *RTPSender) *DTMFSender {
+	return &DTMFSender{
+		sender: sender,
+		log:    sender.api.settingEngine.LoggerFactory.NewLogger("DTMFSender"),
+	}
+}
+
+// CanInsertDTMF returns true if the RTPSender can send DTMF tones. It must be sending a
+// track, and a telephone-event codec must be negotiated at the clock rate of its codec
+func (d *DTMFSender) CanInsertDTMF() bool {
+	_, _, _, ok := d.telephoneEventStream()
+	return ok
+}
+
+// ToneBuffer returns the tones that remain to be played
+func (d *DTMFSender) ToneBuffer() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.toneBuffer
+}
+
+// OnToneChange sets an event handler which is invoked when a tone starts playing, and with
+// an empty tone once all the tones of the buffer were played
+func (d *DTMFSender) OnToneChange(f func(tone string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onToneChangeHandler = f
+}
+
+// InsertDTMF replaces the tone buffer with tones, which are sent after the tone currently playing.
+// Tones are 0-9, A-D, # and *, and ',' which pauses for two seconds. Each tone lasts duration,
+// between 40ms and 6s, and is followed by interToneGap, between 30ms and 6s. Zero values use the
+// defaults of 100ms and 70ms.
+func (d *DTMFSender) InsertDTMF(tones string, duration, interToneGap time.Duration) error {
+	if !d.CanInsertDTMF() {
+		return &rtcerr.InvalidStateError{Err: ErrDTMFSenderCannotInsert}
+	}
+
+	tones = strings.ToUpper(tones)
+	for _, tone := range tones {
+		if _, ok := dtmfToneEvent(tone); !ok && tone != ',' {
+			return &rtcerr.InvalidCharacterError{Err: ErrInvalidDTMFTone}
+		}
+	}
+
+	switch {
+	case duration == 0:
+		duration = dtmfDefaultDuration
+	case duration < dtmfMinDuration:
+		duration = dtmfMinDuration
+	case duration > dtmfMaxDuration:
+		duration = dtmfMaxDuration
+	}
+
+	switch {
+	case interToneGap == 0:
+		interToneGap = dtmfDefaultInterToneGap
+	case interToneGap < dtmfMinInterToneGap:
+		interToneGap = dtmfMinInterToneGap
+	case interToneGap > dtmfMaxInterToneGap:
+		interToneGap = dtmfMaxInterToneGap
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.toneBuffer, d.duration, d.interToneGap = tones, duration, interToneGap
+	if !d.playing && tones != "" {
+		d.playing = true
+		go d.play()
+	}
+
+	return nil
+}
+
+// play sends the tones of the buffer until it is empty, or the RTPSender is stopped
+func (d *DTMFSender) play() {
+	for {
+		d.mu.Lock()
+		if d.toneBuffer == "" {
+			d.playing = false
+			handler := d.onToneChangeHandler
+			d.mu.Unlock()
+
+			if handler != nil {
+				handler("")
+			}
+			return
+		}
+
+		tone := rune(d.toneBuffer[0])
+		d.toneBuffer = d.toneBuffer[1:]
+		duration, interToneGap := d.duration, d.interToneGap
+		handler := d.onToneChangeHandler
+		d.mu.Unlock()
+
+		if handler != nil {
+			handler(string(tone))
+		}
+
+		played := false
+		if event, ok := dtmfToneEvent(tone); ok {
+			played = d.sendEvent(event, duration) && d.wait(interToneGap)
+		} else {
+			played = d.wait(dtmfPause)
+		}
+
+		if !played {
+			d.mu.Lock()
+			d.toneBuffer, d.playing = "", false
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+// sendEvent sends the packets of a telephone event lasting duration. It returns false if it
+// couldn't be sent, because the RTPSender was stopped or doesn't send a track anymore
+// https://tools.ietf.org/html/rfc4733#section-2.5.1
+func (d *DTMFSender) sendEvent(event uint8, duration time.Duration) bool {
+	writeStream, ssrc, codec, ok := d.telephoneEventStream()
+	if !ok {
+		return false
+	}
+
+	header := rtp.Header{
+		Version:     2,
+		Marker:      true,
+		PayloadType: uint8(codec.PayloadType),
+		SSRC:        uint32(ssrc),
+		Timestamp:   writeStream.telephoneEventTimestamp(codec.ClockRate),
+	}
+	if d.hasSentEvent && int32(header.Timestamp-d.eventEnd) < 0 {
+		header.Timestamp = d.eventEnd
+	}
+	send := func(segmentDuration uint64, endOfEvent bool) {
+		payload, err := telephoneevent.Event{
+			Event:      event,
+			EndOfEvent: endOfEvent,
+			Volume:     dtmfVolume,
+			Duration:   uint16(segmentDuration),
+		}.Marshal()
+		if err == nil {
+			_, err = writeStream.writeTelephoneEvent(header, payload)
+		}
+		if err != nil {
+			d.log.Warnf("failed sending telephone event: %v", err)
+		}
+		header.Marker = false
+	}
+
+	remaining := telephoneEventUnits(duration, codec.ClockRate)
+	interval := telephoneEventUnits(telephoneEventInterval, codec.ClockRate)
+	segmentDuration := uint64(0)
+	for remaining > 0 {
+		step := interval
+		if step > remaining {
+			step = remaining
+		}
+		if !d.wait(telephoneEventDuration(step, codec.ClockRate)) {
+			return false
+		}
+		remaining -= step
+		segmentDuration += step
+
+		// A segment ends once its duration doesn't fit in a packet, the next one starts where it ended
+		if segmentDuration >= telephoneEventMaxDuration {
+			send(telephoneEventMaxDuration, false)
+			header.Timestamp += telephoneEventMaxDuration
+			segmentDuration -= telephoneEventMaxDuration
+		}
+
+		if remaining > 0 {
+			send(segmentDuration, false)
+		}
+	}
+
+	for i := 0; i < telephoneEventEndPackets; i++ {
+		send(segmentDuration, true)
+	}
+	d.eventEnd, d.hasSentEvent = header.Timestamp+uint32(segmentDuration), true
+
+	return true
+}
+
+// wait waits for duration, it returns false if the RTPSender was stopped in the meantime
+func (d *DTMFSender) wait(duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-d.sender.stopCalled:
+		return false
+	}
+}
+
+// telephoneEventStream returns the stream of the first encoding of the RTPSender, and the
+// telephone-event codec negotiated at the clock rate of the codec it sends
+func (d *DTMFSender) telephoneEventStream() (*interceptorToTrackLocalWriter, SSRC, RTPCodecParameters, bool) {
+	r := d.sender
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	select {
+	case <-r.stopCalled:
+		return nil, 0, RTPCodecParameters{}, false
+	default:
+	}
+
+	if !r.hasSent() || len(r.trackEncodings) == 0 || r.trackEncodings[0].track == nil {
+		return nil, 0, RTPCodecParameters{}, false
+	}
+
+	e := r.trackEncodings[0]
+	for _, codec := range r.api.mediaEngine.getCodecsByKind(RTPCodecTypeAudio) {
+		if strings.EqualFold(codec.MimeType, mimeTypeTelephoneEvent) && codec.ClockRate == e.codec.ClockRate {
+			return e.writeStream, e.parameters.SSRC, codec, true
+		}
+	}
+
+	return nil, 0, RTPCodecParameters{}, false
+}
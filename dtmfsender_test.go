@@ -0,0 +1,120 @@
+// +build !js
+
+package webrtc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/transport/test"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTMFSender(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	pcOffer, pcAnswer, err := newPair()
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: mimeTypeOpus}, "audio", "pion")
+	assert.NoError(t, err)
+
+	sender, err := pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	dtmf := sender.DTMF()
+	assert.NotNil(t, dtmf)
+	assert.False(t, dtmf.CanInsertDTMF())
+
+	var invalidStateErr *rtcerr.InvalidStateError
+	assert.True(t, errors.As(dtmf.InsertDTMF("1", 0, 0), &invalidStateErr))
+
+	events := make(chan TelephoneEvent, 10)
+	pcAnswer.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+		track.OnTelephoneEvent(func(e TelephoneEvent) {
+			events <- e
+		})
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+			assert.Equal(t, uint8(track.PayloadType()), p.PayloadType)
+		}
+	})
+
+	tones := make(chan string, 10)
+	dtmf.OnToneChange(func(tone string) {
+		tones <- tone
+	})
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	done := make(chan struct{})
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0x00}, Duration: 20 * time.Millisecond}))
+			}
+		}
+	}()
+
+	for !dtmf.CanInsertDTMF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var invalidCharacterErr *rtcerr.InvalidCharacterError
+	assert.True(t, errors.As(dtmf.InsertDTMF("1x", 0, 0), &invalidCharacterErr))
+
+	assert.NoError(t, dtmf.InsertDTMF("1#", 100*time.Millisecond, 50*time.Millisecond))
+	for _, expected := range []string{"1", "#", ""} {
+		assert.Equal(t, expected, <-tones)
+	}
+
+	for _, expected := range []TelephoneEvent{
+		{Event: 1, Tone: "1"},
+		{Event: 1, Tone: "1", EndOfEvent: true, Duration: 100 * time.Millisecond},
+		{Event: 11, Tone: "#"},
+		{Event: 11, Tone: "#", EndOfEvent: true, Duration: 100 * time.Millisecond},
+	} {
+		e := <-events
+		e.Volume = 0
+		if !expected.EndOfEvent {
+			e.Duration = 0
+		}
+		assert.Equal(t, expected, e)
+	}
+	assert.Equal(t, "", dtmf.ToneBuffer())
+
+	close(done)
+	<-writerDone
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+func TestDTMFSender_Video(t *testing.T) {
+	pc, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	sender, err := pc.AddTrack(track)
+	assert.NoError(t, err)
+	assert.Nil(t, sender.DTMF())
+
+	assert.NoError(t, pc.Close())
+}
@@ -141,6 +141,39 @@ var (
 	// ErrNoPayloaderForCodec indicates that the requested codec does not have a payloader
 	ErrNoPayloaderForCodec = errors.New("the requested codec does not have a payloader")
 
+	// ErrModifyingSendEncodings indicates that SetParameters was called with encodings that were added,
+	// removed, reordered or had their RID or SSRCs changed
+	ErrModifyingSendEncodings = errors.New("encodings can not be added, removed or have their RID or SSRCs changed")
+
+	// ErrInvalidScaleResolutionDownBy indicates that SetParameters was called with a ScaleResolutionDownBy less than 1
+	ErrInvalidScaleResolutionDownBy = errors.New("ScaleResolutionDownBy must be greater than or equal to 1")
+
+	// ErrInvalidMaxFramerate indicates that SetParameters was called with a negative MaxFramerate
+	ErrInvalidMaxFramerate = errors.New("MaxFramerate must not be negative")
+
+	// ErrRTPTransceiverCodecUnsupported indicates that SetCodecPreferences was called with a codec
+	// that isn't registered in the MediaEngine for the kind of the RTPTransceiver
+	ErrRTPTransceiverCodecUnsupported = errors.New("codec is not registered in the MediaEngine")
+
+	// ErrRTPTransceiverCodecPreferencesRTXOnly indicates that SetCodecPreferences was called with
+	// RTX codecs only, without any codec to send the media with
+	ErrRTPTransceiverCodecPreferencesRTXOnly = errors.New("codec preferences must contain a codec other than RTX")
+
+	// ErrDTMFSenderCannotInsert indicates that InsertDTMF was called while the RTPSender isn't sending,
+	// or without a telephone-event codec negotiated at the clock rate of the codec it sends
+	ErrDTMFSenderCannotInsert = errors.New("RTPSender can not send DTMF tones")
+
+	// ErrInvalidDTMFTone indicates that InsertDTMF was called with tones other than 0-9, A-D, #, * and ','
+	ErrInvalidDTMFTone = errors.New("DTMF tones must be one of 0-9, A-D, #, * and ','")
+
+	// ErrHeaderExtensionNotNegotiated indicates that a header extension was set or read
+	// on a track, while the extension wasn't negotiated with the remote
+	ErrHeaderExtensionNotNegotiated = errors.New("header extension was not negotiated")
+
+	// ErrRegisterHeaderExtensionInvalidDirection indicates that RegisterHeaderExtension was called with
+	// allowed directions other than RTPTransceiverDirectionSendonly and RTPTransceiverDirectionRecvonly
+	ErrRegisterHeaderExtensionInvalidDirection = errors.New("header extension directions must be sendonly or recvonly")
+
 	errDetachNotEnabled                 = errors.New("enable detaching by calling webrtc.DetachDataChannels()")
 	errDetachBeforeOpened               = errors.New("datachannel not opened yet, try calling Detach from OnOpen")
 	errDtlsTransportNotStarted          = errors.New("the DTLS transport has not started yet")
@@ -163,6 +196,8 @@ var (
 
 	errNetworkTypeUnknown = errors.New("unknown network type")
 
+	errH264ProfileLevelIDInvalid = errors.New("invalid H264 profile-level-id")
+
 	errSDPDoesNotMatchOffer                           = errors.New("new sdp does not match previous offer")
 	errSDPDoesNotMatchAnswer                          = errors.New("new sdp does not match previous answer")
 	errPeerConnSDPTypeInvalidValue                    = errors.New("provided value is not a valid enum value of type SDPType")
@@ -189,10 +224,15 @@ var (
 	errRTPReceiverWithSSRCTrackStreamNotFound = errors.New("unable to find stream for Track with SSRC")
 	errRTPReceiverForSSRCTrackStreamNotFound  = errors.New("no trackStreams found for SSRC")
 	errRTPReceiverForRIDTrackStreamNotFound   = errors.New("no trackStreams found for RID")
+	errRTPReceiverRepairStreamAlreadyExists   = errors.New("a repair stream already exists for RID")
 
-	errRTPSenderTrackNil          = errors.New("Track must not be nil")
-	errRTPSenderDTLSTransportNil  = errors.New("DTLSTransport must not be nil")
-	errRTPSenderSendAlreadyCalled = errors.New("Send has already been called")
+	errRTPSenderTrackNil               = errors.New("Track must not be nil")
+	errRTPSenderDTLSTransportNil       = errors.New("DTLSTransport must not be nil")
+	errRTPSenderSendAlreadyCalled      = errors.New("Send has already been called")
+	errRTPSenderRIDNil                 = errors.New("RID must be set for every encoding when sending Simulcast")
+	errRTPSenderRIDCollision           = errors.New("RID is already used by another encoding")
+	errRTPSenderEncodingForRIDNotFound = errors.New("no encoding found for RID")
+	errRTPSenderStopped                = errors.New("RTPSender has been stopped")
 
 	errRTPTransceiverCannotChangeMid        = errors.New("errRTPSenderTrackNil")
 	errRTPTransceiverSetSendingInvalidState = errors.New("invalid state change in RTPTransceiver.setSending")
@@ -202,11 +242,16 @@ var (
 	errSDPZeroTransceivers                 = errors.New("addTransceiverSDP() called with 0 transceivers")
 	errSDPMediaSectionMediaDataChanInvalid = errors.New("invalid Media Section. Media + DataChannel both enabled")
 	errSDPMediaSectionMultipleTrackInvalid = errors.New("invalid Media Section. Can not have multiple tracks in one MediaSection in UnifiedPlan")
+	errSDPPlanBSimulcast                   = errors.New("invalid Media Section. Can not send Simulcast encodings identified by RID in PlanB")
 
 	errSettingEngineSetAnsweringDTLSRole = errors.New("SetAnsweringDTLSRole must DTLSRoleClient or DTLSRoleServer")
 
 	errSignalingStateCannotRollback            = errors.New("can't rollback from stable state")
 	errSignalingStateProposedTransitionInvalid = errors.New("invalid proposed signaling state transition")
 
+	errTrackLocalStaticNoCodecs          = errors.New("TrackLocal must have at least one codec")
+	errTrackLocalStaticCodecKindMismatch = errors.New("all codecs of a TrackLocal must be of the same kind")
+
 	errStatsICECandidateStateInvalid = errors.New("cannot convert to StatsICECandidatePairStateSucceeded invalid ice candidate state")
+	errStatsTypeUnknown              = errors.New("unknown stats type")
 )
@@ -0,0 +1,332 @@
+package webrtc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// fmtp is the parsed a=fmtp line of a codec
+type fmtp interface {
+	// mimeType returns the MimeType of the codec the parameters belong to
+	mimeType() string
+	// match returns true if a single codec can be negotiated for both parameters
+	match(f fmtp) bool
+	// parameter returns the value of a parameter
+	parameter(key string) (string, bool)
+}
+
+// parseFmtp parses a a=fmtp line of the form "key1=value1;key2=value2"
+func parseFmtp(mimeType, line string) fmtp {
+	parameters := map[string]string{}
+	for _, p := range strings.Split(line, ";") {
+		pp := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		key := strings.ToLower(pp[0])
+		if key == "" {
+			continue
+		}
+
+		value := ""
+		if len(pp) > 1 {
+			value = pp[1]
+		}
+		parameters[key] = value
+	}
+
+	switch strings.ToLower(mimeType) {
+	case mimeTypeH264:
+		return &h264Fmtp{parameters: parameters}
+	case mimeTypeVP9:
+		return &vp9Fmtp{parameters: parameters}
+	case mimeTypeH265:
+		return &h265Fmtp{parameters: parameters}
+	default:
+		return &genericFmtp{mime: mimeType, parameters: parameters}
+	}
+}
+
+// setFmtpParameter sets the value of a parameter of a a=fmtp line, the other parameters are kept as is
+func setFmtpParameter(line, key, value string) string {
+	parameters := []string{}
+	if strings.TrimSpace(line) != "" {
+		parameters = strings.Split(line, ";")
+	}
+
+	for i, p := range parameters {
+		pp := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if strings.EqualFold(pp[0], key) {
+			parameters[i] = pp[0] + "=" + value
+			return strings.Join(parameters, ";")
+		}
+	}
+
+	return strings.Join(append(parameters, key+"="+value), ";")
+}
+
+// removeFmtpParameter removes a parameter from a a=fmtp line, and returns its value
+func removeFmtpParameter(line, key string) (string, string) {
+	parameters := strings.Split(line, ";")
+	for i, p := range parameters {
+		pp := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if !strings.EqualFold(pp[0], key) {
+			continue
+		}
+
+		value := ""
+		if len(pp) > 1 {
+			value = pp[1]
+		}
+		return strings.TrimSpace(strings.Join(append(parameters[:i], parameters[i+1:]...), ";")), value
+	}
+
+	return line, ""
+}
+
+// genericFmtp matches if all parameters are equal
+type genericFmtp struct {
+	mime       string
+	parameters map[string]string
+}
+
+func (g *genericFmtp) mimeType() string {
+	return g.mime
+}
+
+func (g *genericFmtp) match(f fmtp) bool {
+	other, ok := f.(*genericFmtp)
+	if !ok || !strings.EqualFold(g.mime, other.mime) || len(g.parameters) != len(other.parameters) {
+		return false
+	}
+
+	for k, v := range g.parameters {
+		if otherValue, ok := other.parameters[k]; !ok || !strings.EqualFold(v, otherValue) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g *genericFmtp) parameter(key string) (string, bool) {
+	v, ok := g.parameters[key]
+	return v, ok
+}
+
+// h264Fmtp matches if the packetization mode and the profile are the same. The level may differ,
+// the answer is limited to the level of the offer unless both sides allow level asymmetry.
+// https://tools.ietf.org/html/rfc6184#section-8.1
+type h264Fmtp struct {
+	parameters map[string]string
+}
+
+// h264Profile is a H264 profile, as identified by profile_idc and the constraint flags of profile_iop
+type h264Profile int
+
+const (
+	h264ProfileUnknown h264Profile = iota
+	h264ProfileConstrainedBaseline
+	h264ProfileBaseline
+	h264ProfileMain
+	h264ProfileConstrainedHigh
+	h264ProfileHigh
+	h264ProfilePredictiveHigh444
+)
+
+// h264ProfilePatterns maps profile_idc and the bits of profile_iop set in mask to a profile.
+// A profile_iop may match several patterns, the first one wins.
+// https://tools.ietf.org/html/rfc6184#section-8.1 Table 5
+var h264ProfilePatterns = []struct {
+	profileIdc  byte
+	mask, value byte
+	profile     h264Profile
+}{
+	{0x42, 0x4F, 0x40, h264ProfileConstrainedBaseline}, // x1xx0000
+	{0x4D, 0x8F, 0x80, h264ProfileConstrainedBaseline}, // 1xxx0000
+	{0x58, 0xCF, 0xC0, h264ProfileConstrainedBaseline}, // 11xx0000
+	{0x42, 0x4F, 0x00, h264ProfileBaseline},            // x0xx0000
+	{0x58, 0xCF, 0x80, h264ProfileBaseline},            // 10xx0000
+	{0x4D, 0xAF, 0x00, h264ProfileMain},                // 0x0x0000
+	{0x64, 0xFF, 0x00, h264ProfileHigh},                // 00000000
+	{0x64, 0xFF, 0x0C, h264ProfileConstrainedHigh},     // 00001100
+	{0xF4, 0xFF, 0x00, h264ProfilePredictiveHigh444},   // 00000000
+}
+
+const (
+	h264DefaultProfileLevelID    = "42000a"
+	h264DefaultPacketizationMode = "0"
+)
+
+// parseH264ProfileLevelID returns the profile and level_idc of a profile-level-id
+func parseH264ProfileLevelID(profileLevelID string) (h264Profile, byte, error) {
+	b, err := hex.DecodeString(profileLevelID)
+	if err != nil {
+		return h264ProfileUnknown, 0, err
+	} else if len(b) != 3 {
+		return h264ProfileUnknown, 0, fmt.Errorf("%w: %s", errH264ProfileLevelIDInvalid, profileLevelID)
+	}
+
+	for _, p := range h264ProfilePatterns {
+		if b[0] == p.profileIdc && b[1]&p.mask == p.value {
+			return p.profile, b[2], nil
+		}
+	}
+
+	return h264ProfileUnknown, 0, fmt.Errorf("%w: %s", errH264ProfileLevelIDInvalid, profileLevelID)
+}
+
+func (h *h264Fmtp) mimeType() string {
+	return mimeTypeH264
+}
+
+func (h *h264Fmtp) match(f fmtp) bool {
+	other, ok := f.(*h264Fmtp)
+	if !ok {
+		return false
+	}
+
+	if h.parameterOrDefault("packetization-mode", h264DefaultPacketizationMode) !=
+		other.parameterOrDefault("packetization-mode", h264DefaultPacketizationMode) {
+		return false
+	}
+
+	profileLevelID := h.parameterOrDefault("profile-level-id", h264DefaultProfileLevelID)
+	otherProfileLevelID := other.parameterOrDefault("profile-level-id", h264DefaultProfileLevelID)
+
+	profile, _, err := parseH264ProfileLevelID(profileLevelID)
+	otherProfile, _, otherErr := parseH264ProfileLevelID(otherProfileLevelID)
+	if err != nil || otherErr != nil {
+		// Profiles we don't know only match themselves
+		return strings.EqualFold(profileLevelID, otherProfileLevelID)
+	}
+
+	return profile == otherProfile
+}
+
+func (h *h264Fmtp) parameter(key string) (string, bool) {
+	v, ok := h.parameters[key]
+	return v, ok
+}
+
+func (h *h264Fmtp) parameterOrDefault(key, defaultValue string) string {
+	if v, ok := h.parameters[key]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+// levelAsymmetryAllowed returns true if the level of each direction may differ
+func (h *h264Fmtp) levelAsymmetryAllowed() bool {
+	return h.parameterOrDefault("level-asymmetry-allowed", "0") == "1"
+}
+
+// h264AnswerFmtpLine returns the fmtp line to negotiate a remote H264 codec with a matching local one.
+// Unless both allow level asymmetry, the level of the remote fmtp line is lowered to the local level.
+// Level 1b is not special cased, it is treated as level 1.1.
+func h264AnswerFmtpLine(local, remote RTPCodecCapability) string {
+	localFmtp, ok := parseFmtp(local.MimeType, local.SDPFmtpLine).(*h264Fmtp)
+	if !ok {
+		return remote.SDPFmtpLine
+	}
+	remoteFmtp, ok := parseFmtp(remote.MimeType, remote.SDPFmtpLine).(*h264Fmtp)
+	if !ok || localFmtp.levelAsymmetryAllowed() && remoteFmtp.levelAsymmetryAllowed() {
+		return remote.SDPFmtpLine
+	}
+
+	_, localLevel, err := parseH264ProfileLevelID(localFmtp.parameterOrDefault("profile-level-id", h264DefaultProfileLevelID))
+	if err != nil {
+		return remote.SDPFmtpLine
+	}
+	remoteProfileLevelID, ok := remoteFmtp.parameter("profile-level-id")
+	if !ok {
+		return remote.SDPFmtpLine
+	}
+	_, remoteLevel, err := parseH264ProfileLevelID(remoteProfileLevelID)
+	if err != nil || remoteLevel <= localLevel {
+		return remote.SDPFmtpLine
+	}
+
+	parameters := strings.Split(remote.SDPFmtpLine, ";")
+	for i, p := range parameters {
+		pp := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if strings.EqualFold(pp[0], "profile-level-id") {
+			parameters[i] = fmt.Sprintf("%s=%s%02x", pp[0], remoteProfileLevelID[:4], localLevel)
+		}
+	}
+
+	return strings.Join(parameters, ";")
+}
+
+// vp9Fmtp matches if the profile is the same
+// https://tools.ietf.org/html/draft-ietf-payload-vp9-16#section-6
+type vp9Fmtp struct {
+	parameters map[string]string
+}
+
+func (v *vp9Fmtp) mimeType() string {
+	return mimeTypeVP9
+}
+
+func (v *vp9Fmtp) match(f fmtp) bool {
+	other, ok := f.(*vp9Fmtp)
+	if !ok {
+		return false
+	}
+
+	profile, ok := v.parameter("profile-id")
+	if !ok {
+		profile = "0"
+	}
+	otherProfile, ok := other.parameter("profile-id")
+	if !ok {
+		otherProfile = "0"
+	}
+
+	return profile == otherProfile
+}
+
+func (v *vp9Fmtp) parameter(key string) (string, bool) {
+	p, ok := v.parameters[key]
+	return p, ok
+}
+
+// h265Fmtp matches if the profile is the same. The level may differ, each side
+// sends at a level supported by the other.
+// https://tools.ietf.org/html/rfc7798#section-7.2.2
+type h265Fmtp struct {
+	parameters map[string]string
+}
+
+func (h *h265Fmtp) mimeType() string {
+	return mimeTypeH265
+}
+
+func (h *h265Fmtp) match(f fmtp) bool {
+	other, ok := f.(*h265Fmtp)
+	if !ok {
+		return false
+	}
+
+	for key, defaultValue := range map[string]string{
+		"profile-space": "0",
+		"profile-id":    "1",
+		"tier-flag":     "0",
+	} {
+		if h.parameterOrDefault(key, defaultValue) != other.parameterOrDefault(key, defaultValue) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h *h265Fmtp) parameter(key string) (string, bool) {
+	v, ok := h.parameters[key]
+	return v, ok
+}
+
+func (h *h265Fmtp) parameterOrDefault(key, defaultValue string) string {
+	if v, ok := h.parameters[key]; ok {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,122 @@
+package webrtc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFmtp(t *testing.T) {
+	f := parseFmtp("audio/opus", "minptime=10; useinbandfec=1;flag")
+	assert.Equal(t, "audio/opus", f.mimeType())
+
+	for key, expected := range map[string]string{"minptime": "10", "useinbandfec": "1", "flag": ""} {
+		value, ok := f.parameter(key)
+		assert.True(t, ok)
+		assert.Equal(t, expected, value)
+	}
+
+	_, ok := f.parameter("stereo")
+	assert.False(t, ok)
+}
+
+func TestFmtpMatch(t *testing.T) {
+	for _, test := range []struct {
+		name                               string
+		mimeTypeA, lineA, mimeTypeB, lineB string
+		match                              bool
+	}{
+		{"Generic same parameters", "audio/opus", "minptime=10;useinbandfec=1", "audio/OPUS", "useinbandfec=1; minptime=10", true},
+		{"Generic different parameters", "audio/opus", "minptime=10;useinbandfec=1", "audio/opus", "minptime=10", false},
+		{"Generic different mime type", "video/vp8", "", "video/vp9", "", false},
+		{"H265 default profile", "video/H265", "", "video/h265", "profile-id=1;level-id=93", true},
+		{"H265 different level", "video/H265", "profile-id=1;level-id=120", "video/H265", "profile-id=1;level-id=93", true},
+		{"H265 different profile", "video/H265", "profile-id=1", "video/H265", "profile-id=2", false},
+		{"H265 different tier", "video/H265", "tier-flag=0", "video/H265", "tier-flag=1", false},
+		{"H265 and generic", "video/H265", "", "video/vp8", "", false},
+		{"H264 same profile", "video/H264", "packetization-mode=1;profile-level-id=42e01f", "video/h264", "profile-level-id=42e034;packetization-mode=1", true},
+		{"H264 constrained baseline with baseline constraint flag", "video/H264", "packetization-mode=1;profile-level-id=42e01f", "video/H264", "packetization-mode=1;profile-level-id=42c01f", true},
+		{"H264 constrained baseline and baseline", "video/H264", "packetization-mode=1;profile-level-id=42e01f", "video/H264", "packetization-mode=1;profile-level-id=42001f", false},
+		{"H264 constrained baseline and main", "video/H264", "packetization-mode=1;profile-level-id=42e01f", "video/H264", "packetization-mode=1;profile-level-id=4d001f", false},
+		{"H264 different packetization mode", "video/H264", "packetization-mode=1;profile-level-id=42e01f", "video/H264", "packetization-mode=0;profile-level-id=42e01f", false},
+		{"H264 default packetization mode", "video/H264", "profile-level-id=42e01f", "video/H264", "packetization-mode=0;profile-level-id=42e01f", true},
+		{"H264 default profile", "video/H264", "packetization-mode=1", "video/H264", "packetization-mode=1;profile-level-id=42001f", true},
+		{"H264 unknown profile", "video/H264", "profile-level-id=7a001f", "video/H264", "profile-level-id=7A001F", true},
+		{"H264 invalid profile", "video/H264", "profile-level-id=42e0", "video/H264", "profile-level-id=42e01f", false},
+		{"VP9 same profile", "video/VP9", "profile-id=0", "video/vp9", "", true},
+		{"VP9 different profile", "video/VP9", "profile-id=0", "video/VP9", "profile-id=2", false},
+	} {
+		a, b := parseFmtp(test.mimeTypeA, test.lineA), parseFmtp(test.mimeTypeB, test.lineB)
+		assert.Equal(t, test.match, a.match(b), test.name)
+		assert.Equal(t, test.match, b.match(a), test.name)
+	}
+}
+
+func TestCodecParametersFuzzySearch(t *testing.T) {
+	h264 := func(payloadType PayloadType, line string) RTPCodecParameters {
+		return RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeH264, ClockRate: 90000, SDPFmtpLine: line}, PayloadType: payloadType}
+	}
+	vp8 := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeVP8, ClockRate: 90000, SDPFmtpLine: "max-fr=30"}, PayloadType: 96}
+
+	// An empty fmtp line is the default parameters of H264, packetization-mode=0
+	_, err := codecParametersFuzzySearch(h264(0, ""), []RTPCodecParameters{h264(102, "packetization-mode=1;profile-level-id=42001f")})
+	assert.True(t, errors.Is(err, ErrCodecNotFound))
+
+	codec, err := codecParametersFuzzySearch(h264(0, ""), []RTPCodecParameters{
+		h264(102, "packetization-mode=1;profile-level-id=42001f"),
+		h264(127, "packetization-mode=0;profile-level-id=42001f"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, PayloadType(127), codec.PayloadType)
+
+	// A local capability without fmtp line is sent with packetization-mode=1
+	codec, err = codecCapabilityFuzzySearch(RTPCodecCapability{MimeType: mimeTypeH264, ClockRate: 90000}, []RTPCodecParameters{
+		h264(127, "packetization-mode=0;profile-level-id=42001f"),
+		h264(102, "packetization-mode=1;profile-level-id=42001f"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, PayloadType(102), codec.PayloadType)
+
+	// Codecs without fmtp semantics fall back to the MimeType
+	codec, err = codecParametersFuzzySearch(RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: "video/vp8"}}, []RTPCodecParameters{vp8})
+	assert.NoError(t, err)
+	assert.Equal(t, PayloadType(96), codec.PayloadType)
+}
+
+func TestH264AnswerFmtpLine(t *testing.T) {
+	local := RTPCodecCapability{MimeType: mimeTypeH264, SDPFmtpLine: "packetization-mode=1;profile-level-id=42e01f"}
+
+	for _, test := range []struct {
+		name, remote, answer string
+	}{
+		{"Lower level", "packetization-mode=1;profile-level-id=42e015", "packetization-mode=1;profile-level-id=42e015"},
+		{"Higher level", "packetization-mode=1; profile-level-id=42e034", "packetization-mode=1;profile-level-id=42e01f"},
+		{"Level asymmetry not allowed locally", "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e034", "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f"},
+	} {
+		assert.Equal(t, test.answer, h264AnswerFmtpLine(local, RTPCodecCapability{MimeType: "video/H264", SDPFmtpLine: test.remote}), test.name)
+	}
+
+	local.SDPFmtpLine = "level-asymmetry-allowed=1;" + local.SDPFmtpLine
+	remote := RTPCodecCapability{MimeType: "video/H264", SDPFmtpLine: "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e034"}
+	assert.Equal(t, remote.SDPFmtpLine, h264AnswerFmtpLine(local, remote), "Level asymmetry allowed")
+
+	remote = RTPCodecCapability{MimeType: "video/VP8", SDPFmtpLine: "profile-level-id=42e034"}
+	assert.Equal(t, remote.SDPFmtpLine, h264AnswerFmtpLine(local, remote), "Not H264")
+}
+
+func TestSetFmtpParameter(t *testing.T) {
+	assert.Equal(t, "usedtx=1", setFmtpParameter("", "usedtx", "1"))
+	assert.Equal(t, "minptime=10; useinbandfec=1;usedtx=1", setFmtpParameter("minptime=10; useinbandfec=1", "usedtx", "1"))
+	assert.Equal(t, "minptime=10;USEDTX=1", setFmtpParameter("minptime=10; USEDTX=0", "usedtx", "1"))
+}
+
+func TestRemoveFmtpParameter(t *testing.T) {
+	line, value := removeFmtpParameter("minptime=10; ptime=20;useinbandfec=1", "ptime")
+	assert.Equal(t, "minptime=10;useinbandfec=1", line)
+	assert.Equal(t, "20", value)
+
+	line, value = removeFmtpParameter("minptime=10; useinbandfec=1", "ptime")
+	assert.Equal(t, "minptime=10; useinbandfec=1", line)
+	assert.Equal(t, "", value)
+}
@@ -1,6 +1,7 @@
 package webrtc
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/pion/ice/v2"
@@ -92,3 +93,20 @@ func getCandidateType(candidateType ice.CandidateType) (ICECandidateType, error)
 		return ICECandidateType(Unknown), err
 	}
 }
+
+// MarshalJSON enables JSON marshaling of a ICECandidateType
+func (t ICECandidateType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON enables JSON unmarshaling of a ICECandidateType
+func (t *ICECandidateType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	var err error
+	*t, err = NewICECandidateType(s)
+	return err
+}
@@ -3,6 +3,7 @@
 package webrtc
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 
@@ -142,6 +143,10 @@ func (g *ICEGatherer) Gather() error {
 	agent := g.agent
 	g.lock.Unlock()
 
+	if agent == nil {
+		return fmt.Errorf("%w: unable to gather", errICEAgentNotExist)
+	}
+
 	g.setState(ICEGathererStateGathering)
 	if err := agent.OnCandidate(func(candidate ice.Candidate) {
 		onLocalCandidateHandler := func(*ICECandidate) {}
@@ -178,10 +183,12 @@ func (g *ICEGatherer) Close() error {
 	g.lock.Lock()
 	defer g.lock.Unlock()
 
-	if g.agent == nil {
-		return nil
-	} else if err := g.agent.Close(); err != nil {
-		return err
+	// Mark the ICEGatherer closed even if no agent was created yet, otherwise
+	// a racing Gather would create an agent that is never closed.
+	if g.agent != nil {
+		if err := g.agent.Close(); err != nil {
+			return err
+		}
 	}
 
 	g.agent = nil
@@ -251,6 +258,46 @@ func (g *ICEGatherer) getAgent() *ice.Agent {
 	return g.agent
 }
 
+func toICECandidatePairStats(candidatePairStats ice.CandidatePairStats) (ICECandidatePairStats, error) {
+	state, err := toStatsICECandidatePairState(candidatePairStats.State)
+
+	pairID := newICECandidatePairStatsID(candidatePairStats.LocalCandidateID,
+		candidatePairStats.RemoteCandidateID)
+
+	return ICECandidatePairStats{
+		Timestamp:                   statsTimestampFrom(candidatePairStats.Timestamp),
+		Type:                        StatsTypeCandidatePair,
+		ID:                          pairID,
+		TransportID:                 "iceTransport",
+		LocalCandidateID:            candidatePairStats.LocalCandidateID,
+		RemoteCandidateID:           candidatePairStats.RemoteCandidateID,
+		State:                       state,
+		Nominated:                   candidatePairStats.Nominated,
+		PacketsSent:                 candidatePairStats.PacketsSent,
+		PacketsReceived:             candidatePairStats.PacketsReceived,
+		BytesSent:                   candidatePairStats.BytesSent,
+		BytesReceived:               candidatePairStats.BytesReceived,
+		LastPacketSentTimestamp:     statsTimestampFrom(candidatePairStats.LastPacketSentTimestamp),
+		LastPacketReceivedTimestamp: statsTimestampFrom(candidatePairStats.LastPacketReceivedTimestamp),
+		FirstRequestTimestamp:       statsTimestampFrom(candidatePairStats.FirstRequestTimestamp),
+		LastRequestTimestamp:        statsTimestampFrom(candidatePairStats.LastRequestTimestamp),
+		LastResponseTimestamp:       statsTimestampFrom(candidatePairStats.LastResponseTimestamp),
+		TotalRoundTripTime:          candidatePairStats.TotalRoundTripTime,
+		CurrentRoundTripTime:        candidatePairStats.CurrentRoundTripTime,
+		AvailableOutgoingBitrate:    candidatePairStats.AvailableOutgoingBitrate,
+		AvailableIncomingBitrate:    candidatePairStats.AvailableIncomingBitrate,
+		CircuitBreakerTriggerCount:  candidatePairStats.CircuitBreakerTriggerCount,
+		RequestsReceived:            candidatePairStats.RequestsReceived,
+		RequestsSent:                candidatePairStats.RequestsSent,
+		ResponsesReceived:           candidatePairStats.ResponsesReceived,
+		ResponsesSent:               candidatePairStats.ResponsesSent,
+		RetransmissionsReceived:     candidatePairStats.RetransmissionsReceived,
+		RetransmissionsSent:         candidatePairStats.RetransmissionsSent,
+		ConsentRequestsSent:         candidatePairStats.ConsentRequestsSent,
+		ConsentExpiredTimestamp:     statsTimestampFrom(candidatePairStats.ConsentExpiredTimestamp),
+	}, err
+}
+
 func (g *ICEGatherer) collectStats(collector *statsReportCollector) {
 	agent := g.getAgent()
 	if agent == nil {
@@ -262,46 +309,11 @@ func (g *ICEGatherer) collectStats(collector *statsReportCollector) {
 		for _, candidatePairStats := range agent.GetCandidatePairsStats() {
 			collector.Collecting()
 
-			state, err := toStatsICECandidatePairState(candidatePairStats.State)
+			stats, err := toICECandidatePairStats(candidatePairStats)
 			if err != nil {
 				g.log.Error(err.Error())
 			}
 
-			pairID := newICECandidatePairStatsID(candidatePairStats.LocalCandidateID,
-				candidatePairStats.RemoteCandidateID)
-
-			stats := ICECandidatePairStats{
-				Timestamp: statsTimestampFrom(candidatePairStats.Timestamp),
-				Type:      StatsTypeCandidatePair,
-				ID:        pairID,
-				// TransportID:
-				LocalCandidateID:            candidatePairStats.LocalCandidateID,
-				RemoteCandidateID:           candidatePairStats.RemoteCandidateID,
-				State:                       state,
-				Nominated:                   candidatePairStats.Nominated,
-				PacketsSent:                 candidatePairStats.PacketsSent,
-				PacketsReceived:             candidatePairStats.PacketsReceived,
-				BytesSent:                   candidatePairStats.BytesSent,
-				BytesReceived:               candidatePairStats.BytesReceived,
-				LastPacketSentTimestamp:     statsTimestampFrom(candidatePairStats.LastPacketSentTimestamp),
-				LastPacketReceivedTimestamp: statsTimestampFrom(candidatePairStats.LastPacketReceivedTimestamp),
-				FirstRequestTimestamp:       statsTimestampFrom(candidatePairStats.FirstRequestTimestamp),
-				LastRequestTimestamp:        statsTimestampFrom(candidatePairStats.LastRequestTimestamp),
-				LastResponseTimestamp:       statsTimestampFrom(candidatePairStats.LastResponseTimestamp),
-				TotalRoundTripTime:          candidatePairStats.TotalRoundTripTime,
-				CurrentRoundTripTime:        candidatePairStats.CurrentRoundTripTime,
-				AvailableOutgoingBitrate:    candidatePairStats.AvailableOutgoingBitrate,
-				AvailableIncomingBitrate:    candidatePairStats.AvailableIncomingBitrate,
-				CircuitBreakerTriggerCount:  candidatePairStats.CircuitBreakerTriggerCount,
-				RequestsReceived:            candidatePairStats.RequestsReceived,
-				RequestsSent:                candidatePairStats.RequestsSent,
-				ResponsesReceived:           candidatePairStats.ResponsesReceived,
-				ResponsesSent:               candidatePairStats.ResponsesSent,
-				RetransmissionsReceived:     candidatePairStats.RetransmissionsReceived,
-				RetransmissionsSent:         candidatePairStats.RetransmissionsSent,
-				ConsentRequestsSent:         candidatePairStats.ConsentRequestsSent,
-				ConsentExpiredTimestamp:     statsTimestampFrom(candidatePairStats.ConsentExpiredTimestamp),
-			}
 			collector.Collect(stats.ID, stats)
 		}
 
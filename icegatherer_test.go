@@ -4,6 +4,7 @@ package webrtc
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -97,3 +98,19 @@ func TestICEGather_mDNSCandidateGathering(t *testing.T) {
 	<-gotMulticastDNSCandidate.Done()
 	assert.NoError(t, gatherer.Close())
 }
+
+func TestICEGatherer_CloseBeforeGather(t *testing.T) {
+	report := test.CheckRoutines(t)
+	defer report()
+
+	gatherer, err := NewAPI().NewICEGatherer(ICEGatherOptions{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, gatherer.Close())
+	assert.Equal(t, ICEGathererStateClosed, gatherer.State())
+
+	// A closed ICEGatherer must not create an agent that would never be closed
+	err = gatherer.Gather()
+	assert.True(t, errors.Is(err, errICEAgentNotExist))
+	assert.Equal(t, ICEGathererStateClosed, gatherer.State())
+}
@@ -1,5 +1,7 @@
 package webrtc
 
+import "encoding/json"
+
 // ICERole describes the role ice.Agent is playing in selecting the
 // preferred the candidate pair.
 type ICERole int
@@ -43,3 +45,19 @@ func (t ICERole) String() string {
 		return ErrUnknownType.Error()
 	}
 }
+
+// MarshalJSON enables JSON marshaling of a ICERole
+func (t ICERole) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON enables JSON unmarshaling of a ICERole
+func (t *ICERole) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*t = newICERole(s)
+	return nil
+}
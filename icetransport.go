@@ -27,7 +27,8 @@ type ICETransport struct {
 	onConnectionStateChangeHandler       atomic.Value // func(ICETransportState)
 	onSelectedCandidatePairChangeHandler atomic.Value // func(*ICECandidatePair)
 
-	state atomic.Value // ICETransportState
+	state                 atomic.Value // ICETransportState
+	selectedCandidatePair atomic.Value // *ICECandidatePair
 
 	gatherer *ICEGatherer
 	conn     *ice.Conn
@@ -101,7 +102,9 @@ func (t *ICETransport) Start(gatherer *ICEGatherer, params ICEParameters, role *
 			t.log.Warnf("%w: %s", errICECandiatesCoversionFailed, err)
 			return
 		}
-		t.onSelectedCandidatePairChange(NewICECandidatePair(&candidates[0], &candidates[1]))
+		pair := NewICECandidatePair(&candidates[0], &candidates[1])
+		t.selectedCandidatePair.Store(pair)
+		t.onSelectedCandidatePairChange(pair)
 	}); err != nil {
 		return err
 	}
@@ -301,17 +304,19 @@ func (t *ICETransport) ensureGatherer() error {
 	return nil
 }
 
-func (t *ICETransport) collectStats(collector *statsReportCollector) {
+// newTransportStats returns the stats of the ICETransport, the DTLSTransport running
+// over it fills in the rest
+func (t *ICETransport) newTransportStats() TransportStats {
 	t.lock.Lock()
 	conn := t.conn
+	role := t.role
 	t.lock.Unlock()
 
-	collector.Collecting()
-
 	stats := TransportStats{
 		Timestamp: statsTimestampFrom(time.Now()),
 		Type:      StatsTypeTransport,
 		ID:        "iceTransport",
+		ICERole:   role,
 	}
 
 	if conn != nil {
@@ -319,7 +324,53 @@ func (t *ICETransport) collectStats(collector *statsReportCollector) {
 		stats.BytesReceived = conn.BytesReceived()
 	}
 
-	collector.Collect(stats.ID, stats)
+	if pair := t.getSelectedCandidatePair(); pair != nil {
+		stats.SelectedCandidatePairID = pair.statsID
+	}
+
+	return stats
+}
+
+func (t *ICETransport) getSelectedCandidatePair() *ICECandidatePair {
+	if pair, ok := t.selectedCandidatePair.Load().(*ICECandidatePair); ok {
+		return pair
+	}
+	return nil
+}
+
+// collectSelectedCandidatePairStats collects the stats of the candidate pair the
+// packets of the ICETransport are sent over
+func (t *ICETransport) collectSelectedCandidatePairStats(collector *statsReportCollector) {
+	pair := t.getSelectedCandidatePair()
+	if pair == nil {
+		return
+	}
+
+	t.lock.RLock()
+	gatherer := t.gatherer
+	t.lock.RUnlock()
+
+	if gatherer == nil {
+		return
+	}
+
+	agent := gatherer.getAgent()
+	if agent == nil {
+		return
+	}
+
+	for _, candidatePairStats := range agent.GetCandidatePairsStats() {
+		stats, err := toICECandidatePairStats(candidatePairStats)
+		if err != nil {
+			t.log.Error(err.Error())
+		}
+		if stats.ID != pair.statsID {
+			continue
+		}
+
+		collector.Collecting()
+		collector.Collect(stats.ID, stats)
+	}
 }
 
 func (t *ICETransport) haveRemoteCredentialsChange(newUfrag, newPwd string) bool {
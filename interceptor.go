@@ -0,0 +1,354 @@
+// +build !js
+
+package webrtc
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/fec"
+	"github.com/pion/webrtc/v3/pkg/interceptor/gcc"
+	"github.com/pion/webrtc/v3/pkg/interceptor/nack"
+	"github.com/pion/webrtc/v3/pkg/interceptor/report"
+	"github.com/pion/webrtc/v3/pkg/interceptor/stats"
+	"github.com/pion/webrtc/v3/pkg/interceptor/twcc"
+)
+
+// RegisterDefaultInterceptors will register some useful interceptors.
+// If you want to customize which interceptors are loaded, you should copy the
+// code from this method and remove unwanted interceptors.
+func RegisterDefaultInterceptors(mediaEngine *MediaEngine, interceptorRegistry *interceptor.Registry) error {
+	if err := ConfigureCongestionControl(interceptorRegistry); err != nil {
+		return err
+	}
+
+	// The header extension is added early, so retransmissions also get their own transport wide sequence number
+	if err := ConfigureTWCCHeaderExtensionSender(mediaEngine, interceptorRegistry); err != nil {
+		return err
+	}
+
+	if err := ConfigureNack(mediaEngine, interceptorRegistry); err != nil {
+		return err
+	}
+
+	if err := ConfigureRTCPReports(interceptorRegistry); err != nil {
+		return err
+	}
+
+	if err := ConfigureTWCCSender(mediaEngine, interceptorRegistry); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConfigureRTCPReports will setup everything necessary for generating Sender and Receiver Reports
+func ConfigureRTCPReports(interceptorRegistry *interceptor.Registry) error {
+	receiver, err := report.NewReceiverInterceptor()
+	if err != nil {
+		return err
+	}
+
+	sender, err := report.NewSenderInterceptor()
+	if err != nil {
+		return err
+	}
+
+	interceptorRegistry.Add(receiver)
+	interceptorRegistry.Add(sender)
+	return nil
+}
+
+// ConfigureNack will setup everything necessary for handling generating/responding to nack messages.
+func ConfigureNack(mediaEngine *MediaEngine, interceptorRegistry *interceptor.Registry) error {
+	generator, err := nack.NewGeneratorInterceptor()
+	if err != nil {
+		return err
+	}
+
+	responder, err := nack.NewResponderInterceptor()
+	if err != nil {
+		return err
+	}
+
+	mediaEngine.RegisterFeedback(RTCPFeedback{Type: "nack"}, RTPCodecTypeVideo)
+	mediaEngine.RegisterFeedback(RTCPFeedback{Type: "nack", Parameter: "pli"}, RTPCodecTypeVideo)
+	interceptorRegistry.Add(responder)
+	interceptorRegistry.Add(generator)
+	return nil
+}
+
+// ConfigureFEC will setup sending forward error correction (FEC) packets for video, with FlexFEC-03
+// when it is negotiated, or with ULPFEC inside RED otherwise. Lost packets can then be recovered by the remote
+// without a retransmission. Receiving FEC packets doesn't need any setup. FEC isn't part of RegisterDefaultInterceptors because it uses more bandwidth.
+// The packets are protected as they are sent, so it must be called before any other Interceptor is added.
+func ConfigureFEC(interceptorRegistry *interceptor.Registry, opts ...fec.EncoderOption) error {
+	encoder, err := fec.NewEncoderInterceptor(opts...)
+	if err != nil {
+		return err
+	}
+
+	interceptorRegistry.Add(encoder)
+	return nil
+}
+
+// ConfigureCongestionControl will setup a send side bandwidth estimator, which is exposed with
+// PeerConnection.OnTargetBitrateChange and PeerConnection.GetTargetBitrate. It relies on the transport wide
+// sequence number, so it must be called before ConfigureTWCCHeaderExtensionSender.
+func ConfigureCongestionControl(interceptorRegistry *interceptor.Registry) error {
+	estimator, err := gcc.NewSendSideBWE()
+	if err != nil {
+		return err
+	}
+
+	interceptorRegistry.Add(estimator)
+	return nil
+}
+
+// ConfigureREMB will setup a receive side bandwidth estimator, which sends Receiver Estimated Maximum Bitrate (REMB)
+// for remote video streams. This is only needed for remote peers that don't support transport wide congestion control.
+func ConfigureREMB(mediaEngine *MediaEngine, interceptorRegistry *interceptor.Registry) error {
+	mediaEngine.RegisterFeedback(RTCPFeedback{Type: TypeRTCPFBGoogREMB}, RTPCodecTypeVideo)
+	if err := mediaEngine.RegisterHeaderExtension(RTPHeaderExtensionCapability{URI: sdp.ABSSendTimeURI}, RTPCodecTypeVideo, RTPTransceiverDirectionRecvonly); err != nil {
+		return err
+	}
+
+	estimator, err := gcc.NewReceiveSideBWE()
+	if err != nil {
+		return err
+	}
+
+	interceptorRegistry.Add(estimator)
+	return nil
+}
+
+// ConfigureTWCCHeaderExtensionSender will setup everything necessary for adding
+// a TWCC header extension to outgoing RTP packets. This will allow the remote peer to generate TWCC reports.
+func ConfigureTWCCHeaderExtensionSender(mediaEngine *MediaEngine, interceptorRegistry *interceptor.Registry) error {
+	for _, typ := range []RTPCodecType{RTPCodecTypeVideo, RTPCodecTypeAudio} {
+		if err := mediaEngine.RegisterHeaderExtension(RTPHeaderExtensionCapability{URI: sdp.TransportCCURI}, typ, RTPTransceiverDirectionSendonly); err != nil {
+			return err
+		}
+	}
+
+	headerExtension, err := twcc.NewHeaderExtensionInterceptor()
+	if err != nil {
+		return err
+	}
+
+	interceptorRegistry.Add(headerExtension)
+	return nil
+}
+
+// ConfigureTWCCSender will setup everything necessary for generating TWCC reports.
+func ConfigureTWCCSender(mediaEngine *MediaEngine, interceptorRegistry *interceptor.Registry) error {
+	for _, typ := range []RTPCodecType{RTPCodecTypeVideo, RTPCodecTypeAudio} {
+		mediaEngine.RegisterFeedback(RTCPFeedback{Type: TypeRTCPFBTransportCC}, typ)
+		if err := mediaEngine.RegisterHeaderExtension(RTPHeaderExtensionCapability{URI: sdp.TransportCCURI}, typ, RTPTransceiverDirectionRecvonly); err != nil {
+			return err
+		}
+	}
+
+	generator, err := twcc.NewSenderInterceptor()
+	if err != nil {
+		return err
+	}
+
+	interceptorRegistry.Add(generator)
+	return nil
+}
+
+// bandwidthEstimator is implemented by Interceptors estimating the bandwidth available for sending
+type bandwidthEstimator interface {
+	GetTargetBitrate() int
+	OnTargetBitrateChange(f func(bitrate int))
+}
+
+// findBandwidthEstimator returns the first bandwidthEstimator in i, or nil if there is none
+func findBandwidthEstimator(i interceptor.Interceptor) bandwidthEstimator {
+	if estimator, ok := i.(bandwidthEstimator); ok {
+		return estimator
+	}
+
+	if chain, ok := i.(*interceptor.Chain); ok {
+		for _, child := range chain.Interceptors() {
+			if estimator := findBandwidthEstimator(child); estimator != nil {
+				return estimator
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasFECEncoder returns true if i, or an Interceptor of its chain, sends FEC packets
+func hasFECEncoder(i interceptor.Interceptor) bool {
+	if _, ok := i.(*fec.EncoderInterceptor); ok {
+		return true
+	}
+
+	if chain, ok := i.(*interceptor.Chain); ok {
+		for _, child := range chain.Interceptors() {
+			if hasFECEncoder(child) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newStatsInterceptor creates the Interceptor recording the statistics of the RTP streams of a PeerConnection
+func newStatsInterceptor() (*stats.Interceptor, error) {
+	f, err := stats.NewInterceptor()
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := f.NewInterceptor("")
+	if err != nil {
+		return nil, err
+	}
+
+	return i.(*stats.Interceptor), nil
+}
+
+// interceptorToTrackLocalWriter is the TrackLocalWriter handed to a TrackLocal on Bind.
+// A TrackLocal is bound before the negotiated codec is known, so the interceptor
+// chain is stored once the StreamInfo can be built. Packets written before that are dropped.
+// Packets written while the encoding is paused are dropped too, before reaching the interceptors.
+type interceptorToTrackLocalWriter struct {
+	interceptor atomic.Value // interceptor.RTPWriter
+
+	// Telephone events are sent in between the packets of the track, on the same stream.
+	// The sequence numbers of the packets of the track are shifted by the number of events sent,
+	// less the number of packets dropped while paused, so the stream has no gap once resumed
+	mu                   sync.Mutex
+	paused               bool
+	sequenceNumberOffset uint16
+	lastSequenceNumber   uint16
+	lastTimestamp        uint32
+	lastWrite            time.Time
+}
+
+func (i *interceptorToTrackLocalWriter) WriteRTP(header *rtp.Header, payload []byte) (int, error) {
+	i.mu.Lock()
+	if i.paused {
+		i.sequenceNumberOffset--
+		i.mu.Unlock()
+		return 0, nil
+	}
+
+	writer := i.writer()
+	if writer == nil {
+		i.mu.Unlock()
+		return 0, nil
+	}
+
+	if i.sequenceNumberOffset != 0 {
+		// The header is owned by the caller
+		shifted := *header
+		shifted.SequenceNumber += i.sequenceNumberOffset
+		header = &shifted
+	}
+	i.lastSequenceNumber, i.lastTimestamp, i.lastWrite = header.SequenceNumber, header.Timestamp, time.Now()
+	i.mu.Unlock()
+
+	return writer.Write(header, payload, interceptor.Attributes{})
+}
+
+// setPaused pauses or resumes the encoding, the packets written while it is paused are dropped
+func (i *interceptorToTrackLocalWriter) setPaused(paused bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.paused = paused
+}
+
+// writer returns the interceptor chain packets are written to, or nil if it isn't stored yet
+func (i *interceptorToTrackLocalWriter) writer() interceptor.RTPWriter {
+	if writer, ok := i.interceptor.Load().(interceptor.RTPWriter); ok && writer != nil {
+		return writer
+	}
+
+	return nil
+}
+
+// writeTelephoneEvent writes a telephone event packet, its sequence number follows the last packet written
+func (i *interceptorToTrackLocalWriter) writeTelephoneEvent(header rtp.Header, payload []byte) (int, error) {
+	i.mu.Lock()
+	writer := i.writer()
+	if i.paused || writer == nil {
+		i.mu.Unlock()
+		return 0, nil
+	}
+
+	i.sequenceNumberOffset++
+	i.lastSequenceNumber++
+	header.SequenceNumber = i.lastSequenceNumber
+	i.mu.Unlock()
+
+	return writer.Write(&header, payload, interceptor.Attributes{})
+}
+
+// telephoneEventTimestamp returns the timestamp of a telephone event starting now, at the given clock rate.
+// It follows the timestamp of the last packet written by the track
+func (i *interceptorToTrackLocalWriter) telephoneEventTimestamp(clockRate uint32) uint32 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.lastWrite.IsZero() {
+		return i.lastTimestamp
+	}
+	return i.lastTimestamp + uint32(telephoneEventUnits(time.Since(i.lastWrite), clockRate))
+}
+
+func (i *interceptorToTrackLocalWriter) Write(b []byte) (int, error) {
+	packet := &rtp.Packet{}
+	if err := packet.Unmarshal(b); err != nil {
+		return 0, err
+	}
+
+	return i.WriteRTP(&packet.Header, packet.Payload)
+}
+
+func createStreamInfo(id string, ssrc SSRC, payloadType PayloadType, codec RTPCodecCapability, webrtcHeaderExtensions []RTPHeaderExtensionParameter) interceptor.StreamInfo {
+	headerExtensions := make([]interceptor.RTPHeaderExtension, 0, len(webrtcHeaderExtensions))
+	for _, h := range webrtcHeaderExtensions {
+		headerExtensions = append(headerExtensions, interceptor.RTPHeaderExtension{ID: h.ID, URI: h.URI})
+	}
+
+	feedbacks := make([]interceptor.RTCPFeedback, 0, len(codec.RTCPFeedback))
+	for _, f := range codec.RTCPFeedback {
+		feedbacks = append(feedbacks, interceptor.RTCPFeedback{Type: f.Type, Parameter: f.Parameter})
+	}
+
+	return interceptor.StreamInfo{
+		ID:                  id,
+		Attributes:          interceptor.Attributes{},
+		SSRC:                uint32(ssrc),
+		PayloadType:         uint8(payloadType),
+		RTPHeaderExtensions: headerExtensions,
+		MimeType:            codec.MimeType,
+		ClockRate:           codec.ClockRate,
+		Channels:            codec.Channels,
+		SDPFmtpLine:         codec.SDPFmtpLine,
+		RTCPFeedback:        feedbacks,
+	}
+}
+
+// headerExtensionParameters flattens the negotiated header extensions into a list ordered by ID
+func headerExtensionParameters(headerExtensions map[int]mediaEngineHeaderExtension) []RTPHeaderExtensionParameter {
+	out := make([]RTPHeaderExtensionParameter, 0, len(headerExtensions))
+	for id, h := range headerExtensions {
+		out = append(out, RTPHeaderExtensionParameter{ID: id, URI: h.uri})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
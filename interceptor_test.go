@@ -0,0 +1,796 @@
+// +build !js
+
+package webrtc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/transport/test"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/fec"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/stretchr/testify/assert"
+)
+
+type testInterceptor struct {
+	interceptor.NoOp
+
+	localStreams, remoteStreams uint32
+	remotePackets, rtcpWrites   uint32
+	lastLocalInfo               atomic.Value // *interceptor.StreamInfo
+}
+
+func (i *testInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	atomic.AddUint32(&i.localStreams, 1)
+	i.lastLocalInfo.Store(info)
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		return writer.Write(header, []byte{0xde, 0xad}, attributes)
+	})
+}
+
+func (i *testInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	atomic.AddUint32(&i.remoteStreams, 1)
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		atomic.AddUint32(&i.remotePackets, 1)
+		return reader.Read(b, a)
+	})
+}
+
+func (i *testInterceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	return interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, attributes interceptor.Attributes) (int, error) {
+		atomic.AddUint32(&i.rtcpWrites, 1)
+		return writer.Write(pkts, attributes)
+	})
+}
+
+type testInterceptorFactory struct {
+	interceptors []*testInterceptor
+}
+
+func (f *testInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &testInterceptor{}
+	f.interceptors = append(f.interceptors, i)
+	return i, nil
+}
+
+// writeSamplesUntil writes sample to track every 20 milliseconds until ctx is done
+func writeSamplesUntil(ctx context.Context, t *testing.T, track *TrackLocalStaticSample, sample media.Sample) {
+	ticker := time.NewTicker(time.Millisecond * 20)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			assert.NoError(t, track.WriteSample(sample))
+		}
+	}
+}
+
+// writeRTPUntil writes a packet to track every 20 milliseconds until ctx is done. The packets
+// are numbered from 0, their payload is their sequence number
+func writeRTPUntil(ctx context.Context, t *testing.T, track *TrackLocalStaticRTP) {
+	ticker := time.NewTicker(time.Millisecond * 20)
+	defer ticker.Stop()
+	for sequenceNumber := uint16(0); ; sequenceNumber++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			assert.NoError(t, track.WriteRTP(&rtp.Packet{
+				Header:  rtp.Header{Version: 2, SequenceNumber: sequenceNumber, PayloadType: 96},
+				Payload: []byte{byte(sequenceNumber >> 8), byte(sequenceNumber)},
+			}))
+		}
+	}
+}
+
+func TestPeerConnection_Interceptor(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+
+	factory := &testInterceptorFactory{}
+	ir := &interceptor.Registry{}
+	ir.Add(factory)
+
+	offerer, answerer, err := NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir)).newPair(Configuration{})
+	assert.NoError(t, err)
+
+	// Every PeerConnection has its own Interceptor
+	assert.Len(t, factory.interceptors, 2)
+	offerInterceptor, answerInterceptor := factory.interceptors[0], factory.interceptors[1]
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+	assert.NoError(t, err)
+
+	_, err = offerer.AddTrack(track)
+	assert.NoError(t, err)
+
+	seenRTP, seenRTPCancel := context.WithCancel(context.Background())
+	answerer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+
+			if bytes.Equal(p.Payload, []byte{0xde, 0xad}) {
+				seenRTPCancel()
+			}
+		}
+	})
+
+	assert.NoError(t, signalPair(offerer, answerer))
+
+	writeSamplesUntil(seenRTP, t, track, media.Sample{Data: []byte{0x00}, Duration: time.Second})
+
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&offerInterceptor.localStreams))
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&answerInterceptor.remoteStreams))
+	assert.NotZero(t, atomic.LoadUint32(&answerInterceptor.remotePackets))
+
+	info, ok := offerInterceptor.lastLocalInfo.Load().(*interceptor.StreamInfo)
+	assert.True(t, ok)
+	assert.True(t, strings.EqualFold(info.MimeType, "video/vp8"))
+	assert.Equal(t, uint32(offerer.GetSenders()[0].trackEncodings[0].parameters.SSRC), info.SSRC)
+	assert.NotEmpty(t, info.RTCPFeedback)
+
+	assert.NoError(t, answerer.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{MediaSSRC: info.SSRC}}))
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&answerInterceptor.rtcpWrites))
+
+	assert.NoError(t, offerer.Close())
+	assert.NoError(t, answerer.Close())
+}
+
+// streamInfoInterceptor records the StreamInfo of the last remote stream bound,
+// the number of remote streams bound and the sequence numbers read since the last one was bound
+type streamInfoInterceptor struct {
+	interceptor.NoOp
+
+	mu              sync.Mutex
+	info            *interceptor.StreamInfo
+	binds           int
+	sequenceNumbers []uint16
+}
+
+func (i *streamInfoInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	i.mu.Lock()
+	i.info, i.sequenceNumbers = info, nil
+	i.binds++
+	i.mu.Unlock()
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err != nil {
+			return n, a, err
+		}
+
+		header := rtp.Header{}
+		if headerErr := header.Unmarshal(b[:n]); headerErr == nil {
+			i.mu.Lock()
+			if i.info == info {
+				i.sequenceNumbers = append(i.sequenceNumbers, header.SequenceNumber)
+			}
+			i.mu.Unlock()
+		}
+		return n, a, err
+	})
+}
+
+type streamInfoInterceptorFactory struct {
+	interceptor *streamInfoInterceptor
+}
+
+func (f *streamInfoInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return f.interceptor, nil
+}
+
+func (i *streamInfoInterceptor) lastStream() (interceptor.StreamInfo, int, []uint16) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return *i.info, i.binds, append([]uint16{}, i.sequenceNumbers...)
+}
+
+func TestPeerConnection_InterceptorStreamInfo(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerer, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+	answerInterceptor := &streamInfoInterceptor{}
+	ir := &interceptor.Registry{}
+	ir.Add(&streamInfoInterceptorFactory{answerInterceptor})
+
+	answerer, err := NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	// PCMA isn't the preferred audio codec, the StreamInfo must come from the PayloadType received
+	track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: mimeTypePCMA}, "audio", "pion")
+	assert.NoError(t, err)
+	_, err = offerer.AddTrack(track)
+	assert.NoError(t, err)
+
+	firstSequenceNumber := make(chan uint16, 1)
+	answerer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+		p, readErr := track.ReadRTP()
+		if readErr != nil {
+			return
+		}
+		firstSequenceNumber <- p.SequenceNumber
+
+		for {
+			if _, readErr = track.ReadRTP(); readErr != nil {
+				return
+			}
+		}
+	})
+
+	assert.NoError(t, signalPair(offerer, answerer))
+
+	var sequenceNumber, sent uint16
+	func() {
+		ticker := time.NewTicker(time.Millisecond * 20)
+		defer ticker.Stop()
+		for {
+			select {
+			case sequenceNumber = <-firstSequenceNumber:
+				return
+			case <-ticker.C:
+				sent++
+				assert.NoError(t, track.WriteRTP(&rtp.Packet{Header: rtp.Header{Version: 2, SequenceNumber: sent, Timestamp: uint32(sent) * 160}, Payload: []byte{0x00}}))
+			}
+		}
+	}()
+
+	// The stream is only bound once its PayloadType is known
+	info, binds, sequenceNumbers := answerInterceptor.lastStream()
+	assert.Equal(t, 1, binds)
+	assert.Equal(t, mimeTypePCMA, info.MimeType)
+	assert.Equal(t, uint32(8000), info.ClockRate)
+	assert.Equal(t, uint8(8), info.PayloadType)
+
+	// The packet read to find the PayloadType is read again through the stream bound with it
+	if assert.NotEmpty(t, sequenceNumbers) {
+		assert.Equal(t, sequenceNumber, sequenceNumbers[0])
+	}
+
+	assert.NoError(t, offerer.Close())
+	assert.NoError(t, answerer.Close())
+}
+
+// dropFirstInterceptor drops the first write of a sequence number, so the
+// packet only arrives if it is retransmitted
+type dropFirstInterceptor struct {
+	interceptor.NoOp
+	sequenceNumber uint16
+	dropped        uint32
+}
+
+func (i *dropFirstInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		if header.SSRC == info.SSRC && header.SequenceNumber == i.sequenceNumber && atomic.CompareAndSwapUint32(&i.dropped, 0, 1) {
+			return len(payload), nil
+		}
+		return writer.Write(header, payload, attributes)
+	})
+}
+
+type dropFirstInterceptorFactory struct {
+	interceptor *dropFirstInterceptor
+}
+
+func (f *dropFirstInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return f.interceptor, nil
+}
+
+func TestPeerConnection_NackRTX(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	dropper := &dropFirstInterceptor{sequenceNumber: 5}
+	newAPI := func() *API {
+		m := &MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+
+		ir := &interceptor.Registry{}
+		// Added before the NACK responder, so the packet is dropped after it was stored for retransmission
+		ir.Add(&dropFirstInterceptorFactory{dropper})
+		assert.NoError(t, ConfigureNack(m, ir))
+
+		return NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir))
+	}
+
+	offerer, err := newAPI().NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	answerer, err := newAPI().NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+	assert.NoError(t, err)
+
+	sender, err := offerer.AddTrack(track)
+	assert.NoError(t, err)
+
+	// Incoming NACKs are handled while RTCP is read
+	go func() {
+		for {
+			if _, readErr := sender.ReadRTCP(); readErr != nil {
+				return
+			}
+		}
+	}()
+
+	repaired, repairedCancel := context.WithCancel(context.Background())
+	answerer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+
+			if p.SequenceNumber == dropper.sequenceNumber {
+				assert.Equal(t, track.SSRC(), SSRC(p.SSRC))
+				assert.Equal(t, track.PayloadType(), PayloadType(p.PayloadType))
+				assert.Equal(t, []byte{0x00, 0x05}, p.Payload)
+				repairedCancel()
+			}
+		}
+	})
+
+	assert.NoError(t, signalPair(offerer, answerer))
+
+	writeRTPUntil(repaired, t, track)
+
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&dropper.dropped))
+
+	// The retransmission was sent on the RTX stream
+	sender.mu.RLock()
+	assert.Equal(t, uint32(sender.trackEncodings[0].parameters.RTX.SSRC), sender.trackEncodings[0].streamInfo.SSRCRetransmission)
+	assert.NotZero(t, sender.trackEncodings[0].streamInfo.PayloadTypeRetransmission)
+	sender.mu.RUnlock()
+
+	assert.NoError(t, offerer.Close())
+	assert.NoError(t, answerer.Close())
+}
+
+func TestPeerConnection_FEC(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	fecCodec := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{mimeTypeFlexFEC03, 90000, 0, "repair-window=10000000", nil}, PayloadType: 117}
+	dropper := &dropFirstInterceptor{sequenceNumber: 5}
+	newAPI := func() *API {
+		m := &MediaEngine{}
+		assert.NoError(t, m.RegisterCodec(RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{mimeTypeVP8, 90000, 0, "", nil}, PayloadType: 96}, RTPCodecTypeVideo))
+		assert.NoError(t, m.RegisterCodec(fecCodec, RTPCodecTypeVideo))
+
+		ir := &interceptor.Registry{}
+		// Added before the FEC encoder, so the packet is dropped after it was protected
+		ir.Add(&dropFirstInterceptorFactory{dropper})
+		assert.NoError(t, ConfigureFEC(ir, fec.EncoderProtectionRate(0.5)))
+
+		return NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir))
+	}
+
+	offerer, err := newAPI().NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	answerer, err := newAPI().NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	sender, err := offerer.AddTrack(track)
+	assert.NoError(t, err)
+
+	recovered, recoveredCancel := context.WithCancel(context.Background())
+	answerer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+
+			if p.SequenceNumber == dropper.sequenceNumber {
+				assert.Equal(t, track.SSRC(), SSRC(p.SSRC))
+				assert.Equal(t, PayloadType(96), PayloadType(p.PayloadType))
+				assert.Equal(t, []byte{0x00, 0x05}, p.Payload)
+				recoveredCancel()
+			}
+		}
+	})
+
+	assert.NoError(t, signalPair(offerer, answerer))
+
+	writeRTPUntil(recovered, t, track)
+
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&dropper.dropped))
+
+	sender.mu.RLock()
+	assert.Equal(t, uint32(sender.trackEncodings[0].parameters.FEC.SSRC), sender.trackEncodings[0].streamInfo.SSRCForwardErrorCorrection)
+	assert.Equal(t, uint8(fecCodec.PayloadType), sender.trackEncodings[0].streamInfo.PayloadTypeForwardErrorCorrection)
+	sender.mu.RUnlock()
+
+	assert.NoError(t, offerer.Close())
+	assert.NoError(t, answerer.Close())
+}
+
+func TestPeerConnection_ULPFEC(t *testing.T) {
+	for _, testCase := range []struct {
+		Name string
+		// A ULPFEC packet is sent after each group, so the media packets are sent with
+		// their sequence number plus the number of groups before them
+		GroupSize int
+		Nack      bool
+		Dropped   uint16
+	}{
+		{"Recovered", 2, false, 4},
+		// The packet is retransmitted before the ULPFEC packet protecting it is sent
+		{"Retransmitted", 48, true, 60},
+	} {
+		testCase := testCase
+		t.Run(testCase.Name, func(t *testing.T) {
+			lim := test.TimeOut(time.Second * 30)
+			defer lim.Stop()
+
+			report := test.CheckRoutines(t)
+			defer report()
+
+			dropper := &dropFirstInterceptor{sequenceNumber: testCase.Dropped}
+			newAPI := func() *API {
+				// FlexFEC would be preferred if it was negotiated
+				m := &MediaEngine{}
+				for _, codec := range []RTPCodecParameters{
+					{RTPCodecCapability: RTPCodecCapability{mimeTypeVP8, 90000, 0, "", nil}, PayloadType: 96},
+					{RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=96", nil}, PayloadType: 97},
+					{RTPCodecCapability: RTPCodecCapability{mimeTypeVideoRED, 90000, 0, "", nil}, PayloadType: 114},
+					{RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=114", nil}, PayloadType: 115},
+					{RTPCodecCapability: RTPCodecCapability{mimeTypeULPFEC, 90000, 0, "", nil}, PayloadType: 116},
+				} {
+					assert.NoError(t, m.RegisterCodec(codec, RTPCodecTypeVideo))
+				}
+
+				ir := &interceptor.Registry{}
+				// Added before the FEC encoder, so the packet is dropped after it was protected and shifted
+				ir.Add(&dropFirstInterceptorFactory{dropper})
+				assert.NoError(t, ConfigureFEC(ir, fec.EncoderProtectionRate(1/float64(testCase.GroupSize))))
+				if testCase.Nack {
+					assert.NoError(t, ConfigureNack(m, ir))
+				}
+
+				return NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir))
+			}
+
+			offerer, err := newAPI().NewPeerConnection(Configuration{})
+			assert.NoError(t, err)
+
+			answerer, err := newAPI().NewPeerConnection(Configuration{})
+			assert.NoError(t, err)
+
+			track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+			assert.NoError(t, err)
+
+			sender, err := offerer.AddTrack(track)
+			assert.NoError(t, err)
+
+			// Incoming NACKs are handled while RTCP is read
+			go func() {
+				for {
+					if _, readErr := sender.ReadRTCP(); readErr != nil {
+						return
+					}
+				}
+			}()
+
+			repaired, repairedCancel := context.WithCancel(context.Background())
+			answerer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+				for {
+					p, readErr := track.ReadRTP()
+					if readErr != nil {
+						return
+					}
+
+					// The packets are read as they were written, without RED
+					written := binary.BigEndian.Uint16(p.Payload)
+					assert.Equal(t, PayloadType(96), PayloadType(p.PayloadType))
+					assert.Equal(t, written+written/uint16(testCase.GroupSize), p.SequenceNumber)
+					if p.SequenceNumber == dropper.sequenceNumber {
+						repairedCancel()
+					}
+				}
+			})
+
+			assert.NoError(t, signalPair(offerer, answerer))
+
+			writeRTPUntil(repaired, t, track)
+
+			assert.Equal(t, uint32(1), atomic.LoadUint32(&dropper.dropped))
+
+			sender.mu.RLock()
+			assert.Zero(t, sender.trackEncodings[0].streamInfo.SSRCForwardErrorCorrection)
+			assert.Equal(t, uint8(116), sender.trackEncodings[0].streamInfo.PayloadTypeForwardErrorCorrection)
+			assert.Equal(t, uint8(114), sender.trackEncodings[0].streamInfo.PayloadTypeRED)
+			sender.mu.RUnlock()
+
+			assert.NoError(t, offerer.Close())
+			assert.NoError(t, answerer.Close())
+		})
+	}
+}
+
+func TestPeerConnection_FECOnlyWhenConfigured(t *testing.T) {
+	for _, configureFEC := range []bool{false, true} {
+		m := &MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+
+		ir := &interceptor.Registry{}
+		if configureFEC {
+			assert.NoError(t, ConfigureFEC(ir))
+		}
+
+		pc, err := NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir)).NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+
+		track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+		assert.NoError(t, err)
+
+		sender, err := pc.AddTrack(track)
+		assert.NoError(t, err)
+
+		offer, err := pc.CreateOffer(nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, configureFEC, sender.GetParameters().Encodings.FEC.SSRC != 0)
+		assert.Equal(t, configureFEC, strings.Contains(offer.SDP, "a=ssrc-group:"+sdpSemanticTokenFECFramework))
+
+		assert.NoError(t, pc.Close())
+	}
+}
+
+// TestPeerConnection_RTCPFeedback checks the RTCP the default Interceptors, or the ones configured,
+// send in response to the media of a track
+func TestPeerConnection_RTCPFeedback(t *testing.T) {
+	for _, testCase := range []struct {
+		Name string
+		// Configure registers the Interceptors, RegisterDefaultInterceptors if nil
+		Configure func(*MediaEngine, *interceptor.Registry) error
+		Sample    media.Sample
+		// SenderRTCP and ReceiverRTCP match the packet waited for, read by the RTPSender or the RTPReceiver
+		SenderRTCP   func(pkt rtcp.Packet, ssrc SSRC) bool
+		ReceiverRTCP func(pkt rtcp.Packet, ssrc SSRC) bool
+		// TargetBitrate waits for the target bitrate of the sending PeerConnection to change instead
+		TargetBitrate bool
+		// HeaderExtension must be negotiated for video
+		HeaderExtension string
+	}{
+		{
+			Name:   "SenderReports",
+			Sample: media.Sample{Data: []byte{0x00}, Duration: time.Second},
+			ReceiverRTCP: func(pkt rtcp.Packet, ssrc SSRC) bool {
+				sr, ok := pkt.(*rtcp.SenderReport)
+				return ok && sr.SSRC == uint32(ssrc) && sr.PacketCount > 0
+			},
+		},
+		{
+			Name:   "ReceiverReports",
+			Sample: media.Sample{Data: []byte{0x00}, Duration: time.Second},
+			SenderRTCP: func(pkt rtcp.Packet, ssrc SSRC) bool {
+				rr, ok := pkt.(*rtcp.ReceiverReport)
+				return ok && len(rr.Reports) == 1 && rr.Reports[0].SSRC == uint32(ssrc)
+			},
+		},
+		{
+			Name:   "TWCC",
+			Sample: media.Sample{Data: []byte{0x00}, Duration: time.Second},
+			SenderRTCP: func(pkt rtcp.Packet, ssrc SSRC) bool {
+				fb, ok := pkt.(*rtcp.TransportLayerCC)
+				return ok && fb.MediaSSRC == uint32(ssrc) && len(fb.RecvDeltas) != 0
+			},
+			HeaderExtension: sdp.TransportCCURI,
+		},
+		{
+			Name:          "TargetBitrate",
+			Sample:        media.Sample{Data: make([]byte, 1000), Duration: time.Second},
+			TargetBitrate: true,
+		},
+		{
+			Name:      "REMB",
+			Configure: ConfigureREMB,
+			Sample:    media.Sample{Data: make([]byte, 1000), Duration: time.Millisecond * 20},
+			SenderRTCP: func(pkt rtcp.Packet, ssrc SSRC) bool {
+				remb, ok := pkt.(*rtcp.ReceiverEstimatedMaximumBitrate)
+				return ok && remb.Bitrate != 0 && remb.SSRCs[0] == uint32(ssrc)
+			},
+		},
+	} {
+		testCase := testCase
+		t.Run(testCase.Name, func(t *testing.T) {
+			lim := test.TimeOut(time.Second * 30)
+			defer lim.Stop()
+
+			report := test.CheckRoutines(t)
+			defer report()
+
+			configure := testCase.Configure
+			if configure == nil {
+				configure = RegisterDefaultInterceptors
+			}
+			newAPI := func() *API {
+				m := &MediaEngine{}
+				assert.NoError(t, m.RegisterDefaultCodecs())
+
+				ir := &interceptor.Registry{}
+				assert.NoError(t, configure(m, ir))
+
+				return NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir))
+			}
+
+			offerer, err := newAPI().NewPeerConnection(Configuration{})
+			assert.NoError(t, err)
+
+			answerer, err := newAPI().NewPeerConnection(Configuration{})
+			assert.NoError(t, err)
+
+			track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+			assert.NoError(t, err)
+
+			sender, err := offerer.AddTrack(track)
+			assert.NoError(t, err)
+			ssrc := sender.GetParameters().Encodings.SSRC
+
+			seen, seenCancel := context.WithCancel(context.Background())
+			go func() {
+				for {
+					pkts, readErr := sender.ReadRTCP()
+					if readErr != nil {
+						return
+					}
+
+					for _, pkt := range pkts {
+						if testCase.SenderRTCP != nil && testCase.SenderRTCP(pkt, ssrc) {
+							seenCancel()
+						}
+					}
+				}
+			}()
+
+			if testCase.TargetBitrate {
+				assert.NotZero(t, offerer.GetTargetBitrate())
+				offerer.OnTargetBitrateChange(func(bps int) {
+					assert.NotZero(t, bps)
+					seenCancel()
+				})
+			}
+
+			answerer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+				go func() {
+					for {
+						pkts, readErr := receiver.ReadRTCP()
+						if readErr != nil {
+							return
+						}
+
+						for _, pkt := range pkts {
+							if testCase.ReceiverRTCP != nil && testCase.ReceiverRTCP(pkt, track.SSRC()) {
+								seenCancel()
+							}
+						}
+					}
+				}()
+
+				for {
+					if _, readErr := track.ReadRTP(); readErr != nil {
+						return
+					}
+				}
+			})
+
+			assert.NoError(t, signalPair(offerer, answerer))
+
+			if testCase.HeaderExtension != "" {
+				id, _, videoNegotiated := answerer.api.mediaEngine.GetHeaderExtensionID(RTPHeaderExtensionCapability{URI: testCase.HeaderExtension})
+				assert.NotZero(t, id)
+				assert.True(t, videoNegotiated)
+			}
+
+			writeSamplesUntil(seen, t, track, testCase.Sample)
+
+			assert.NoError(t, offerer.Close())
+			assert.NoError(t, answerer.Close())
+		})
+	}
+}
+
+func TestPeerConnection_TargetBitrateWithoutEstimator(t *testing.T) {
+	pc, err := NewAPI().NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+	assert.Zero(t, pc.GetTargetBitrate())
+	pc.OnTargetBitrateChange(func(int) {})
+	assert.NoError(t, pc.Close())
+}
+
+func TestInterceptorToTrackLocalWriter_TelephoneEvent(t *testing.T) {
+	sequenceNumbers := []uint16{}
+	writer := &interceptorToTrackLocalWriter{}
+	writer.interceptor.Store(interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		sequenceNumbers = append(sequenceNumbers, header.SequenceNumber)
+		return len(payload), nil
+	}))
+
+	header := &rtp.Header{SequenceNumber: 10, Timestamp: 1000}
+	_, err := writer.WriteRTP(header, []byte{0x00})
+	assert.NoError(t, err)
+
+	_, err = writer.writeTelephoneEvent(rtp.Header{}, []byte{0x00})
+	assert.NoError(t, err)
+	_, err = writer.writeTelephoneEvent(rtp.Header{}, []byte{0x00})
+	assert.NoError(t, err)
+
+	header = &rtp.Header{SequenceNumber: 11, Timestamp: 1960}
+	_, err = writer.WriteRTP(header, []byte{0x00})
+	assert.NoError(t, err)
+
+	// The header written by the track is left untouched
+	assert.Equal(t, uint16(11), header.SequenceNumber)
+	assert.Equal(t, []uint16{10, 11, 12, 13}, sequenceNumbers)
+}
+
+func TestInterceptorToTrackLocalWriter_Paused(t *testing.T) {
+	sequenceNumbers := []uint16{}
+	writer := &interceptorToTrackLocalWriter{}
+	writer.interceptor.Store(interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		sequenceNumbers = append(sequenceNumbers, header.SequenceNumber)
+		return len(payload), nil
+	}))
+
+	write := func(sequenceNumber uint16) {
+		_, err := writer.WriteRTP(&rtp.Header{SequenceNumber: sequenceNumber}, []byte{0x00})
+		assert.NoError(t, err)
+	}
+
+	write(65534)
+	write(65535)
+
+	writer.setPaused(true)
+	write(0)
+	write(1)
+	write(2)
+
+	writer.setPaused(false)
+	write(3)
+	write(4)
+
+	assert.Equal(t, []uint16{65534, 65535, 0, 1}, sequenceNumbers)
+}
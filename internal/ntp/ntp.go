@@ -0,0 +1,23 @@
+// Package ntp converts times to and from the 64 bit NTP timestamp format of RTCP and RTP header extensions
+package ntp
+
+import "time"
+
+// epochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+const epochOffset = 2208988800
+
+// FromTime converts t to the 64 bit NTP timestamp format, https://tools.ietf.org/html/rfc3550#section-4
+func FromTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix()) + epochOffset
+	fraction := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+
+	return seconds<<32 | fraction
+}
+
+// ToTime converts a 64 bit NTP timestamp to a time
+func ToTime(timestamp uint64) time.Time {
+	seconds := timestamp>>32 - epochOffset
+	nanoseconds := (timestamp & 0xffffffff) * uint64(time.Second) >> 32
+
+	return time.Unix(int64(seconds), int64(nanoseconds))
+}
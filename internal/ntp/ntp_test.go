@@ -0,0 +1,21 @@
+package ntp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromTime(t *testing.T) {
+	// 2021-01-01 00:00:00.5 UTC is 3818448000.5 seconds after the NTP epoch
+	assert.Equal(t, uint64(3818448000)<<32|1<<31, FromTime(time.Date(2021, 1, 1, 0, 0, 0, 500000000, time.UTC)))
+
+	// The Unix epoch is the NTP epoch plus the offset
+	assert.Equal(t, uint64(epochOffset)<<32, FromTime(time.Unix(0, 0)))
+}
+
+func TestToTime(t *testing.T) {
+	now := time.Unix(1609459200, 250000000)
+	assert.Equal(t, now, ToTime(FromTime(now)))
+}
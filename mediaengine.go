@@ -11,21 +11,16 @@ import (
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
 	"github.com/pion/sdp/v3"
-)
-
-const (
-	mimeTypeH264 = "video/h264"
-	mimeTypeOpus = "audio/opus"
-	mimeTypeVP8  = "video/vp8"
-	mimeTypeVP9  = "video/vp9"
-	mimeTypeG722 = "audio/G722"
-	mimeTypePCMU = "audio/PCMU"
-	mimeTypePCMA = "audio/PCMA"
+	"github.com/pion/webrtc/v3/pkg/codecs/av1"
+	"github.com/pion/webrtc/v3/pkg/codecs/h265"
 )
 
 type mediaEngineHeaderExtension struct {
 	uri              string
 	isAudio, isVideo bool
+
+	// allowedDirections are the directions the extension is used in, all directions if empty
+	allowedDirections []RTPTransceiverDirection
 }
 
 // A MediaEngine defines the codecs supported by a PeerConnection, and the
@@ -48,9 +43,13 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 	// Default Pion Audio Codecs
 	for _, codec := range []RTPCodecParameters{
 		{
-			RTPCodecCapability: RTPCodecCapability{mimeTypeOpus, 48000, 2, "minptime=10;useinbandfec=1", nil},
+			RTPCodecCapability: RTPCodecCapability{mimeTypeOpus, 48000, 2, OpusParameters{MinPTime: 10, UseInbandFEC: true}.SDPFmtpLine(), nil},
 			PayloadType:        111,
 		},
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeRED, 48000, 2, "111/111", nil},
+			PayloadType:        63,
+		},
 		{
 			RTPCodecCapability: RTPCodecCapability{mimeTypeG722, 8000, 0, "", nil},
 			PayloadType:        9,
@@ -63,6 +62,14 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			RTPCodecCapability: RTPCodecCapability{mimeTypePCMA, 8000, 0, "", nil},
 			PayloadType:        8,
 		},
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeTelephoneEvent, 48000, 0, "0-15", nil},
+			PayloadType:        110,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeTelephoneEvent, 8000, 0, "0-15", nil},
+			PayloadType:        126,
+		},
 	} {
 		if err := m.RegisterCodec(codec, RTPCodecTypeAudio); err != nil {
 			return err
@@ -164,9 +171,40 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 		},
 
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/ulpfec", 90000, 0, "", nil},
+			RTPCodecCapability: RTPCodecCapability{mimeTypeAV1, 90000, 0, "", videoRTCPFeedback},
+			PayloadType:        41,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=41", nil},
+			PayloadType:        42,
+		},
+
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeH265, 90000, 0, "profile-id=1", videoRTCPFeedback},
+			PayloadType:        43,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=43", nil},
+			PayloadType:        44,
+		},
+
+		// ULPFEC is sent inside RED, along with the media
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeVideoRED, 90000, 0, "", nil},
+			PayloadType:        114,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=114", nil},
+			PayloadType:        115,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeULPFEC, 90000, 0, "", nil},
 			PayloadType:        116,
 		},
+		{
+			RTPCodecCapability: RTPCodecCapability{mimeTypeFlexFEC03, 90000, 0, "repair-window=10000000", nil},
+			PayloadType:        117,
+		},
 	} {
 		if err := m.RegisterCodec(codec, RTPCodecTypeVideo); err != nil {
 			return err
@@ -203,9 +241,43 @@ func (m *MediaEngine) RegisterCodec(codec RTPCodecParameters, typ RTPCodecType)
 	return nil
 }
 
+// RegisterFeedback adds feedback mechanism to already registered codecs.
+func (m *MediaEngine) RegisterFeedback(feedback RTCPFeedback, typ RTPCodecType) {
+	switch typ {
+	case RTPCodecTypeVideo:
+		for i, v := range m.videoCodecs {
+			m.videoCodecs[i].RTCPFeedback = appendFeedback(v.RTCPFeedback, feedback)
+		}
+	case RTPCodecTypeAudio:
+		for i, v := range m.audioCodecs {
+			m.audioCodecs[i].RTCPFeedback = appendFeedback(v.RTCPFeedback, feedback)
+		}
+	}
+}
+
+// appendFeedback returns a copy of feedbacks with feedback appended, unless it is already present.
+// A copy is made because the default codecs share a single RTCPFeedback slice.
+func appendFeedback(feedbacks []RTCPFeedback, feedback RTCPFeedback) []RTCPFeedback {
+	for _, f := range feedbacks {
+		if f == feedback {
+			return feedbacks
+		}
+	}
+
+	return append(append([]RTCPFeedback{}, feedbacks...), feedback)
+}
+
 // RegisterHeaderExtension adds a header extension to the MediaEngine
 // To determine the negotiated value use `GetHeaderExtensionID` after signaling is complete
-func (m *MediaEngine) RegisterHeaderExtension(extension RTPHeaderExtensionCapability, typ RTPCodecType) error {
+// allowedDirections limits the offer to transceivers sending (RTPTransceiverDirectionSendonly) or
+// receiving (RTPTransceiverDirectionRecvonly) with it, the extension is offered in every direction if none is given
+func (m *MediaEngine) RegisterHeaderExtension(extension RTPHeaderExtensionCapability, typ RTPCodecType, allowedDirections ...RTPTransceiverDirection) error {
+	for _, direction := range allowedDirections {
+		if direction != RTPTransceiverDirectionSendonly && direction != RTPTransceiverDirectionRecvonly {
+			return ErrRegisterHeaderExtensionInvalidDirection
+		}
+	}
+
 	if m.negotiatedHeaderExtensions == nil {
 		m.negotiatedHeaderExtensions = map[int]mediaEngineHeaderExtension{}
 	}
@@ -218,8 +290,10 @@ func (m *MediaEngine) RegisterHeaderExtension(extension RTPHeaderExtensionCapabi
 	}
 
 	if extensionIndex == -1 {
-		m.headerExtensions = append(m.headerExtensions, mediaEngineHeaderExtension{})
+		m.headerExtensions = append(m.headerExtensions, mediaEngineHeaderExtension{allowedDirections: allowedDirections})
 		extensionIndex = len(m.headerExtensions) - 1
+	} else {
+		m.headerExtensions[extensionIndex].allowDirections(allowedDirections)
 	}
 
 	if typ == RTPCodecTypeAudio {
@@ -233,6 +307,37 @@ func (m *MediaEngine) RegisterHeaderExtension(extension RTPHeaderExtensionCapabi
 	return nil
 }
 
+// allowDirections extends the directions of an extension registered again
+func (e *mediaEngineHeaderExtension) allowDirections(directions []RTPTransceiverDirection) {
+	if len(e.allowedDirections) == 0 {
+		return
+	} else if len(directions) == 0 {
+		e.allowedDirections = nil
+		return
+	}
+
+	for _, direction := range directions {
+		if !e.isUsedIn(direction) {
+			e.allowedDirections = append(e.allowedDirections, direction)
+		}
+	}
+}
+
+// isUsedIn returns if the extension is used by a transceiver with the given direction
+func (e mediaEngineHeaderExtension) isUsedIn(direction RTPTransceiverDirection) bool {
+	if len(e.allowedDirections) == 0 || direction == RTPTransceiverDirectionSendrecv {
+		return true
+	}
+
+	for _, allowed := range e.allowedDirections {
+		if allowed == direction {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetHeaderExtensionID returns the negotiated ID for a header extension.
 // If the Header Extension isn't enabled ok will be false
 func (m *MediaEngine) GetHeaderExtensionID(extension RTPHeaderExtensionCapability) (val int, audioNegotiated, videoNegotiated bool) {
@@ -267,19 +372,7 @@ func (m *MediaEngine) getCodecByPayload(payloadType PayloadType) (RTPCodecParame
 func (m *MediaEngine) collectStats(collector *statsReportCollector) {
 	statsLoop := func(codecs []RTPCodecParameters) {
 		for _, codec := range codecs {
-			collector.Collecting()
-			stats := CodecStats{
-				Timestamp:   statsTimestampFrom(time.Now()),
-				Type:        StatsTypeCodec,
-				ID:          codec.statsID,
-				PayloadType: codec.PayloadType,
-				MimeType:    codec.MimeType,
-				ClockRate:   codec.ClockRate,
-				Channels:    uint8(codec.Channels),
-				SDPFmtpLine: codec.SDPFmtpLine,
-			}
-
-			collector.Collect(stats.ID, stats)
+			collectCodecStats(collector, codec)
 		}
 	}
 
@@ -287,6 +380,22 @@ func (m *MediaEngine) collectStats(collector *statsReportCollector) {
 	statsLoop(m.audioCodecs)
 }
 
+func collectCodecStats(collector *statsReportCollector, codec RTPCodecParameters) {
+	collector.Collecting()
+	stats := CodecStats{
+		Timestamp:   statsTimestampFrom(time.Now()),
+		Type:        StatsTypeCodec,
+		ID:          codec.statsID,
+		PayloadType: codec.PayloadType,
+		MimeType:    codec.MimeType,
+		ClockRate:   codec.ClockRate,
+		Channels:    uint8(codec.Channels),
+		SDPFmtpLine: codec.SDPFmtpLine,
+	}
+
+	collector.Collect(stats.ID, stats)
+}
+
 // Look up a codec and enable if it exists
 func (m *MediaEngine) updateCodecParameters(remoteCodec RTPCodecParameters, typ RTPCodecType) error {
 	codecs := m.videoCodecs
@@ -314,7 +423,15 @@ func (m *MediaEngine) updateCodecParameters(remoteCodec RTPCodecParameters, typ
 		}
 	}
 
-	if _, err := codecParametersFuzzySearch(remoteCodec, codecs); err == nil {
+	// RED is only negotiated along with its primary encoding
+	if primary, ok := redPrimaryPayloadType(remoteCodec.RTPCodecCapability); ok {
+		if _, err := m.getCodecByPayload(primary); err != nil {
+			return nil
+		}
+	}
+
+	if codec, err := codecParametersFuzzySearch(remoteCodec, codecs); err == nil {
+		remoteCodec.SDPFmtpLine = h264AnswerFmtpLine(codec.RTPCodecCapability, remoteCodec.RTPCodecCapability)
 		return pushCodec(remoteCodec)
 	}
 
@@ -405,6 +522,49 @@ func (m *MediaEngine) getCodecsByKind(typ RTPCodecType) []RTPCodecParameters {
 	return nil
 }
 
+// getRegisteredCodecsByKind returns the codecs registered for a kind, regardless of the negotiation
+func (m *MediaEngine) getRegisteredCodecsByKind(typ RTPCodecType) []RTPCodecParameters {
+	switch typ {
+	case RTPCodecTypeVideo:
+		return m.videoCodecs
+	case RTPCodecTypeAudio:
+		return m.audioCodecs
+	default:
+		return nil
+	}
+}
+
+// GetCapabilities returns the codecs and header extensions registered for a kind, the ones
+// a PeerConnection using the MediaEngine can send and receive. RTX is listed once, instead of
+// once per codec it repairs. They can be passed to RTPTransceiver.SetCodecPreferences.
+func (m *MediaEngine) GetCapabilities(typ RTPCodecType) RTCRtpCapabilities {
+	capabilities := RTCRtpCapabilities{
+		HeaderExtensions: []RTPHeaderExtensionCapability{},
+		Codecs:           []RTPCodecCapability{},
+	}
+
+	hasRTX := false
+	for _, codec := range m.getRegisteredCodecsByKind(typ) {
+		if isRTXMimeType(codec.MimeType) {
+			if !hasRTX {
+				hasRTX = true
+				capabilities.Codecs = append(capabilities.Codecs, RTPCodecCapability{MimeType: codec.MimeType, ClockRate: codec.ClockRate})
+			}
+			continue
+		}
+
+		capabilities.Codecs = append(capabilities.Codecs, codec.RTPCodecCapability)
+	}
+
+	for _, e := range m.headerExtensions {
+		if e.isAudio && typ == RTPCodecTypeAudio || e.isVideo && typ == RTPCodecTypeVideo {
+			capabilities.HeaderExtensions = append(capabilities.HeaderExtensions, RTPHeaderExtensionCapability{e.uri})
+		}
+	}
+
+	return capabilities
+}
+
 func (m *MediaEngine) negotiatedHeaderExtensionsForType(typ RTPCodecType) map[int]mediaEngineHeaderExtension {
 	headerExtensions := map[int]mediaEngineHeaderExtension{}
 	for id, e := range m.negotiatedHeaderExtensions {
@@ -416,16 +576,40 @@ func (m *MediaEngine) negotiatedHeaderExtensionsForType(typ RTPCodecType) map[in
 	return headerExtensions
 }
 
+// getHeaderExtensionsByKind returns the header extensions to signal for a transceiver of a kind and direction.
+// Once the kind is negotiated the IDs chosen in the offer are used. Before that only the registered extensions used in
+// direction are offered. Their ID is their position in the registration order starting from 1, so an extension keeps
+// the same ID in every media section of the bundle.
+func (m *MediaEngine) getHeaderExtensionsByKind(typ RTPCodecType, direction RTPTransceiverDirection) map[int]mediaEngineHeaderExtension {
+	if typ == RTPCodecTypeVideo && m.negotiatedVideo || typ == RTPCodecTypeAudio && m.negotiatedAudio {
+		return m.negotiatedHeaderExtensionsForType(typ)
+	}
+
+	headerExtensions := map[int]mediaEngineHeaderExtension{}
+	for i, e := range m.headerExtensions {
+		if (e.isAudio && typ == RTPCodecTypeAudio || e.isVideo && typ == RTPCodecTypeVideo) && e.isUsedIn(direction) {
+			headerExtensions[i+1] = e
+		}
+	}
+
+	return headerExtensions
+}
+
 func payloaderForCodec(codec RTPCodecCapability) (rtp.Payloader, error) {
 	switch strings.ToLower(codec.MimeType) {
 	case mimeTypeH264:
 		return &codecs.H264Payloader{}, nil
-	case mimeTypeOpus:
+	case mimeTypeOpus, mimeTypeRED:
+		// RED is only sent with Opus as the primary encoding
 		return &codecs.OpusPayloader{}, nil
 	case mimeTypeVP8:
 		return &codecs.VP8Payloader{}, nil
 	case mimeTypeVP9:
 		return &codecs.VP9Payloader{}, nil
+	case mimeTypeAV1:
+		return &av1.Payloader{}, nil
+	case mimeTypeH265:
+		return &h265.Payloader{}, nil
 	case mimeTypeG722:
 		return &codecs.G722Payloader{}, nil
 	case mimeTypePCMU, mimeTypePCMA:
@@ -136,6 +136,113 @@ a=rtpmap:111 opus/48000/2
 		assert.Equal(t, opusCodec.MimeType, mimeTypeOpus)
 	})
 
+	t.Run("Matches H264 profile and packetization mode", func(t *testing.T) {
+		const h264Profiles = `v=0
+o=- 4596489990601351948 2 IN IP4 127.0.0.1
+s=-
+t=0 0
+m=video 60323 UDP/TLS/RTP/SAVPF 96 97 98
+a=rtpmap:96 H264/90000
+a=fmtp:96 level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=4d001f
+a=rtpmap:97 H264/90000
+a=fmtp:97 packetization-mode=1;profile-level-id=42e034
+a=rtpmap:98 H264/90000
+a=fmtp:98 level-asymmetry-allowed=1;profile-level-id=42e01f
+`
+
+		m := MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+		assert.NoError(t, m.updateFromRemoteDescription(mustParse(h264Profiles)))
+
+		assert.True(t, m.negotiatedVideo)
+
+		// Main profile isn't supported
+		_, err := m.getCodecByPayload(96)
+		assert.Error(t, err)
+
+		// Level asymmetry isn't allowed by the remote, the level is lowered to the local one
+		h264Codec, err := m.getCodecByPayload(97)
+		assert.NoError(t, err)
+		assert.Equal(t, "packetization-mode=1;profile-level-id=42e01f", h264Codec.SDPFmtpLine)
+
+		h264Codec, err = m.getCodecByPayload(98)
+		assert.NoError(t, err)
+		assert.Equal(t, "level-asymmetry-allowed=1;profile-level-id=42e01f", h264Codec.SDPFmtpLine)
+	})
+
+	t.Run("Matches VP9 profile", func(t *testing.T) {
+		const vp9Profiles = `v=0
+o=- 4596489990601351948 2 IN IP4 127.0.0.1
+s=-
+t=0 0
+m=video 60323 UDP/TLS/RTP/SAVPF 96 97
+a=rtpmap:96 VP9/90000
+a=fmtp:96 profile-id=2
+a=rtpmap:97 VP9/90000
+`
+
+		m := MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+		assert.NoError(t, m.updateFromRemoteDescription(mustParse(vp9Profiles)))
+
+		_, err := m.getCodecByPayload(96)
+		assert.Error(t, err)
+
+		_, err = m.getCodecByPayload(97)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Matches H265 profile", func(t *testing.T) {
+		const h265Profiles = `v=0
+o=- 4596489990601351948 2 IN IP4 127.0.0.1
+s=-
+t=0 0
+m=video 60323 UDP/TLS/RTP/SAVPF 96 98
+a=rtpmap:96 H265/90000
+a=fmtp:96 profile-id=2
+a=rtpmap:98 H265/90000
+a=fmtp:98 profile-id=1;level-id=93
+`
+
+		m := MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+		assert.NoError(t, m.updateFromRemoteDescription(mustParse(h265Profiles)))
+
+		assert.True(t, m.negotiatedVideo)
+
+		_, err := m.getCodecByPayload(96)
+		assert.Error(t, err)
+
+		h265Codec, err := m.getCodecByPayload(98)
+		assert.NoError(t, err)
+		assert.Equal(t, h265Codec.MimeType, "video/H265")
+	})
+
+	t.Run("RED requires its primary encoding", func(t *testing.T) {
+		const redPrimary = `v=0
+o=- 4596489990601351948 2 IN IP4 127.0.0.1
+s=-
+t=0 0
+m=audio 9 UDP/TLS/RTP/SAVPF 111 63 62
+a=rtpmap:111 opus/48000/2
+a=rtpmap:63 red/48000/2
+a=fmtp:63 111/111
+a=rtpmap:62 red/48000/2
+a=fmtp:62 109/109
+`
+
+		m := MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+		assert.NoError(t, m.updateFromRemoteDescription(mustParse(redPrimary)))
+
+		redCodec, err := m.getCodecByPayload(63)
+		assert.NoError(t, err)
+		assert.Equal(t, "audio/red", redCodec.MimeType)
+
+		_, err = m.getCodecByPayload(62)
+		assert.Error(t, err)
+	})
+
 	t.Run("Header Extensions", func(t *testing.T) {
 		const headerExtensions = `v=0
 o=- 4596489990601351948 2 IN IP4 127.0.0.1
@@ -163,5 +270,85 @@ a=rtpmap:111 opus/48000/2
 		assert.Equal(t, midID, 7)
 		assert.True(t, midAudioEnabled)
 		assert.False(t, midVideoEnabled)
+
+		// Audio uses the negotiated IDs, video still offers the registered extensions
+		assert.Equal(t, map[int]mediaEngineHeaderExtension{
+			7: {uri: sdp.SDESMidURI, isAudio: true},
+			5: {uri: sdp.SDESRTPStreamIDURI, isAudio: true},
+		}, m.getHeaderExtensionsByKind(RTPCodecTypeAudio, RTPTransceiverDirectionSendrecv))
+		assert.Len(t, m.getHeaderExtensionsByKind(RTPCodecTypeVideo, RTPTransceiverDirectionSendrecv), 3)
 	})
 }
+
+func TestMediaEngineRegisterFeedback(t *testing.T) {
+	m := MediaEngine{}
+	assert.NoError(t, m.RegisterCodec(RTPCodecParameters{
+		RTPCodecCapability: RTPCodecCapability{mimeTypeVP8, 90000, 0, "", nil},
+		PayloadType:        96,
+	}, RTPCodecTypeVideo))
+	assert.NoError(t, m.RegisterCodec(RTPCodecParameters{
+		RTPCodecCapability: RTPCodecCapability{mimeTypeOpus, 48000, 2, "", nil},
+		PayloadType:        111,
+	}, RTPCodecTypeAudio))
+
+	m.RegisterFeedback(RTCPFeedback{Type: "nack"}, RTPCodecTypeVideo)
+	m.RegisterFeedback(RTCPFeedback{Type: "nack"}, RTPCodecTypeVideo)
+	m.RegisterFeedback(RTCPFeedback{Type: "nack", Parameter: "pli"}, RTPCodecTypeVideo)
+
+	assert.Equal(t, []RTCPFeedback{{"nack", ""}, {"nack", "pli"}}, m.videoCodecs[0].RTCPFeedback)
+	assert.Empty(t, m.audioCodecs[0].RTCPFeedback)
+}
+
+func TestMediaEngineHeaderExtensionDirection(t *testing.T) {
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{sdp.SDESMidURI}, RTPCodecTypeVideo))
+	assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{sdp.TransportCCURI}, RTPCodecTypeVideo, RTPTransceiverDirectionSendonly))
+	assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{sdp.ABSSendTimeURI}, RTPCodecTypeVideo, RTPTransceiverDirectionRecvonly))
+
+	uris := func(direction RTPTransceiverDirection) map[int]string {
+		out := map[int]string{}
+		for id, e := range m.getHeaderExtensionsByKind(RTPCodecTypeVideo, direction) {
+			out[id] = e.uri
+		}
+		return out
+	}
+
+	// IDs follow the registration order, and are kept when an extension isn't offered
+	assert.Equal(t, map[int]string{1: sdp.SDESMidURI, 2: sdp.TransportCCURI}, uris(RTPTransceiverDirectionSendonly))
+	assert.Equal(t, map[int]string{1: sdp.SDESMidURI, 3: sdp.ABSSendTimeURI}, uris(RTPTransceiverDirectionRecvonly))
+	assert.Equal(t, map[int]string{1: sdp.SDESMidURI, 2: sdp.TransportCCURI, 3: sdp.ABSSendTimeURI}, uris(RTPTransceiverDirectionSendrecv))
+	assert.Equal(t, map[int]string{1: sdp.SDESMidURI}, uris(RTPTransceiverDirectionInactive))
+	assert.Empty(t, m.getHeaderExtensionsByKind(RTPCodecTypeAudio, RTPTransceiverDirectionSendrecv))
+
+	// Registering again for the other direction offers the extension in both
+	assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{sdp.TransportCCURI}, RTPCodecTypeVideo, RTPTransceiverDirectionRecvonly))
+	assert.Equal(t, map[int]string{1: sdp.SDESMidURI, 2: sdp.TransportCCURI, 3: sdp.ABSSendTimeURI}, uris(RTPTransceiverDirectionRecvonly))
+
+	// Registering without directions offers the extension in every direction
+	assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{sdp.ABSSendTimeURI}, RTPCodecTypeVideo))
+	assert.Equal(t, map[int]string{1: sdp.SDESMidURI, 2: sdp.TransportCCURI, 3: sdp.ABSSendTimeURI}, uris(RTPTransceiverDirectionSendonly))
+
+	assert.Equal(t, ErrRegisterHeaderExtensionInvalidDirection, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{sdp.SDESMidURI}, RTPCodecTypeVideo, RTPTransceiverDirectionSendrecv))
+}
+
+func TestMediaEngine_GetCapabilities(t *testing.T) {
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+
+	video := m.GetCapabilities(RTPCodecTypeVideo)
+	rtxCount := 0
+	for _, codec := range video.Codecs {
+		assert.NotEqual(t, mimeTypeOpus, codec.MimeType)
+		if isRTXMimeType(codec.MimeType) {
+			rtxCount++
+			assert.Equal(t, "", codec.SDPFmtpLine)
+		}
+	}
+	assert.Equal(t, 1, rtxCount)
+	assert.Equal(t, mimeTypeVP8, video.Codecs[0].MimeType)
+	assert.Contains(t, video.HeaderExtensions, RTPHeaderExtensionCapability{sdp.SDESMidURI})
+
+	audio := m.GetCapabilities(RTPCodecTypeAudio)
+	assert.Equal(t, mimeTypeOpus, audio.Codecs[0].MimeType)
+	assert.Equal(t, "minptime=10;useinbandfec=1", audio.Codecs[0].SDPFmtpLine)
+}
@@ -1,6 +1,7 @@
 package webrtc
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/pion/ice/v2"
@@ -102,3 +103,20 @@ func getNetworkType(iceNetworkType ice.NetworkType) (NetworkType, error) {
 		return NetworkType(Unknown), fmt.Errorf("%w: %s", errNetworkTypeUnknown, iceNetworkType.String())
 	}
 }
+
+// MarshalJSON enables JSON marshaling of a NetworkType
+func (t NetworkType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON enables JSON unmarshaling of a NetworkType
+func (t *NetworkType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	var err error
+	*t, err = NewNetworkType(s)
+	return err
+}
@@ -5,6 +5,8 @@ package webrtc
 type OfferAnswerOptions struct {
 	// VoiceActivityDetection allows the application to provide information
 	// about whether it wishes voice detection feature to be enabled or disabled.
+	// When enabled, Opus is negotiated with discontinuous transmission (usedtx=1), no packets
+	// are sent during silence.
 	VoiceActivityDetection bool
 }
 
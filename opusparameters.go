@@ -0,0 +1,147 @@
+package webrtc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	opusParameterMinPTime          = "minptime"
+	opusParameterPTime             = "ptime"
+	opusParameterMaxPTime          = "maxptime"
+	opusParameterUseInbandFEC      = "useinbandfec"
+	opusParameterUseDTX            = "usedtx"
+	opusParameterStereo            = "stereo"
+	opusParameterSpropStereo       = "sprop-stereo"
+	opusParameterCBR               = "cbr"
+	opusParameterMaxAverageBitrate = "maxaveragebitrate"
+	opusParameterMaxPlaybackRate   = "maxplaybackrate"
+)
+
+// OpusParameters are the format specific parameters of the Opus codec. A zero value means
+// the parameter isn't signaled, and its default applies. All of them describe how the side
+// signaling them prefers to receive, except SpropStereo which describes how it sends.
+//
+// PTime and MaxPTime are signaled with the ptime and maxptime attributes of the media section.
+// They are carried by the SDPFmtpLine of the codec, so the negotiated codec has all the parameters.
+//
+// https://tools.ietf.org/html/rfc7587#section-6.1
+type OpusParameters struct {
+	// MinPTime, PTime and MaxPTime are the minimum, preferred and maximum
+	// duration of the media in a packet, in milliseconds
+	MinPTime uint32
+	PTime    uint32
+	MaxPTime uint32
+
+	// UseInbandFEC signals the in-band forward error correction of Opus can be decoded
+	UseInbandFEC bool
+
+	// UseDTX signals discontinuous transmission is preferred, packets aren't sent during silence
+	UseDTX bool
+
+	// Stereo signals stereo is preferred, SpropStereo signals stereo is likely to be sent
+	Stereo      bool
+	SpropStereo bool
+
+	// CBR signals a constant bitrate is preferred
+	CBR bool
+
+	// MaxAverageBitrate is the maximum average bitrate to be received, in bits per second
+	MaxAverageBitrate uint32
+
+	// MaxPlaybackRate is the maximum sampling rate that is rendered, in Hz
+	MaxPlaybackRate uint32
+}
+
+// OpusParameters returns the Opus parameters of the SDPFmtpLine.
+// The second return value is false if the codec isn't Opus
+func (c RTPCodecCapability) OpusParameters() (OpusParameters, bool) {
+	if !strings.EqualFold(c.MimeType, mimeTypeOpus) {
+		return OpusParameters{}, false
+	}
+
+	f := parseFmtp(c.MimeType, c.SDPFmtpLine)
+	flag := func(key string) bool {
+		value, _ := f.parameter(key)
+		return value == "1"
+	}
+	number := func(key string) uint32 {
+		value, _ := f.parameter(key)
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0
+		}
+		return uint32(n)
+	}
+
+	return OpusParameters{
+		MinPTime:          number(opusParameterMinPTime),
+		PTime:             number(opusParameterPTime),
+		MaxPTime:          number(opusParameterMaxPTime),
+		UseInbandFEC:      flag(opusParameterUseInbandFEC),
+		UseDTX:            flag(opusParameterUseDTX),
+		Stereo:            flag(opusParameterStereo),
+		SpropStereo:       flag(opusParameterSpropStereo),
+		CBR:               flag(opusParameterCBR),
+		MaxAverageBitrate: number(opusParameterMaxAverageBitrate),
+		MaxPlaybackRate:   number(opusParameterMaxPlaybackRate),
+	}, true
+}
+
+// SDPFmtpLine returns the parameters as the SDPFmtpLine of an Opus RTPCodecCapability
+func (o OpusParameters) SDPFmtpLine() string {
+	parameters := []string{}
+	number := func(key string, value uint32) {
+		if value != 0 {
+			parameters = append(parameters, fmt.Sprintf("%s=%d", key, value))
+		}
+	}
+	flag := func(key string, value bool) {
+		if value {
+			parameters = append(parameters, key+"=1")
+		}
+	}
+
+	number(opusParameterMinPTime, o.MinPTime)
+	number(opusParameterPTime, o.PTime)
+	number(opusParameterMaxPTime, o.MaxPTime)
+	flag(opusParameterUseInbandFEC, o.UseInbandFEC)
+	flag(opusParameterUseDTX, o.UseDTX)
+	flag(opusParameterStereo, o.Stereo)
+	flag(opusParameterSpropStereo, o.SpropStereo)
+	flag(opusParameterCBR, o.CBR)
+	number(opusParameterMaxAverageBitrate, o.MaxAverageBitrate)
+	number(opusParameterMaxPlaybackRate, o.MaxPlaybackRate)
+
+	return strings.Join(parameters, ";")
+}
+
+// opusMediaAttributes are the Opus parameters sent as attributes of the media section, instead of in the a=fmtp line
+var opusMediaAttributes = []string{opusParameterPTime, opusParameterMaxPTime}
+
+// opusFmtpLineToSDP splits the SDPFmtpLine of an Opus codec into the a=fmtp line and the attributes of the media section
+func opusFmtpLineToSDP(fmtpLine string) (string, map[string]string) {
+	attributes := map[string]string{}
+	for _, key := range opusMediaAttributes {
+		var value string
+		if fmtpLine, value = removeFmtpParameter(fmtpLine, key); value != "" {
+			attributes[key] = value
+		}
+	}
+
+	return fmtpLine, attributes
+}
+
+// opusFmtpLineFromSDP adds the attributes of a media section to the a=fmtp line of an Opus codec
+func opusFmtpLineFromSDP(fmtpLine string, attributes map[string]string) string {
+	for _, key := range opusMediaAttributes {
+		if value, ok := attributes[key]; ok && value != "" {
+			if _, ok := parseFmtp(mimeTypeOpus, fmtpLine).parameter(key); !ok {
+				fmtpLine = setFmtpParameter(fmtpLine, key, value)
+			}
+		}
+	}
+
+	return fmtpLine
+}
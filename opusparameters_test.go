@@ -0,0 +1,30 @@
+package webrtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpusParameters(t *testing.T) {
+	parameters, ok := RTPCodecCapability{MimeType: "audio/OPUS", SDPFmtpLine: "minptime=10; useinbandfec=1;stereo=1;sprop-stereo=0;maxaveragebitrate=64000;ptime=20"}.OpusParameters()
+	assert.True(t, ok)
+	assert.Equal(t, OpusParameters{MinPTime: 10, PTime: 20, UseInbandFEC: true, Stereo: true, MaxAverageBitrate: 64000}, parameters)
+	assert.Equal(t, "minptime=10;ptime=20;useinbandfec=1;stereo=1;maxaveragebitrate=64000", parameters.SDPFmtpLine())
+
+	_, ok = RTPCodecCapability{MimeType: mimeTypeVP8}.OpusParameters()
+	assert.False(t, ok)
+
+	assert.Equal(t, "", OpusParameters{}.SDPFmtpLine())
+	assert.Equal(t, "usedtx=1;sprop-stereo=1;cbr=1;maxplaybackrate=16000", OpusParameters{UseDTX: true, SpropStereo: true, CBR: true, MaxPlaybackRate: 16000}.SDPFmtpLine())
+}
+
+func TestOpusFmtpLineSDP(t *testing.T) {
+	fmtpLine, attributes := opusFmtpLineToSDP("minptime=10;ptime=20;maxptime=60;useinbandfec=1")
+	assert.Equal(t, "minptime=10;useinbandfec=1", fmtpLine)
+	assert.Equal(t, map[string]string{"ptime": "20", "maxptime": "60"}, attributes)
+
+	assert.Equal(t, "minptime=10;useinbandfec=1;ptime=20;maxptime=60", opusFmtpLineFromSDP(fmtpLine, attributes))
+	assert.Equal(t, "ptime=40;maxptime=60", opusFmtpLineFromSDP("ptime=40", attributes))
+	assert.Equal(t, "useinbandfec=1", opusFmtpLineFromSDP("useinbandfec=1", map[string]string{}))
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/pion/rtcp"
 	"github.com/pion/sdp/v3"
 	"github.com/pion/webrtc/v3/internal/util"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
 	"github.com/pion/webrtc/v3/pkg/rtcerr"
 )
 
@@ -76,16 +77,25 @@ type PeerConnection struct {
 	// A reference to the associated API state used by this connection
 	api *API
 	log logging.LeveledLogger
+
+	interceptorRTCPWriter interceptor.RTCPWriter
+	bandwidthEstimator    bandwidthEstimator
 }
 
 // NewPeerConnection creates a peerconnection with the default
-// codecs. See API.NewPeerConnection for details.
+// codecs and interceptors. See API.NewPeerConnection for details.
 func NewPeerConnection(configuration Configuration) (*PeerConnection, error) {
-	m := MediaEngine{}
+	m := &MediaEngine{}
 	if err := m.RegisterDefaultCodecs(); err != nil {
 		return nil, err
 	}
-	api := NewAPI(WithMediaEngine(&m))
+
+	i := &interceptor.Registry{}
+	if err := RegisterDefaultInterceptors(m, i); err != nil {
+		return nil, err
+	}
+
+	api := NewAPI(WithMediaEngine(m), WithInterceptorRegistry(i))
 	return api.NewPeerConnection(configuration)
 }
 
@@ -124,6 +134,27 @@ func (api *API) NewPeerConnection(configuration Configuration) (*PeerConnection,
 		return nil, err
 	}
 
+	// Every PeerConnection gets its own Interceptor so state isn't shared between them
+	i, err := api.interceptorRegistry.Build("")
+	if err != nil {
+		return nil, err
+	}
+
+	// The statistics are recorded closest to the network, after the registered Interceptors
+	statsInterceptor, err := newStatsInterceptor()
+	if err != nil {
+		return nil, err
+	}
+
+	pc.api = &API{
+		settingEngine:    api.settingEngine,
+		mediaEngine:      api.mediaEngine,
+		interceptor:      interceptor.NewChain([]interceptor.Interceptor{statsInterceptor, i}),
+		statsInterceptor: statsInterceptor,
+		sendsFEC:         hasFECEncoder(i),
+	}
+	pc.bandwidthEstimator = findBandwidthEstimator(i)
+
 	pc.iceGatherer, err = pc.createICEGatherer()
 	if err != nil {
 		return nil, err
@@ -153,6 +184,8 @@ func (api *API) NewPeerConnection(configuration Configuration) (*PeerConnection,
 		}
 	})
 
+	pc.interceptorRTCPWriter = pc.api.interceptor.BindRTCPWriter(interceptor.RTCPWriterFunc(pc.writeRTCP))
+
 	return pc, nil
 }
 
@@ -454,6 +487,26 @@ func (pc *PeerConnection) OnConnectionStateChange(f func(PeerConnectionState)) {
 	pc.onConnectionStateChangeHandler = f
 }
 
+// OnTargetBitrateChange sets an event handler which is called with the estimated bandwidth
+// available for sending, in bits per second, each time it changes. The estimate needs a
+// bandwidth estimator, see ConfigureCongestionControl, and is only updated while the RTCP
+// of the RTPSenders is read. The handler is called from the goroutine reading RTCP.
+func (pc *PeerConnection) OnTargetBitrateChange(f func(bps int)) {
+	if pc.bandwidthEstimator != nil {
+		pc.bandwidthEstimator.OnTargetBitrateChange(f)
+	}
+}
+
+// GetTargetBitrate returns the estimated bandwidth available for sending in bits per second,
+// or 0 if no bandwidth estimator is configured.
+func (pc *PeerConnection) GetTargetBitrate() int {
+	if pc.bandwidthEstimator == nil {
+		return 0
+	}
+
+	return pc.bandwidthEstimator.GetTargetBitrate()
+}
+
 // SetConfiguration updates the configuration of this PeerConnection object.
 func (pc *PeerConnection) SetConfiguration(configuration Configuration) error { //nolint:gocognit
 	// https://www.w3.org/TR/webrtc/#dom-rtcpeerconnection-setconfiguration (step #2)
@@ -637,6 +690,10 @@ func (pc *PeerConnection) CreateOffer(options *OfferOptions) (SessionDescription
 			return SessionDescription{}, err
 		}
 
+		if options != nil && options.VoiceActivityDetection {
+			enableOpusDTX(d)
+		}
+
 		sdpBytes, err := d.Marshal()
 		if err != nil {
 			return SessionDescription{}, err
@@ -770,6 +827,10 @@ func (pc *PeerConnection) CreateAnswer(options *AnswerOptions) (SessionDescripti
 		return SessionDescription{}, err
 	}
 
+	if options != nil && options.VoiceActivityDetection {
+		enableOpusDTX(d)
+	}
+
 	sdpBytes, err := d.Marshal()
 	if err != nil {
 		return SessionDescription{}, err
@@ -1089,7 +1150,11 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 func (pc *PeerConnection) startReceiver(incoming trackDetails, receiver *RTPReceiver) {
 	encodings := []RTPDecodingParameters{}
 	if incoming.ssrc != 0 {
-		encodings = append(encodings, RTPDecodingParameters{RTPCodingParameters{SSRC: incoming.ssrc}})
+		encodings = append(encodings, RTPDecodingParameters{RTPCodingParameters{
+			SSRC: incoming.ssrc,
+			RTX:  RTPRtxParameters{SSRC: incoming.repairSsrc},
+			FEC:  RTPFecParameters{SSRC: incoming.fecSsrc},
+		}})
 	}
 	for _, rid := range incoming.rids {
 		encodings = append(encodings, RTPDecodingParameters{RTPCodingParameters{RID: rid}})
@@ -1126,10 +1191,12 @@ func (pc *PeerConnection) startReceiver(incoming trackDetails, receiver *RTPRece
 			return
 		}
 
+		receiver.setStreamCodec(receiver.Track(), codec)
+
 		receiver.Track().mu.Lock()
 		receiver.Track().kind = receiver.kind
-		receiver.Track().codec = codec
 		receiver.Track().mu.Unlock()
+		receiver.Track().setCodec(codec, pc.api.mediaEngine)
 
 		pc.onTrack(receiver.Track(), receiver)
 	}()
@@ -1210,16 +1277,9 @@ func (pc *PeerConnection) startRTPReceivers(incomingTracks []trackDetails, curre
 // startRTPSenders starts all outbound RTP streams
 func (pc *PeerConnection) startRTPSenders(currentTransceivers []*RTPTransceiver) {
 	for _, transceiver := range currentTransceivers {
-		if transceiver.Sender() != nil && transceiver.Sender().isNegotiated() && !transceiver.Sender().hasSent() {
-			err := transceiver.Sender().Send(RTPSendParameters{
-				Encodings: RTPEncodingParameters{
-					RTPCodingParameters{
-						SSRC:        transceiver.Sender().ssrc,
-						PayloadType: transceiver.Sender().payloadType,
-					},
-				},
-			})
-			if err != nil {
+		if sender := transceiver.Sender(); sender != nil && sender.isNegotiated() && !sender.hasSent() {
+			sender.setMid(transceiver.Mid())
+			if err := sender.Send(newRTPSendParameters(sender.getEncodingParameters())); err != nil {
 				pc.log.Warnf("Failed to start Sender: %s", err)
 			}
 		}
@@ -1306,15 +1366,18 @@ func (pc *PeerConnection) handleUndeclaredSSRC(rtpStream io.Reader, ssrc SSRC) e
 		return errPeerConnSimulcastStreamIDRTPExtensionRequired
 	}
 
+	// The repaired-rtp-stream-id is optional, without it RTX streams for simulcast can't be matched to a track
+	repairStreamIDExtensionID, _, _ := pc.api.mediaEngine.GetHeaderExtensionID(RTPHeaderExtensionCapability{sdesRepairRTPStreamIDURI})
+
 	b := make([]byte, receiveMTU)
-	var mid, rid string
+	var mid, rid, rsid string
 	for readCount := 0; readCount <= simulcastProbeCount; readCount++ {
 		i, err := rtpStream.Read(b)
 		if err != nil {
 			return err
 		}
 
-		maybeMid, maybeRid, payloadType, err := handleUnknownRTPPacket(b[:i], uint8(midExtensionID), uint8(streamIDExtensionID))
+		maybeMid, maybeRid, maybeRsid, payloadType, err := handleUnknownRTPPacket(b[:i], uint8(midExtensionID), uint8(streamIDExtensionID), uint8(repairStreamIDExtensionID))
 		if err != nil {
 			return err
 		}
@@ -1325,6 +1388,19 @@ func (pc *PeerConnection) handleUndeclaredSSRC(rtpStream io.Reader, ssrc SSRC) e
 		if maybeRid != "" {
 			rid = maybeRid
 		}
+		if maybeRsid != "" {
+			rsid = maybeRsid
+		}
+
+		if mid != "" && rsid != "" {
+			for _, t := range pc.GetTransceivers() {
+				if t.Mid() != mid || t.Receiver() == nil {
+					continue
+				}
+
+				return t.Receiver().receiveForRtx(rsid, ssrc)
+			}
+		}
 
 		if mid == "" || rid == "" {
 			continue
@@ -1590,10 +1666,19 @@ func (pc *PeerConnection) AddTransceiverFromTrack(track TrackLocal, init ...RtpT
 	}
 
 	direction := RTPTransceiverDirectionSendrecv
+	var sendEncodings []RTPEncodingParameters
 	if len(init) > 1 {
 		return nil, errPeerConnAddTransceiverFromTrackOnlyAcceptsOne
 	} else if len(init) == 1 {
 		direction = init[0].Direction
+		sendEncodings = init[0].SendEncodings
+	}
+
+	newSender := func() (*RTPSender, error) {
+		if len(sendEncodings) == 0 {
+			return pc.api.NewRTPSender(track, pc.dtlsTransport)
+		}
+		return pc.api.newSimulcastRTPSender(track, pc.dtlsTransport, sendEncodings)
 	}
 
 	switch direction {
@@ -1603,7 +1688,7 @@ func (pc *PeerConnection) AddTransceiverFromTrack(track TrackLocal, init ...RtpT
 			return nil, err
 		}
 
-		sender, err := pc.api.NewRTPSender(track, pc.dtlsTransport)
+		sender, err := newSender()
 		if err != nil {
 			return nil, err
 		}
@@ -1620,7 +1705,7 @@ func (pc *PeerConnection) AddTransceiverFromTrack(track TrackLocal, init ...RtpT
 		return t, nil
 
 	case RTPTransceiverDirectionSendonly:
-		sender, err := pc.api.NewRTPSender(track, pc.dtlsTransport)
+		sender, err := newSender()
 		if err != nil {
 			return nil, err
 		}
@@ -1728,25 +1813,28 @@ func (pc *PeerConnection) SetIdentityProvider(provider string) error {
 // WriteRTCP sends a user provided RTCP packet to the connected peer
 // If no peer is connected the packet is discarded
 func (pc *PeerConnection) WriteRTCP(pkts []rtcp.Packet) error {
+	_, err := pc.interceptorRTCPWriter.Write(pkts, interceptor.Attributes{})
+	return err
+}
+
+// writeRTCP is the bottom of the Interceptor RTCPWriter chain
+func (pc *PeerConnection) writeRTCP(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
 	raw, err := rtcp.Marshal(pkts)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	srtcpSession, err := pc.dtlsTransport.getSRTCPSession()
 	if err != nil {
-		return nil
+		return 0, nil
 	}
 
 	writeStream, err := srtcpSession.OpenWriteStream()
 	if err != nil {
-		return fmt.Errorf("%w: %v", errPeerConnWriteRTCPOpenWriteStream, err)
+		return 0, fmt.Errorf("%w: %v", errPeerConnWriteRTCPOpenWriteStream, err)
 	}
 
-	if _, err := writeStream.Write(raw); err != nil {
-		return err
-	}
-	return nil
+	return writeStream.Write(raw)
 }
 
 // Close ends the PeerConnection
@@ -1770,6 +1858,8 @@ func (pc *PeerConnection) Close() error {
 	//    continue the chain the Mux has to be closed.
 	closeErrs := make([]error, 4)
 
+	closeErrs = append(closeErrs, pc.api.interceptor.Close())
+
 	// https://www.w3.org/TR/webrtc/#dom-rtcpeerconnection-close (step #4)
 	for _, t := range pc.GetTransceivers() {
 		if !t.stopped {
@@ -1809,7 +1899,7 @@ func (pc *PeerConnection) newRTPTransceiver(
 	direction RTPTransceiverDirection,
 	kind RTPCodecType,
 ) *RTPTransceiver {
-	t := &RTPTransceiver{kind: kind}
+	t := &RTPTransceiver{kind: kind, api: pc.api}
 	t.setReceiver(receiver)
 	t.setSender(sender)
 	t.setDirection(direction)
@@ -1905,9 +1995,7 @@ func (pc *PeerConnection) GetStats() StatsReport {
 	if pc.iceGatherer != nil {
 		pc.iceGatherer.collectStats(statsCollector)
 	}
-	if pc.iceTransport != nil {
-		pc.iceTransport.collectStats(statsCollector)
-	}
+	pc.dtlsTransport.collectStats(statsCollector)
 
 	pc.sctpTransport.lock.Lock()
 	dataChannels := append([]*DataChannel{}, pc.sctpTransport.dataChannels...)
@@ -1926,6 +2014,15 @@ func (pc *PeerConnection) GetStats() StatsReport {
 	}
 	pc.sctpTransport.collectStats(statsCollector)
 
+	for _, t := range pc.rtpTransceivers {
+		if sender := t.Sender(); sender != nil {
+			sender.collectStats(statsCollector)
+		}
+		if receiver := t.Receiver(); receiver != nil {
+			receiver.collectStats(statsCollector)
+		}
+	}
+
 	stats := PeerConnectionStats{
 		Timestamp:             statsTimestampNow(),
 		Type:                  StatsTypePeerConnection,
@@ -1944,6 +2041,7 @@ func (pc *PeerConnection) GetStats() StatsReport {
 			continue
 		}
 	}
+	pc.dtlsTransport.collectRemoteCertificateStats(statsCollector)
 	pc.mu.Unlock()
 
 	pc.api.mediaEngine.collectStats(statsCollector)
@@ -18,6 +18,8 @@ import (
 	"github.com/pion/rtcp"
 	"github.com/pion/sdp/v3"
 	"github.com/pion/transport/test"
+	"github.com/pion/webrtc/v3/pkg/codecs/av1"
+	"github.com/pion/webrtc/v3/pkg/headerextension"
 	"github.com/pion/webrtc/v3/pkg/media"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -154,7 +156,7 @@ func TestPeerConnection_Media_Sample(t *testing.T) {
 	go func() {
 		for {
 			time.Sleep(time.Millisecond * 100)
-			if routineErr := pcOffer.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{SenderSSRC: uint32(sender.ssrc), MediaSSRC: uint32(sender.ssrc)}}); routineErr != nil {
+			if routineErr := pcOffer.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{SenderSSRC: uint32(sender.GetParameters().Encodings.SSRC), MediaSSRC: uint32(sender.GetParameters().Encodings.SSRC)}}); routineErr != nil {
 				awaitRTCPSenderSend <- routineErr
 			}
 
@@ -768,12 +770,12 @@ func TestAddTransceiverAddTrack_Reuse(t *testing.T) {
 	track1, sender1 := addTrack()
 	assert.Equal(t, 1, len(pc.GetTransceivers()))
 	assert.Equal(t, sender1, tr.Sender())
-	assert.Equal(t, track1, tr.Sender().track)
+	assert.Equal(t, track1, tr.Sender().trackEncodings[0].track)
 	require.NoError(t, pc.RemoveTrack(sender1))
 
 	track2, _ := addTrack()
 	assert.Equal(t, 1, len(pc.GetTransceivers()))
-	assert.Equal(t, track2, tr.Sender().track)
+	assert.Equal(t, track2, tr.Sender().trackEncodings[0].track)
 
 	addTrack()
 	assert.Equal(t, 2, len(pc.GetTransceivers()))
@@ -1130,3 +1132,196 @@ func TestPeerConnection_Start_Right_Receiver(t *testing.T) {
 	assert.NoError(t, pcOffer.Close())
 	assert.NoError(t, pcAnswer.Close())
 }
+
+func TestPeerConnection_Media_AV1(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	pcOffer, pcAnswer, err := newPair()
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/AV1"}, "video", "pion")
+	assert.NoError(t, err)
+
+	_, err = pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	// A sequence header and a frame larger than the MTU, with their size fields
+	temporalUnit := append([]byte{0x0A, 0x01, 0x00, 0x32, 0x80, 0x10}, bytes.Repeat([]byte{0xAB}, 2048)...)
+
+	temporalUnitReceived := make(chan struct{})
+	pcAnswer.OnTrack(func(track *TrackRemote, _ *RTPReceiver) {
+		assert.True(t, strings.EqualFold(track.Codec().MimeType, "video/AV1"))
+
+		depacketizer := &av1.Packet{}
+		received := []byte{}
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+
+			obus, readErr := depacketizer.UnmarshalRTP(p)
+			assert.NoError(t, readErr)
+			received = append(received, obus...)
+
+			if !p.Marker {
+				continue
+			} else if bytes.Equal(received, temporalUnit) {
+				close(temporalUnitReceived)
+				return
+			}
+			received = []byte{}
+		}
+	})
+
+	go func() {
+		for {
+			select {
+			case <-temporalUnitReceived:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			if routineErr := track.WriteSample(media.Sample{Data: temporalUnit, Duration: time.Second}); routineErr != nil {
+				return
+			}
+		}
+	}()
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	<-temporalUnitReceived
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+func TestPeerConnection_Media_RED(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	pcOffer, pcAnswer, err := newPair()
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSampleWithCodecs([]RTPCodecCapability{
+		{MimeType: "audio/red", ClockRate: 48000, Channels: 2},
+		{MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}, "audio", "pion")
+	assert.NoError(t, err)
+
+	_, err = pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	frame := []byte{0xAB, 0xCD}
+	frameReceived := make(chan struct{})
+	pcAnswer.OnTrack(func(track *TrackRemote, _ *RTPReceiver) {
+		// RED is unwrapped, the track is read as Opus
+		assert.Equal(t, mimeTypeOpus, track.Codec().MimeType)
+		assert.Equal(t, PayloadType(111), track.PayloadType())
+
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+
+			assert.Equal(t, uint8(111), p.PayloadType)
+			if bytes.Equal(p.Payload, frame) {
+				close(frameReceived)
+				return
+			}
+		}
+	})
+
+	go func() {
+		for {
+			select {
+			case <-frameReceived:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			if routineErr := track.WriteSample(media.Sample{Data: frame, Duration: 20 * time.Millisecond}); routineErr != nil {
+				return
+			}
+		}
+	}()
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	<-frameReceived
+	assert.Equal(t, mimeTypeRED, track.Codec().MimeType)
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+func TestPeerConnection_Media_HeaderExtensions(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	newPeerConnection := func() *PeerConnection {
+		m := &MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+		assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{URI: headerextension.AudioLevelURI}, RTPCodecTypeAudio))
+
+		pc, err := NewAPI(WithMediaEngine(m)).NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+		return pc
+	}
+	pcOffer, pcAnswer := newPeerConnection(), newPeerConnection()
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: mimeTypeOpus}, "audio", "pion")
+	assert.NoError(t, err)
+
+	_, err = pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	audioLevelReceived := make(chan struct{})
+	pcAnswer.OnTrack(func(track *TrackRemote, _ *RTPReceiver) {
+		for {
+			p, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+
+			audioLevel := &headerextension.AudioLevel{}
+			ok, extensionErr := track.GetHeaderExtension(&p.Header, audioLevel)
+			assert.NoError(t, extensionErr)
+			if ok {
+				assert.Equal(t, &headerextension.AudioLevel{Level: 30, Voice: true}, audioLevel)
+
+				_, extensionErr = track.GetHeaderExtension(&p.Header, &headerextension.VideoOrientation{})
+				assert.Equal(t, ErrHeaderExtensionNotNegotiated, extensionErr)
+				close(audioLevelReceived)
+				return
+			}
+		}
+	})
+
+	go func() {
+		for {
+			select {
+			case <-audioLevelReceived:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			sample := media.Sample{Data: []byte{0xAB}, Duration: 20 * time.Millisecond}
+			if routineErr := track.WriteSampleWithHeaderExtensions(sample, &headerextension.AudioLevel{Level: 30, Voice: true}); routineErr != nil {
+				return
+			}
+		}
+	}()
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	<-audioLevelReceived
+	closePairNow(t, pcOffer, pcAnswer)
+}
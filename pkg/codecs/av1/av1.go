@@ -0,0 +1,150 @@
+// Package av1 implements the RTP payload format for AV1
+// https://aomediacodec.github.io/av1-rtp-spec/
+package av1
+
+import (
+	"errors"
+)
+
+const (
+	zMask = 0x80
+	yMask = 0x40
+	wMask = 0x30
+	nMask = 0x08
+
+	wShift = 4
+
+	// maxElementsWithoutLength is the largest number of OBU elements that can be signaled in W,
+	// letting the last element omit its length
+	maxElementsWithoutLength = 3
+
+	obuTypeMask       = 0x78
+	obuTypeShift      = 3
+	obuExtensionFlag  = 0x04
+	obuHasSizeField   = 0x02
+	leb128ValueMask   = 0x7f
+	leb128ContinueBit = 0x80
+	leb128MaxSize     = 8
+)
+
+// OBUType is the type of an Open Bitstream Unit
+type OBUType uint8
+
+// Types of OBUs
+const (
+	OBUTypeSequenceHeader       OBUType = 1
+	OBUTypeTemporalDelimiter    OBUType = 2
+	OBUTypeFrameHeader          OBUType = 3
+	OBUTypeTileGroup            OBUType = 4
+	OBUTypeMetadata             OBUType = 5
+	OBUTypeFrame                OBUType = 6
+	OBUTypeRedundantFrameHeader OBUType = 7
+	OBUTypeTileList             OBUType = 8
+	OBUTypePadding              OBUType = 15
+)
+
+var (
+	errNilPacket     = errors.New("invalid nil packet")
+	errShortPacket   = errors.New("packet is not large enough")
+	errInvalidLEB128 = errors.New("invalid leb128 value")
+	errShortOBU      = errors.New("OBU is not large enough")
+)
+
+func obuType(header byte) OBUType {
+	return OBUType((header & obuTypeMask) >> obuTypeShift)
+}
+
+func obuHeaderSize(header byte) int {
+	if header&obuExtensionFlag != 0 {
+		return 2
+	}
+	return 1
+}
+
+// encodeLEB128 appends value to b, encoded as unsigned Little Endian Base 128
+func encodeLEB128(b []byte, value uint) []byte {
+	for {
+		if value <= leb128ValueMask {
+			return append(b, byte(value))
+		}
+		b = append(b, byte(value&leb128ValueMask)|leb128ContinueBit)
+		value >>= 7
+	}
+}
+
+func leb128Size(value uint) int {
+	size := 1
+	for ; value > leb128ValueMask; value >>= 7 {
+		size++
+	}
+	return size
+}
+
+// decodeLEB128 returns the value at the start of b and the number of bytes it was encoded with
+func decodeLEB128(b []byte) (uint, int, error) {
+	var value uint
+	for i := 0; i < len(b) && i < leb128MaxSize; i++ {
+		value |= uint(b[i]&leb128ValueMask) << (7 * uint(i))
+		if b[i]&leb128ContinueBit == 0 {
+			return value, i + 1, nil
+		}
+	}
+
+	return 0, 0, errInvalidLEB128
+}
+
+// splitOBUs splits a temporal unit in the low overhead bitstream format into its OBUs.
+// The returned OBUs don't have a size field, as OBUs carried in RTP don't have one.
+func splitOBUs(temporalUnit []byte) ([][]byte, error) {
+	obus := [][]byte{}
+	for len(temporalUnit) > 0 {
+		header := temporalUnit[0]
+		headerSize := obuHeaderSize(header)
+		if len(temporalUnit) < headerSize {
+			return nil, errShortOBU
+		}
+
+		size := len(temporalUnit) - headerSize
+		offset := headerSize
+		if header&obuHasSizeField != 0 {
+			value, n, err := decodeLEB128(temporalUnit[headerSize:])
+			if err != nil {
+				return nil, err
+			}
+			size = int(value)
+			offset += n
+		}
+		if len(temporalUnit) < offset+size {
+			return nil, errShortOBU
+		}
+
+		obu := make([]byte, 0, headerSize+size)
+		obu = append(obu, header&^obuHasSizeField)
+		obu = append(obu, temporalUnit[1:headerSize]...)
+		obu = append(obu, temporalUnit[offset:offset+size]...)
+		obus = append(obus, obu)
+
+		temporalUnit = temporalUnit[offset+size:]
+	}
+
+	return obus, nil
+}
+
+// appendOBU appends obu to b in the low overhead bitstream format, adding a size field if it has none
+func appendOBU(b []byte, obu []byte) []byte {
+	if len(obu) == 0 {
+		return b
+	} else if obu[0]&obuHasSizeField != 0 {
+		return append(b, obu...)
+	}
+
+	headerSize := obuHeaderSize(obu[0])
+	if len(obu) < headerSize {
+		return b
+	}
+
+	b = append(b, obu[0]|obuHasSizeField)
+	b = append(b, obu[1:headerSize]...)
+	b = encodeLEB128(b, uint(len(obu)-headerSize))
+	return append(b, obu[headerSize:]...)
+}
@@ -0,0 +1,148 @@
+package av1
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLEB128(t *testing.T) {
+	for _, value := range []uint{0, 1, 127, 128, 300, 16383, 16384, 1 << 28} {
+		encoded := encodeLEB128(nil, value)
+		assert.Equal(t, leb128Size(value), len(encoded))
+
+		decoded, n, err := decodeLEB128(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, len(encoded), n)
+		assert.Equal(t, value, decoded)
+	}
+
+	_, _, err := decodeLEB128([]byte{0x80, 0x80})
+	assert.Equal(t, errInvalidLEB128, err)
+}
+
+// temporalUnit returns a temporal unit in the low overhead bitstream format with a
+// temporal delimiter, a sequence header and a frame with frameSize bytes of payload
+func temporalUnit(frameSize int) (tu []byte, withoutTemporalDelimiter []byte) {
+	frame := bytes.Repeat([]byte{0xAB}, frameSize)
+
+	tu = []byte{0x12, 0x00}
+	tu = append(tu, 0x0A, 0x03, 0x01, 0x02, 0x03)
+	tu = append(tu, 0x32)
+	tu = encodeLEB128(tu, uint(len(frame)))
+	tu = append(tu, frame...)
+
+	return tu, tu[2:]
+}
+
+func TestPayloader(t *testing.T) {
+	p := &Payloader{}
+
+	assert.Nil(t, p.Payload(2, []byte{0x0A, 0x01, 0x00}), "MTU too small")
+	assert.Nil(t, p.Payload(100, []byte{0x0A, 0x05, 0x00}), "OBU larger than the temporal unit")
+
+	tu, _ := temporalUnit(4)
+	assert.Equal(t, [][]byte{{
+		0x28,                         // W=2, N
+		0x04, 0x08, 0x01, 0x02, 0x03, // Sequence header without size field
+		0x30, 0xAB, 0xAB, 0xAB, 0xAB, // Frame without size field
+	}}, p.Payload(100, tu))
+
+	payloads := p.Payload(10, tu)
+	assert.Equal(t, [][]byte{
+		{0x68, 0x04, 0x08, 0x01, 0x02, 0x03, 0x30, 0xAB, 0xAB},
+		{0x90, 0xAB, 0xAB},
+	}, payloads)
+
+	// More than three elements are all preceded by their length
+	tu = []byte{}
+	for i := 0; i < 4; i++ {
+		tu = append(tu, 0x2A, 0x01, byte(i))
+	}
+	assert.Equal(t, [][]byte{{
+		0x00,
+		0x02, 0x28, 0x00,
+		0x02, 0x28, 0x01,
+		0x02, 0x28, 0x02,
+		0x02, 0x28, 0x03,
+	}}, p.Payload(100, tu))
+}
+
+func TestPacket(t *testing.T) {
+	p := &Packet{}
+
+	_, err := p.Unmarshal(nil)
+	assert.Equal(t, errNilPacket, err)
+
+	_, err = p.Unmarshal([]byte{0x00})
+	assert.Equal(t, errShortPacket, err)
+
+	_, err = p.Unmarshal([]byte{0x00, 0x05, 0x30})
+	assert.Equal(t, errShortPacket, err)
+
+	assert.True(t, p.IsPartitionHead([]byte{0x48}))
+	assert.False(t, p.IsPartitionHead([]byte{0x90}))
+	assert.False(t, p.IsPartitionHead([]byte{}))
+
+	// Continuation of an OBU which start was lost is dropped
+	out, err := p.Unmarshal([]byte{0x90, 0xAB, 0xAB})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, out)
+	assert.True(t, p.Z)
+	assert.Equal(t, byte(1), p.W)
+	assert.Equal(t, [][]byte{{0xAB, 0xAB}}, p.OBUElements)
+}
+
+func TestPacket_UnmarshalRTP(t *testing.T) {
+	p := &Packet{}
+
+	_, err := p.UnmarshalRTP(nil)
+	assert.Equal(t, errNilPacket, err)
+
+	// An OBU fragmented over three packets
+	fragments := [][]byte{{0x50, 0x30, 0x01}, {0xD0, 0x02}, {0x90, 0x03}}
+
+	out, err := p.UnmarshalRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 65535}, Payload: fragments[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, out)
+
+	out, err = p.UnmarshalRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 0}, Payload: fragments[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, out)
+
+	out, err = p.UnmarshalRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 1}, Payload: fragments[2]})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x32, 0x03, 0x01, 0x02, 0x03}, out)
+
+	// The packet in the middle is lost, the fragments around it must not be joined
+	_, err = p.UnmarshalRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 2}, Payload: fragments[0]})
+	assert.NoError(t, err)
+
+	out, err = p.UnmarshalRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 4}, Payload: fragments[2]})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, out)
+}
+
+func TestPayloaderPacketRoundTrip(t *testing.T) {
+	for _, mtu := range []int{3, 10, 100, 1200} {
+		tu, expected := temporalUnit(3000)
+		payloads := (&Payloader{}).Payload(mtu, tu)
+
+		depacketizer := &Packet{}
+		out := []byte{}
+		assert.True(t, depacketizer.IsPartitionHead(payloads[0]))
+		for i, payload := range payloads {
+			assert.LessOrEqual(t, len(payload), mtu)
+			assert.Equal(t, payload[0]&zMask == 0, depacketizer.IsPartitionHead(payload))
+			assert.Equal(t, i == 0, payload[0]&nMask != 0)
+
+			b, err := depacketizer.Unmarshal(payload)
+			assert.NoError(t, err)
+			out = append(out, b...)
+		}
+
+		assert.Equal(t, expected, out, "MTU %d", mtu)
+	}
+}
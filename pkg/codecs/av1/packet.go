@@ -0,0 +1,117 @@
+package av1
+
+import (
+	"github.com/pion/rtp"
+)
+
+// Packet depacketizes the AV1 payload of RTP packets. Unmarshal returns the OBUs in the low overhead
+// bitstream format, so the payloads of a temporal unit can be concatenated.
+// An OBU fragmented over multiple packets is returned with the packet it ends in, so the same Packet
+// must be used for the consecutive packets of a stream. Unmarshal can't tell if a packet in between
+// was lost, UnmarshalRTP should be used when the RTP packets are available.
+type Packet struct {
+	// Z is set if the first OBU element continues an OBU fragment of the previous packet
+	Z bool
+	// Y is set if the last OBU element continues in the next packet
+	Y bool
+	// W is the number of OBU elements, or 0 if each of them is preceded by its length
+	W byte
+	// N is set for the first packet of a coded video sequence
+	N bool
+
+	// OBUElements are the OBU elements of the last unmarshaled packet
+	OBUElements [][]byte
+
+	// fragment is the start of an OBU continued in the next packet
+	fragment []byte
+
+	// sequenceNumber is the sequence number of the last packet given to UnmarshalRTP
+	sequenceNumber    uint16
+	hasSequenceNumber bool
+}
+
+// UnmarshalRTP is Unmarshal for the payload of packet. An OBU fragment isn't continued
+// if a packet was lost since the packet it started in, as the OBU can't be completed.
+func (p *Packet) UnmarshalRTP(packet *rtp.Packet) ([]byte, error) {
+	if packet == nil {
+		return nil, errNilPacket
+	}
+
+	if p.hasSequenceNumber && packet.SequenceNumber != p.sequenceNumber+1 {
+		p.fragment = nil
+	}
+	p.sequenceNumber, p.hasSequenceNumber = packet.SequenceNumber, true
+
+	return p.Unmarshal(packet.Payload)
+}
+
+// Unmarshal parses the passed byte slice and stores the result in the Packet.
+// It returns the complete OBUs of the packet with a size field.
+func (p *Packet) Unmarshal(payload []byte) ([]byte, error) {
+	if payload == nil {
+		return nil, errNilPacket
+	} else if len(payload) < 2 {
+		return nil, errShortPacket
+	}
+
+	p.Z = payload[0]&zMask != 0
+	p.Y = payload[0]&yMask != 0
+	p.W = (payload[0] & wMask) >> wShift
+	p.N = payload[0]&nMask != 0
+
+	p.OBUElements = [][]byte{}
+	for offset := 1; offset < len(payload); {
+		length := len(payload) - offset
+		if p.W == 0 || len(p.OBUElements) < int(p.W)-1 {
+			value, n, err := decodeLEB128(payload[offset:])
+			if err != nil {
+				return nil, err
+			}
+			offset += n
+			length = int(value)
+		}
+		if offset+length > len(payload) {
+			return nil, errShortPacket
+		}
+
+		p.OBUElements = append(p.OBUElements, payload[offset:offset+length])
+		offset += length
+
+		if p.W != 0 && len(p.OBUElements) == int(p.W) {
+			break
+		}
+	}
+
+	elements := p.OBUElements
+	if p.Z && len(elements) > 0 {
+		if len(p.fragment) == 0 {
+			// The start of the OBU was lost
+			elements = elements[1:]
+		} else {
+			elements = append([][]byte{append(p.fragment, elements[0]...)}, elements[1:]...)
+		}
+	}
+
+	p.fragment = nil
+	if p.Y && len(elements) > 0 {
+		p.fragment = append([]byte{}, elements[len(elements)-1]...)
+		elements = elements[:len(elements)-1]
+	}
+
+	out := []byte{}
+	for _, obu := range elements {
+		out = appendOBU(out, obu)
+	}
+
+	return out, nil
+}
+
+// IsPartitionHead checks whether if this is a head of the AV1 partition,
+// which is the case if it doesn't start with the continuation of an OBU
+func (*Packet) IsPartitionHead(payload []byte) bool {
+	if len(payload) < 1 {
+		return false
+	}
+
+	return payload[0]&zMask == 0
+}
@@ -0,0 +1,109 @@
+package av1
+
+// Payloader payloads AV1 temporal units. The payload must be a temporal unit in the
+// low overhead bitstream format, as stored in IVF files.
+// Temporal delimiter and tile list OBUs are not sent, as the RTP payload format requires.
+type Payloader struct{}
+
+// aggregation is a RTP payload being built
+type aggregation struct {
+	z, y     bool
+	elements [][]byte
+	// size is the size of the elements, assuming all of them are preceded by their length
+	size int
+}
+
+func (a *aggregation) add(element []byte) {
+	a.elements = append(a.elements, element)
+	a.size += leb128Size(uint(len(element))) + len(element)
+}
+
+func (a *aggregation) marshal() []byte {
+	w := 0
+	if len(a.elements) <= maxElementsWithoutLength {
+		w = len(a.elements)
+	}
+
+	out := make([]byte, 1, 1+a.size)
+	out[0] = byte(w << wShift)
+	if a.z {
+		out[0] |= zMask
+	}
+	if a.y {
+		out[0] |= yMask
+	}
+
+	for i, element := range a.elements {
+		if w == 0 || i < w-1 {
+			out = encodeLEB128(out, uint(len(element)))
+		}
+		out = append(out, element...)
+	}
+
+	return out
+}
+
+// maxElementSize returns the size of the largest element that fits into available bytes, including its length
+func maxElementSize(available int) int {
+	size := available - 1
+	for size > 0 && size+leb128Size(uint(size)) > available {
+		size--
+	}
+	return size
+}
+
+// Payload fragments an AV1 temporal unit across one or more byte arrays
+func (p *Payloader) Payload(mtu int, payload []byte) [][]byte {
+	// The aggregation header and an element of a single byte with its length
+	if mtu < 3 {
+		return nil
+	}
+
+	obus, err := splitOBUs(payload)
+	if err != nil {
+		return nil
+	}
+
+	payloads := [][]byte{}
+	newCodedVideoSequence := false
+	current := &aggregation{}
+	for _, obu := range obus {
+		switch obuType(obu[0]) {
+		case OBUTypeTemporalDelimiter, OBUTypeTileList:
+			continue
+		case OBUTypeSequenceHeader:
+			newCodedVideoSequence = true
+		}
+
+		for len(obu) > 0 {
+			size := maxElementSize(mtu - 1 - current.size)
+			if size <= 0 {
+				payloads = append(payloads, current.marshal())
+				current = &aggregation{}
+				continue
+			}
+			if size > len(obu) {
+				size = len(obu)
+			}
+
+			current.add(obu[:size])
+			obu = obu[size:]
+
+			// The OBU continues in the next packet
+			if len(obu) > 0 {
+				current.y = true
+				payloads = append(payloads, current.marshal())
+				current = &aggregation{z: true}
+			}
+		}
+	}
+	if len(current.elements) > 0 {
+		payloads = append(payloads, current.marshal())
+	}
+
+	if newCodedVideoSequence && len(payloads) > 0 {
+		payloads[0][0] |= nMask
+	}
+
+	return payloads
+}
@@ -0,0 +1,90 @@
+// Package h265 implements the RTP payload format for H.265/HEVC
+// https://tools.ietf.org/html/rfc7798
+// Only streams without DONL fields (sprop-max-don-diff=0) are supported.
+package h265
+
+import (
+	"errors"
+)
+
+const (
+	naluHeaderSize   = 2
+	fuHeaderSize     = 3
+	apNALULengthSize = 2
+
+	forbiddenBitMask = 0x80
+	naluTypeMask     = 0x7E
+	naluTypeShift    = 1
+	layerIDMask      = 0x01F8
+	layerIDShift     = 3
+	tidMask          = 0x07
+	fuStartBitmask   = 0x80
+	fuEndBitmask     = 0x40
+	fuTypeMask       = 0x3F
+	maxLayerID       = 63
+	maxTID           = 7
+)
+
+// NALUType is the type of a H265 NAL unit
+type NALUType uint8
+
+// Types of NAL units
+// https://tools.ietf.org/html/rfc7798#section-1.1.4
+const (
+	NALUTypeIDRWRADL   NALUType = 19
+	NALUTypeIDRNLP     NALUType = 20
+	NALUTypeCRA        NALUType = 21
+	NALUTypeVPS        NALUType = 32
+	NALUTypeSPS        NALUType = 33
+	NALUTypePPS        NALUType = 34
+	NALUTypeAUD        NALUType = 35
+	NALUTypeFD         NALUType = 38
+	NALUTypePrefixSEI  NALUType = 39
+	NALUTypeSuffixSEI  NALUType = 40
+	NALUTypeAggregated NALUType = 48
+	NALUTypeFU         NALUType = 49
+	NALUTypePACI       NALUType = 50
+)
+
+var (
+	errNilPacket         = errors.New("invalid nil packet")
+	errShortPacket       = errors.New("packet is not large enough")
+	errUnhandledNALUType = errors.New("NALU Type is unhandled")
+)
+
+func annexbNALUStartCode() []byte { return []byte{0x00, 0x00, 0x00, 0x01} }
+
+func naluType(nalu []byte) NALUType {
+	return NALUType((nalu[0] & naluTypeMask) >> naluTypeShift)
+}
+
+func layerID(nalu []byte) uint8 {
+	return uint8((uint16(nalu[0])<<8 | uint16(nalu[1])) & layerIDMask >> layerIDShift)
+}
+
+func tid(nalu []byte) uint8 {
+	return nalu[1] & tidMask
+}
+
+// emitNALUs calls emit for each NAL unit of a Annex B bitstream, without its start code.
+// A bitstream without start code is a single NAL unit.
+func emitNALUs(bitstream []byte, emit func([]byte)) {
+	start, zeros := 0, 0
+	for i, b := range bitstream {
+		switch {
+		case b == 0:
+			zeros++
+			continue
+		case b == 1 && zeros >= 2:
+			if nalu := bitstream[start : i-zeros]; len(nalu) > 0 {
+				emit(nalu)
+			}
+			start = i + 1
+		}
+		zeros = 0
+	}
+
+	if nalu := bitstream[start:]; len(nalu) > 0 {
+		emit(nalu)
+	}
+}
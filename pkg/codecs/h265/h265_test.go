@@ -0,0 +1,102 @@
+package h265
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	vps   = []byte{0x40, 0x01, 0x0C}
+	sps   = []byte{0x42, 0x01, 0x01, 0x01}
+	pps   = []byte{0x44, 0x01, 0xC1}
+	aud   = []byte{0x46, 0x01, 0x50}
+	slice = append([]byte{0x26, 0x01}, bytes.Repeat([]byte{0xAB}, 100)...)
+)
+
+func annexB(nalus ...[]byte) []byte {
+	out := []byte{}
+	for _, nalu := range nalus {
+		out = append(out, annexbNALUStartCode()...)
+		out = append(out, nalu...)
+	}
+	return out
+}
+
+func TestEmitNALUs(t *testing.T) {
+	nalus := [][]byte{}
+	emitNALUs(append(append([]byte{0x00, 0x00, 0x01}, vps...), annexB(sps, pps)...), func(nalu []byte) {
+		nalus = append(nalus, nalu)
+	})
+	assert.Equal(t, [][]byte{vps, sps, pps}, nalus)
+
+	nalus = [][]byte{}
+	emitNALUs(sps, func(nalu []byte) {
+		nalus = append(nalus, nalu)
+	})
+	assert.Equal(t, [][]byte{sps}, nalus)
+}
+
+func TestPayloader(t *testing.T) {
+	p := &Payloader{}
+
+	assert.Empty(t, p.Payload(1200, nil))
+	assert.Empty(t, p.Payload(3, annexB(sps)))
+
+	// Single NAL unit, the AUD is not sent
+	assert.Equal(t, [][]byte{slice}, p.Payload(1200, annexB(aud, slice)))
+
+	// Aggregation Packet, the slice doesn't fit into it anymore
+	assert.Equal(t, [][]byte{{
+		0x60, 0x01,
+		0x00, 0x03, 0x40, 0x01, 0x0C,
+		0x00, 0x04, 0x42, 0x01, 0x01, 0x01,
+		0x00, 0x03, 0x44, 0x01, 0xC1,
+	}, slice}, p.Payload(110, annexB(vps, sps, pps, slice)))
+
+	// Fragmentation Units
+	payloads := p.Payload(53, annexB(slice))
+	assert.Equal(t, 2, len(payloads))
+	assert.Equal(t, append([]byte{0x62, 0x01, 0x93}, slice[2:52]...), payloads[0])
+	assert.Equal(t, append([]byte{0x62, 0x01, 0x53}, slice[52:]...), payloads[1])
+}
+
+func TestPacket(t *testing.T) {
+	p := &Packet{}
+
+	_, err := p.Unmarshal(nil)
+	assert.True(t, errors.Is(err, errNilPacket))
+
+	_, err = p.Unmarshal([]byte{0x40, 0x01})
+	assert.True(t, errors.Is(err, errShortPacket))
+
+	_, err = p.Unmarshal([]byte{0x60, 0x01, 0x00, 0x05, 0x40})
+	assert.True(t, errors.Is(err, errShortPacket))
+
+	_, err = p.Unmarshal([]byte{0x64, 0x01, 0x00})
+	assert.True(t, errors.Is(err, errUnhandledNALUType))
+
+	assert.True(t, p.IsPartitionHead(slice))
+	assert.True(t, p.IsPartitionHead([]byte{0x62, 0x01, 0x93}))
+	assert.False(t, p.IsPartitionHead([]byte{0x62, 0x01, 0x53}))
+	assert.False(t, p.IsPartitionHead([]byte{}))
+}
+
+func TestPayloaderPacketRoundTrip(t *testing.T) {
+	accessUnit := annexB(vps, sps, pps, slice, slice)
+	for _, mtu := range []int{4, 20, 53, 1200} {
+		p := &Packet{}
+		out := []byte{}
+		for _, payload := range (&Payloader{}).Payload(mtu, accessUnit) {
+			assert.LessOrEqual(t, len(payload), mtu)
+
+			nalus, err := p.Unmarshal(payload)
+			assert.NoError(t, err)
+			out = append(out, nalus...)
+		}
+
+		assert.Equal(t, accessUnit, out, "MTU %d", mtu)
+	}
+}
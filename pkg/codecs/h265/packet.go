@@ -0,0 +1,73 @@
+package h265
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Packet depacketizes the H265 payload of RTP packets into NAL units in the Annex B format
+type Packet struct{}
+
+// Unmarshal parses the passed byte slice and returns the NAL units it contains.
+// The start of a fragmented NAL unit is returned with a start code, the following fragments without.
+func (p *Packet) Unmarshal(payload []byte) ([]byte, error) {
+	if payload == nil {
+		return nil, errNilPacket
+	} else if len(payload) <= naluHeaderSize {
+		return nil, fmt.Errorf("%w: %d <= %d", errShortPacket, len(payload), naluHeaderSize)
+	}
+
+	switch typ := naluType(payload); typ {
+	case NALUTypeAggregated:
+		result := []byte{}
+		for offset := naluHeaderSize; offset < len(payload); {
+			if offset+apNALULengthSize > len(payload) {
+				return nil, errShortPacket
+			}
+			size := int(binary.BigEndian.Uint16(payload[offset:]))
+			offset += apNALULengthSize
+
+			if offset+size > len(payload) {
+				return nil, fmt.Errorf("%w AP declared size(%d) is larger than buffer(%d)", errShortPacket, size, len(payload)-offset)
+			}
+
+			result = append(result, annexbNALUStartCode()...)
+			result = append(result, payload[offset:offset+size]...)
+			offset += size
+		}
+		return result, nil
+
+	case NALUTypeFU:
+		if len(payload) < fuHeaderSize {
+			return nil, errShortPacket
+		}
+
+		if payload[2]&fuStartBitmask == 0 {
+			return payload[fuHeaderSize:], nil
+		}
+
+		result := annexbNALUStartCode()
+		result = append(result, payload[0]&^naluTypeMask|(payload[2]&fuTypeMask)<<naluTypeShift, payload[1])
+		return append(result, payload[fuHeaderSize:]...), nil
+
+	case NALUTypePACI:
+		return nil, fmt.Errorf("%w: %d", errUnhandledNALUType, typ)
+
+	default:
+		return append(annexbNALUStartCode(), payload...), nil
+	}
+}
+
+// IsPartitionHead checks whether if this is a head of the H265 partition,
+// which is the case unless it continues a fragmented NAL unit
+func (*Packet) IsPartitionHead(payload []byte) bool {
+	if len(payload) < fuHeaderSize {
+		return len(payload) >= naluHeaderSize
+	}
+
+	if naluType(payload) == NALUTypeFU {
+		return payload[2]&fuStartBitmask != 0
+	}
+
+	return true
+}
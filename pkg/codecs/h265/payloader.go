@@ -0,0 +1,118 @@
+package h265
+
+import (
+	"encoding/binary"
+)
+
+// Payloader payloads H265 access units in the Annex B format. NAL units fitting the MTU
+// are sent in Aggregation Packets, larger ones in Fragmentation Units.
+type Payloader struct{}
+
+// Payload fragments a H265 access unit across one or more byte arrays
+func (p *Payloader) Payload(mtu int, payload []byte) [][]byte {
+	payloads := [][]byte{}
+	if len(payload) == 0 || mtu <= fuHeaderSize {
+		return payloads
+	}
+
+	var aggregated [][]byte
+	aggregatedSize := naluHeaderSize
+	flush := func() {
+		switch len(aggregated) {
+		case 0:
+		case 1:
+			payloads = append(payloads, append([]byte{}, aggregated[0]...))
+		default:
+			payloads = append(payloads, marshalAggregationPacket(aggregated, aggregatedSize))
+		}
+
+		aggregated = nil
+		aggregatedSize = naluHeaderSize
+	}
+
+	emitNALUs(payload, func(nalu []byte) {
+		if len(nalu) < naluHeaderSize {
+			return
+		}
+
+		switch naluType(nalu) {
+		case NALUTypeAUD, NALUTypeFD:
+			return
+		}
+
+		if len(nalu) > mtu {
+			flush()
+			payloads = append(payloads, fragment(mtu, nalu)...)
+			return
+		}
+
+		if aggregatedSize+apNALULengthSize+len(nalu) > mtu {
+			flush()
+		}
+		aggregated = append(aggregated, nalu)
+		aggregatedSize += apNALULengthSize + len(nalu)
+	})
+	flush()
+
+	return payloads
+}
+
+// marshalAggregationPacket creates a Aggregation Packet, its header uses the lowest
+// LayerId and TID of the aggregated NAL units.
+// https://tools.ietf.org/html/rfc7798#section-4.4.2
+func marshalAggregationPacket(nalus [][]byte, size int) []byte {
+	forbidden := byte(0)
+	minLayerID, minTID := uint8(maxLayerID), uint8(maxTID)
+	for _, nalu := range nalus {
+		forbidden |= nalu[0] & forbiddenBitMask
+		if l := layerID(nalu); l < minLayerID {
+			minLayerID = l
+		}
+		if t := tid(nalu); t < minTID {
+			minTID = t
+		}
+	}
+
+	out := make([]byte, naluHeaderSize, size)
+	out[0] = forbidden | byte(NALUTypeAggregated)<<naluTypeShift | minLayerID>>5
+	out[1] = minLayerID<<layerIDShift | minTID
+	for _, nalu := range nalus {
+		length := make([]byte, apNALULengthSize)
+		binary.BigEndian.PutUint16(length, uint16(len(nalu)))
+		out = append(out, length...)
+		out = append(out, nalu...)
+	}
+
+	return out
+}
+
+// fragment splits a NAL unit into Fragmentation Units
+// https://tools.ietf.org/html/rfc7798#section-4.4.3
+func fragment(mtu int, nalu []byte) [][]byte {
+	payloads := [][]byte{}
+	data := nalu[naluHeaderSize:]
+	maxFragmentSize := mtu - fuHeaderSize
+	for offset := 0; offset < len(data); {
+		size := len(data) - offset
+		if size > maxFragmentSize {
+			size = maxFragmentSize
+		}
+
+		out := make([]byte, fuHeaderSize+size)
+		out[0] = nalu[0]&^naluTypeMask | byte(NALUTypeFU)<<naluTypeShift
+		out[1] = nalu[1]
+		out[2] = byte(naluType(nalu))
+		if offset == 0 {
+			out[2] |= fuStartBitmask
+		}
+		if offset+size == len(data) {
+			out[2] |= fuEndBitmask
+		}
+		copy(out[fuHeaderSize:], data[offset:offset+size])
+
+		payloads = append(payloads, out)
+		offset += size
+	}
+
+	return payloads
+}
@@ -0,0 +1,124 @@
+// Package red implements the RTP payload format for redundant audio data
+// https://tools.ietf.org/html/rfc2198
+package red
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	followBit       = 0x80
+	payloadTypeMask = 0x7f
+	headerSize      = 4
+	lastHeaderSize  = 1
+	offsetShift     = 10
+
+	// MaxBlockLength is the largest payload of a redundant block
+	MaxBlockLength = 0x3ff
+
+	// MaxTimestampOffset is the largest offset of a redundant block from the primary block
+	MaxTimestampOffset = 0x3fff
+)
+
+var (
+	errNoBlocks                = errors.New("at least a primary block is required")
+	errShortPacket             = errors.New("packet is not large enough")
+	errBlockTooLarge           = errors.New("redundant block is too large")
+	errTimestampOffsetTooLarge = errors.New("timestamp offset of redundant block is too large")
+)
+
+// Block is an encoding of the media carried by a RED packet
+type Block struct {
+	PayloadType uint8
+
+	// TimestampOffset is the number of samples the block is behind the primary block.
+	// It is always zero for the primary block
+	TimestampOffset uint16
+
+	Payload []byte
+}
+
+// Marshal returns the payload of a RED packet carrying the blocks.
+// The redundant blocks come first, from the oldest, and the primary block last.
+func Marshal(blocks []Block) ([]byte, error) {
+	if len(blocks) == 0 {
+		return nil, errNoBlocks
+	}
+
+	size := lastHeaderSize
+	for i, b := range blocks {
+		if i != len(blocks)-1 {
+			switch {
+			case len(b.Payload) > MaxBlockLength:
+				return nil, errBlockTooLarge
+			case b.TimestampOffset > MaxTimestampOffset:
+				return nil, errTimestampOffsetTooLarge
+			}
+			size += headerSize
+		}
+		size += len(b.Payload)
+	}
+
+	out := make([]byte, 0, size)
+	for i, b := range blocks {
+		if i == len(blocks)-1 {
+			out = append(out, b.PayloadType&payloadTypeMask)
+			break
+		}
+
+		header := make([]byte, headerSize)
+		binary.BigEndian.PutUint32(header, uint32(followBit|b.PayloadType&payloadTypeMask)<<24|uint32(b.TimestampOffset)<<offsetShift|uint32(len(b.Payload)))
+		out = append(out, header...)
+	}
+
+	for _, b := range blocks {
+		out = append(out, b.Payload...)
+	}
+
+	return out, nil
+}
+
+// Unmarshal returns the blocks carried by the payload of a RED packet, in the order of Marshal.
+// The payloads of the blocks reference payload.
+func Unmarshal(payload []byte) ([]Block, error) {
+	blocks := []Block{}
+	lengths := []int{}
+
+	offset := 0
+	for {
+		if len(payload) < offset+lastHeaderSize {
+			return nil, errShortPacket
+		}
+
+		if payload[offset]&followBit == 0 {
+			blocks = append(blocks, Block{PayloadType: payload[offset] & payloadTypeMask})
+			offset += lastHeaderSize
+			break
+		}
+
+		if len(payload) < offset+headerSize {
+			return nil, errShortPacket
+		}
+
+		header := binary.BigEndian.Uint32(payload[offset:])
+		blocks = append(blocks, Block{
+			PayloadType:     payload[offset] & payloadTypeMask,
+			TimestampOffset: uint16(header>>offsetShift) & MaxTimestampOffset,
+		})
+		lengths = append(lengths, int(header&MaxBlockLength))
+		offset += headerSize
+	}
+
+	for i, length := range lengths {
+		if len(payload) < offset+length {
+			return nil, errShortPacket
+		}
+
+		blocks[i].Payload = payload[offset : offset+length]
+		offset += length
+	}
+	blocks[len(blocks)-1].Payload = payload[offset:]
+
+	return blocks, nil
+}
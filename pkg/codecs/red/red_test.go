@@ -0,0 +1,64 @@
+package red
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	_, err := Marshal(nil)
+	assert.Equal(t, errNoBlocks, err)
+
+	payload, err := Marshal([]Block{{PayloadType: 111, Payload: []byte{0xaa, 0xbb, 0xcc}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x6f, 0xaa, 0xbb, 0xcc}, payload)
+
+	// Example of https://tools.ietf.org/html/rfc2198#section-3, with a timestamp offset of 0x101 and a length of 2
+	payload, err = Marshal([]Block{
+		{PayloadType: 111, TimestampOffset: 0x101, Payload: []byte{0x01, 0x02}},
+		{PayloadType: 111, Payload: []byte{0x03}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xef, 0x04, 0x04, 0x02, 0x6f, 0x01, 0x02, 0x03}, payload)
+
+	_, err = Marshal([]Block{{TimestampOffset: MaxTimestampOffset + 1}, {}})
+	assert.Equal(t, errTimestampOffsetTooLarge, err)
+
+	_, err = Marshal([]Block{{Payload: make([]byte, MaxBlockLength+1)}, {}})
+	assert.Equal(t, errBlockTooLarge, err)
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, payload := range [][]byte{{}, {0xef, 0x04}, {0xef, 0x04, 0x04, 0x02}, {0xef, 0x04, 0x04, 0x02, 0x6f, 0x01}} {
+		_, err := Unmarshal(payload)
+		assert.Equal(t, errShortPacket, err)
+	}
+
+	blocks, err := Unmarshal([]byte{0xef, 0x04, 0x04, 0x02, 0xe0, 0x00, 0x08, 0x00, 0x6f, 0x01, 0x02, 0x03})
+	assert.NoError(t, err)
+	assert.Equal(t, []Block{
+		{PayloadType: 111, TimestampOffset: 0x101, Payload: []byte{0x01, 0x02}},
+		{PayloadType: 96, TimestampOffset: 2, Payload: []byte{}},
+		{PayloadType: 111, Payload: []byte{0x03}},
+	}, blocks)
+
+	blocks, err = Unmarshal([]byte{0x6f})
+	assert.NoError(t, err)
+	assert.Equal(t, []Block{{PayloadType: 111, Payload: []byte{}}}, blocks)
+}
+
+func TestRoundTrip(t *testing.T) {
+	blocks := []Block{
+		{PayloadType: 111, TimestampOffset: 1920, Payload: []byte{0x01, 0x02, 0x03}},
+		{PayloadType: 111, TimestampOffset: 960, Payload: []byte{0x04, 0x05}},
+		{PayloadType: 111, Payload: []byte{0x06, 0x07, 0x08, 0x09}},
+	}
+
+	payload, err := Marshal(blocks)
+	assert.NoError(t, err)
+
+	unmarshaled, err := Unmarshal(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks, unmarshaled)
+}
@@ -0,0 +1,71 @@
+// Package telephoneevent implements the RTP payload format for telephone events, like DTMF tones
+// https://tools.ietf.org/html/rfc4733
+package telephoneevent
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	endOfEventBit = 0x80
+	reservedBit   = 0x40
+	volumeMask    = 0x3f
+	payloadSize   = 4
+
+	// MaxVolume is the lowest power level of an event, in -dBm0
+	MaxVolume = 63
+)
+
+var (
+	errShortPacket    = errors.New("packet is not large enough")
+	errVolumeTooLarge = errors.New("volume is larger than 63")
+)
+
+// Event is the payload of a telephone event packet
+type Event struct {
+	// Event is the code of the event, 0-15 are the DTMF tones
+	Event uint8
+
+	// EndOfEvent is set on the packets sent once the event ended
+	EndOfEvent bool
+
+	// Volume is the power level of a tone, from 0 to -63 dBm0, as a positive number
+	Volume uint8
+
+	// Duration is the duration of the event so far, in units of the RTP clock rate,
+	// from the timestamp of the packet
+	Duration uint16
+}
+
+// Marshal returns the payload of a telephone event packet carrying the event
+func (e Event) Marshal() ([]byte, error) {
+	if e.Volume > MaxVolume {
+		return nil, errVolumeTooLarge
+	}
+
+	payload := make([]byte, payloadSize)
+	payload[0] = e.Event
+	payload[1] = e.Volume
+	if e.EndOfEvent {
+		payload[1] |= endOfEventBit
+	}
+	binary.BigEndian.PutUint16(payload[2:], e.Duration)
+
+	return payload, nil
+}
+
+// Unmarshal parses the payload of a telephone event packet. Events that may follow the first one,
+// in the redundant format of the payload, are ignored
+func (e *Event) Unmarshal(payload []byte) error {
+	if len(payload) < payloadSize {
+		return errShortPacket
+	}
+
+	e.Event = payload[0]
+	e.EndOfEvent = payload[1]&endOfEventBit != 0
+	e.Volume = payload[1] & volumeMask
+	e.Duration = binary.BigEndian.Uint16(payload[2:])
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package telephoneevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent(t *testing.T) {
+	for _, test := range []struct {
+		Event   Event
+		Payload []byte
+	}{
+		{Event{Event: 1, Volume: 10, Duration: 400}, []byte{0x01, 0x0a, 0x01, 0x90}},
+		{Event{Event: 11, EndOfEvent: true, Volume: 63, Duration: 65535}, []byte{0x0b, 0xbf, 0xff, 0xff}},
+	} {
+		payload, err := test.Event.Marshal()
+		assert.NoError(t, err)
+		assert.Equal(t, test.Payload, payload)
+
+		var e Event
+		assert.NoError(t, e.Unmarshal(payload))
+		assert.Equal(t, test.Event, e)
+	}
+
+	t.Run("Reserved bit", func(t *testing.T) {
+		var e Event
+		assert.NoError(t, e.Unmarshal([]byte{0x05, reservedBit | 0x80 | 0x07, 0x00, 0xa0}))
+		assert.Equal(t, Event{Event: 5, EndOfEvent: true, Volume: 7, Duration: 160}, e)
+	})
+
+	t.Run("Short packet", func(t *testing.T) {
+		var e Event
+		assert.Equal(t, errShortPacket, e.Unmarshal([]byte{0x01, 0x0a, 0x01}))
+	})
+
+	t.Run("Volume too large", func(t *testing.T) {
+		_, err := Event{Volume: 64}.Marshal()
+		assert.Equal(t, errVolumeTooLarge, err)
+	})
+}
@@ -0,0 +1,67 @@
+package headerextension
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/pion/webrtc/v3/internal/ntp"
+)
+
+const (
+	absCaptureTimeSize           = 8
+	absCaptureTimeWithOffsetSize = 16
+)
+
+// AbsCaptureTime is the NTP time the first sample of the frame in the packet was captured at,
+// on the clock of the original capturer. It is usually sent on key frames and every second
+// https://webrtc.googlesource.com/src/+/refs/heads/master/docs/native-code/rtp-hdrext/abs-capture-time
+type AbsCaptureTime struct {
+	// Timestamp is a 64 bit NTP timestamp
+	Timestamp uint64
+
+	// EstimatedCaptureClockOffset is the estimated offset between the clock of the capturer and the
+	// clock of the sender, in seconds as a signed 32.32 fixed point number. It is omitted if nil
+	EstimatedCaptureClockOffset *int64
+}
+
+// NewAbsCaptureTime returns the AbsCaptureTime of a frame captured at captureTime
+func NewAbsCaptureTime(captureTime time.Time) AbsCaptureTime {
+	return AbsCaptureTime{Timestamp: ntp.FromTime(captureTime)}
+}
+
+// URI returns AbsCaptureTimeURI
+func (a AbsCaptureTime) URI() string { return AbsCaptureTimeURI }
+
+// CaptureTime returns the time the frame was captured at
+func (a AbsCaptureTime) CaptureTime() time.Time {
+	return ntp.ToTime(a.Timestamp)
+}
+
+// Marshal returns the payload of the extension
+func (a AbsCaptureTime) Marshal() ([]byte, error) {
+	if a.EstimatedCaptureClockOffset == nil {
+		payload := make([]byte, absCaptureTimeSize)
+		binary.BigEndian.PutUint64(payload, a.Timestamp)
+		return payload, nil
+	}
+
+	payload := make([]byte, absCaptureTimeWithOffsetSize)
+	binary.BigEndian.PutUint64(payload, a.Timestamp)
+	binary.BigEndian.PutUint64(payload[absCaptureTimeSize:], uint64(*a.EstimatedCaptureClockOffset))
+	return payload, nil
+}
+
+// Unmarshal parses the payload of the extension
+func (a *AbsCaptureTime) Unmarshal(payload []byte) error {
+	if len(payload) < absCaptureTimeSize {
+		return errShortPayload
+	}
+
+	a.Timestamp = binary.BigEndian.Uint64(payload)
+	a.EstimatedCaptureClockOffset = nil
+	if len(payload) >= absCaptureTimeWithOffsetSize {
+		offset := int64(binary.BigEndian.Uint64(payload[absCaptureTimeSize:]))
+		a.EstimatedCaptureClockOffset = &offset
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package headerextension
+
+import (
+	"time"
+
+	"github.com/pion/webrtc/v3/internal/ntp"
+)
+
+const (
+	absSendTimeSize = 3
+
+	// absSendTimeShift is the number of bits dropped from the fraction of a NTP timestamp,
+	// leaving 18 bits of fraction
+	absSendTimeShift = 14
+
+	// absSendTimeWrap is the period of the 6 bits of seconds of a timestamp, in NTP units
+	absSendTimeWrap = uint64(1) << 38
+)
+
+// AbsSendTime is the time the packet was sent at, as 24 bits of a NTP timestamp: 6 bits of
+// seconds and 18 bits of fraction. It wraps every 64 seconds
+// https://webrtc.googlesource.com/src/+/refs/heads/master/docs/native-code/rtp-hdrext/abs-send-time
+type AbsSendTime struct {
+	Timestamp uint32
+}
+
+// NewAbsSendTime returns the AbsSendTime of a packet sent at sendTime
+func NewAbsSendTime(sendTime time.Time) AbsSendTime {
+	return AbsSendTime{Timestamp: uint32(ntp.FromTime(sendTime)>>absSendTimeShift) & 0xffffff}
+}
+
+// URI returns AbsSendTimeURI
+func (a AbsSendTime) URI() string { return AbsSendTimeURI }
+
+// Marshal returns the payload of the extension
+func (a AbsSendTime) Marshal() ([]byte, error) {
+	return []byte{byte(a.Timestamp >> 16), byte(a.Timestamp >> 8), byte(a.Timestamp)}, nil
+}
+
+// Unmarshal parses the payload of the extension
+func (a *AbsSendTime) Unmarshal(payload []byte) error {
+	if len(payload) < absSendTimeSize {
+		return errShortPayload
+	}
+
+	a.Timestamp = uint32(payload[0])<<16 | uint32(payload[1])<<8 | uint32(payload[2])
+	return nil
+}
+
+// Estimate returns the time the packet was sent at, assuming it was received at receiveTime
+// less than 64 seconds after it was sent
+func (a AbsSendTime) Estimate(receiveTime time.Time) time.Time {
+	receive := ntp.FromTime(receiveTime)
+	send := receive&^(absSendTimeWrap-1) | uint64(a.Timestamp&0xffffff)<<absSendTimeShift
+	if send > receive {
+		send -= absSendTimeWrap
+	}
+
+	return ntp.ToTime(send)
+}
@@ -0,0 +1,47 @@
+package headerextension
+
+const (
+	audioLevelSize      = 1
+	audioLevelVoiceFlag = 0x80
+	audioLevelMask      = 0x7f
+
+	// MaxAudioLevel is the level of silence, in -dBov
+	MaxAudioLevel = 127
+)
+
+// AudioLevel is the audio level of the audio in the packet
+// https://tools.ietf.org/html/rfc6464
+type AudioLevel struct {
+	// Level is the audio level, from 0 to -127 dBov, as a positive number
+	Level uint8
+
+	// Voice is set if the packet contains voice
+	Voice bool
+}
+
+// URI returns AudioLevelURI
+func (a AudioLevel) URI() string { return AudioLevelURI }
+
+// Marshal returns the payload of the extension
+func (a AudioLevel) Marshal() ([]byte, error) {
+	if a.Level > MaxAudioLevel {
+		return nil, errAudioLevelTooLarge
+	}
+
+	payload := []byte{a.Level}
+	if a.Voice {
+		payload[0] |= audioLevelVoiceFlag
+	}
+	return payload, nil
+}
+
+// Unmarshal parses the payload of the extension
+func (a *AudioLevel) Unmarshal(payload []byte) error {
+	if len(payload) < audioLevelSize {
+		return errShortPayload
+	}
+
+	a.Level = payload[0] & audioLevelMask
+	a.Voice = payload[0]&audioLevelVoiceFlag != 0
+	return nil
+}
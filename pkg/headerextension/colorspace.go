@@ -0,0 +1,101 @@
+package headerextension
+
+import "encoding/binary"
+
+const (
+	colorSpaceSize             = 4
+	colorSpaceWithMetadataSize = 28
+)
+
+// ColorSpace is the color space of the video frame in the packet, as the code points of ISO/IEC 23091-2.
+// It is usually sent on key frames
+// https://webrtc.googlesource.com/src/+/refs/heads/master/docs/native-code/rtp-hdrext/color-space
+type ColorSpace struct {
+	Primaries          uint8
+	Transfer           uint8
+	MatrixCoefficients uint8
+
+	// Range is 0 for invalid, 1 for limited and 2 for full range
+	Range uint8
+
+	// ChromaSitingHorizontal and ChromaSitingVertical are 0 for unspecified,
+	// 1 for collocated and 2 for half
+	ChromaSitingHorizontal uint8
+	ChromaSitingVertical   uint8
+
+	// HDRMetadata is omitted if nil
+	HDRMetadata *HDRMetadata
+}
+
+// HDRMetadata is the HDR metadata of a video frame (SMPTE ST 2086 and CTA-861.3)
+type HDRMetadata struct {
+	// Chromaticity coordinates of the mastering display, in units of 1/50000
+	PrimaryRX, PrimaryRY     uint16
+	PrimaryGX, PrimaryGY     uint16
+	PrimaryBX, PrimaryBY     uint16
+	WhitePointX, WhitePointY uint16
+
+	// LuminanceMax is in cd/m², LuminanceMin in units of 0.0001 cd/m²
+	LuminanceMax, LuminanceMin uint16
+
+	// MaxContentLightLevel and MaxFrameAverageLightLevel are in cd/m²
+	MaxContentLightLevel      uint16
+	MaxFrameAverageLightLevel uint16
+}
+
+// URI returns ColorSpaceURI
+func (c ColorSpace) URI() string { return ColorSpaceURI }
+
+// Marshal returns the payload of the extension
+func (c ColorSpace) Marshal() ([]byte, error) {
+	if c.Range > 3 || c.ChromaSitingHorizontal > 3 || c.ChromaSitingVertical > 3 {
+		return nil, errColorSpaceValueTooBig
+	}
+
+	size := colorSpaceSize
+	if c.HDRMetadata != nil {
+		size = colorSpaceWithMetadataSize
+	}
+
+	payload := make([]byte, size)
+	payload[0], payload[1], payload[2] = c.Primaries, c.Transfer, c.MatrixCoefficients
+	payload[3] = c.Range<<4 | c.ChromaSitingHorizontal<<2 | c.ChromaSitingVertical
+
+	if m := c.HDRMetadata; m != nil {
+		for i, v := range []uint16{
+			m.PrimaryRX, m.PrimaryRY, m.PrimaryGX, m.PrimaryGY, m.PrimaryBX, m.PrimaryBY, m.WhitePointX, m.WhitePointY,
+			m.LuminanceMax, m.LuminanceMin, m.MaxContentLightLevel, m.MaxFrameAverageLightLevel,
+		} {
+			binary.BigEndian.PutUint16(payload[colorSpaceSize+2*i:], v)
+		}
+	}
+
+	return payload, nil
+}
+
+// Unmarshal parses the payload of the extension
+func (c *ColorSpace) Unmarshal(payload []byte) error {
+	if len(payload) < colorSpaceSize {
+		return errShortPayload
+	}
+
+	c.Primaries, c.Transfer, c.MatrixCoefficients = payload[0], payload[1], payload[2]
+	c.Range = payload[3] >> 4 & 0x03
+	c.ChromaSitingHorizontal = payload[3] >> 2 & 0x03
+	c.ChromaSitingVertical = payload[3] & 0x03
+
+	c.HDRMetadata = nil
+	if len(payload) < colorSpaceWithMetadataSize {
+		return nil
+	}
+
+	m := &HDRMetadata{}
+	for i, v := range []*uint16{
+		&m.PrimaryRX, &m.PrimaryRY, &m.PrimaryGX, &m.PrimaryGY, &m.PrimaryBX, &m.PrimaryBY, &m.WhitePointX, &m.WhitePointY,
+		&m.LuminanceMax, &m.LuminanceMin, &m.MaxContentLightLevel, &m.MaxFrameAverageLightLevel,
+	} {
+		*v = binary.BigEndian.Uint16(payload[colorSpaceSize+2*i:])
+	}
+	c.HDRMetadata = m
+	return nil
+}
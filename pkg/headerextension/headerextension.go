@@ -0,0 +1,37 @@
+// Package headerextension implements the payload formats of common RTP header extensions (RFC 8285).
+// The IDs of the extensions are negotiated per PeerConnection, TrackLocalContext and TrackRemote map
+// an Extension to the ID negotiated for its URI.
+package headerextension
+
+import "errors"
+
+// URIs of the header extensions of this package
+const (
+	AudioLevelURI       = "urn:ietf:params:rtp-hdrext:ssrc-audio-level"
+	AbsSendTimeURI      = "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time"
+	VideoOrientationURI = "urn:3gpp:video-orientation"
+	PlayoutDelayURI     = "http://www.webrtc.org/experiments/rtp-hdrext/playout-delay"
+	AbsCaptureTimeURI   = "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time"
+	ColorSpaceURI       = "http://www.webrtc.org/experiments/rtp-hdrext/color-space"
+)
+
+var (
+	errShortPayload          = errors.New("header extension payload is not large enough")
+	errAudioLevelTooLarge    = errors.New("audio level is larger than 127")
+	errInvalidRotation       = errors.New("rotation is not 0, 90, 180 or 270 degrees")
+	errPlayoutDelayTooLarge  = errors.New("playout delay is larger than 40950ms")
+	errPlayoutDelayMinMax    = errors.New("minimum playout delay is larger than the maximum")
+	errColorSpaceValueTooBig = errors.New("color space range and chroma siting values must be smaller than 4")
+)
+
+// Extension is the value of a RTP header extension
+type Extension interface {
+	// URI identifies the extension in the SDP
+	URI() string
+
+	// Marshal returns the payload of the extension
+	Marshal() ([]byte, error)
+
+	// Unmarshal parses the payload of the extension
+	Unmarshal(payload []byte) error
+}
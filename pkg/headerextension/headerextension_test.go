@@ -0,0 +1,91 @@
+package headerextension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	offset := int64(-1) << 32
+
+	for _, test := range []struct {
+		Name      string
+		Extension Extension
+		Parsed    Extension
+		Payload   []byte
+	}{
+		{"AudioLevel", &AudioLevel{Level: 42, Voice: true}, &AudioLevel{}, []byte{0xaa}},
+		{"AbsSendTime", &AbsSendTime{Timestamp: 0x123456}, &AbsSendTime{}, []byte{0x12, 0x34, 0x56}},
+		{"VideoOrientation", &VideoOrientation{BackCamera: true, Rotation: 270}, &VideoOrientation{}, []byte{0x0b}},
+		{"PlayoutDelay", &PlayoutDelay{MinDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second}, &PlayoutDelay{}, []byte{0x00, 0xa0, 0xc8}},
+		{"AbsCaptureTime", &AbsCaptureTime{Timestamp: 0x0102030405060708}, &AbsCaptureTime{}, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}},
+		{
+			"AbsCaptureTime with offset",
+			&AbsCaptureTime{Timestamp: 0x0102030405060708, EstimatedCaptureClockOffset: &offset},
+			&AbsCaptureTime{},
+			[]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00},
+		},
+		{"ColorSpace", &ColorSpace{Primaries: 1, Transfer: 13, MatrixCoefficients: 6, Range: 2, ChromaSitingHorizontal: 1, ChromaSitingVertical: 2}, &ColorSpace{}, []byte{0x01, 0x0d, 0x06, 0x26}},
+		{
+			"ColorSpace with HDR metadata",
+			&ColorSpace{Primaries: 9, Transfer: 16, MatrixCoefficients: 9, Range: 1, HDRMetadata: &HDRMetadata{
+				PrimaryRX: 1, PrimaryRY: 2, PrimaryGX: 3, PrimaryGY: 4, PrimaryBX: 5, PrimaryBY: 6, WhitePointX: 7, WhitePointY: 8,
+				LuminanceMax: 1000, LuminanceMin: 50, MaxContentLightLevel: 800, MaxFrameAverageLightLevel: 400,
+			}},
+			&ColorSpace{},
+			[]byte{
+				0x09, 0x10, 0x09, 0x10,
+				0x00, 0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00, 0x05, 0x00, 0x06, 0x00, 0x07, 0x00, 0x08,
+				0x03, 0xe8, 0x00, 0x32, 0x03, 0x20, 0x01, 0x90,
+			},
+		},
+	} {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			payload, err := test.Extension.Marshal()
+			assert.NoError(t, err)
+			assert.Equal(t, test.Payload, payload)
+
+			assert.NoError(t, test.Parsed.Unmarshal(payload))
+			assert.Equal(t, test.Extension, test.Parsed)
+			assert.Equal(t, test.Extension.URI(), test.Parsed.URI())
+
+			assert.Equal(t, errShortPayload, test.Parsed.Unmarshal([]byte{}))
+		})
+	}
+}
+
+func TestExtensions_Invalid(t *testing.T) {
+	for _, test := range []struct {
+		Extension Extension
+		Err       error
+	}{
+		{&AudioLevel{Level: 128}, errAudioLevelTooLarge},
+		{&VideoOrientation{Rotation: 45}, errInvalidRotation},
+		{&VideoOrientation{Rotation: 360}, errInvalidRotation},
+		{&PlayoutDelay{MaxDelay: MaxPlayoutDelay + playoutDelayUnit}, errPlayoutDelayTooLarge},
+		{&PlayoutDelay{MinDelay: time.Second, MaxDelay: 500 * time.Millisecond}, errPlayoutDelayMinMax},
+		{&ColorSpace{Range: 4}, errColorSpaceValueTooBig},
+	} {
+		_, err := test.Extension.Marshal()
+		assert.Equal(t, test.Err, err)
+	}
+}
+
+func TestAbsSendTime_Estimate(t *testing.T) {
+	sendTime := time.Unix(1600000000, 123456789)
+	a := NewAbsSendTime(sendTime)
+
+	// 18 bits of fraction are about 4us
+	for _, delay := range []time.Duration{0, 10 * time.Millisecond, 63 * time.Second} {
+		estimate := a.Estimate(sendTime.Add(delay))
+		assert.InDelta(t, 0, estimate.Sub(sendTime).Nanoseconds(), float64(4*time.Microsecond))
+	}
+}
+
+func TestAbsCaptureTime_CaptureTime(t *testing.T) {
+	captureTime := time.Unix(1600000000, 123456789)
+	assert.InDelta(t, 0, NewAbsCaptureTime(captureTime).CaptureTime().Sub(captureTime).Nanoseconds(), 1)
+}
@@ -0,0 +1,49 @@
+package headerextension
+
+import "time"
+
+const (
+	playoutDelaySize = 3
+
+	// playoutDelayUnit is the granularity of the delays
+	playoutDelayUnit = 10 * time.Millisecond
+
+	// MaxPlayoutDelay is the longest delay the extension can carry
+	MaxPlayoutDelay = 0xfff * playoutDelayUnit
+)
+
+// PlayoutDelay is the range of delay the receiver should render the video with, from capture to render,
+// in steps of 10ms. A zero delay asks for rendering as fast as possible
+// https://webrtc.googlesource.com/src/+/refs/heads/master/docs/native-code/rtp-hdrext/playout-delay
+type PlayoutDelay struct {
+	MinDelay, MaxDelay time.Duration
+}
+
+// URI returns PlayoutDelayURI
+func (p PlayoutDelay) URI() string { return PlayoutDelayURI }
+
+// Marshal returns the payload of the extension, the delays are rounded down to 10ms
+func (p PlayoutDelay) Marshal() ([]byte, error) {
+	switch {
+	case p.MinDelay < 0 || p.MaxDelay < 0 || p.MinDelay > MaxPlayoutDelay || p.MaxDelay > MaxPlayoutDelay:
+		return nil, errPlayoutDelayTooLarge
+	case p.MinDelay > p.MaxDelay:
+		return nil, errPlayoutDelayMinMax
+	}
+
+	minDelay, maxDelay := uint16(p.MinDelay/playoutDelayUnit), uint16(p.MaxDelay/playoutDelayUnit)
+	return []byte{byte(minDelay >> 4), byte(minDelay<<4) | byte(maxDelay>>8), byte(maxDelay)}, nil
+}
+
+// Unmarshal parses the payload of the extension
+func (p *PlayoutDelay) Unmarshal(payload []byte) error {
+	if len(payload) < playoutDelaySize {
+		return errShortPayload
+	}
+
+	minDelay := uint16(payload[0])<<4 | uint16(payload[1]>>4)
+	maxDelay := uint16(payload[1]&0x0f)<<8 | uint16(payload[2])
+	p.MinDelay = time.Duration(minDelay) * playoutDelayUnit
+	p.MaxDelay = time.Duration(maxDelay) * playoutDelayUnit
+	return nil
+}
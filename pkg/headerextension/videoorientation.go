@@ -0,0 +1,52 @@
+package headerextension
+
+const (
+	videoOrientationSize       = 1
+	videoOrientationCameraFlag = 0x08
+	videoOrientationFlipFlag   = 0x04
+	videoOrientationRotation   = 0x03
+)
+
+// VideoOrientation is the orientation of the video in the packet, applied by the receiver when rendering it
+// https://www.etsi.org/deliver/etsi_ts/126100_126199/126114/16.07.00_60/ts_126114v160700p.pdf (7.4.5)
+type VideoOrientation struct {
+	// BackCamera is set if the video is captured by a back-facing camera
+	BackCamera bool
+
+	// Flip is set if the video is flipped horizontally
+	Flip bool
+
+	// Rotation is the clockwise rotation of the video, 0, 90, 180 or 270 degrees
+	Rotation uint16
+}
+
+// URI returns VideoOrientationURI
+func (v VideoOrientation) URI() string { return VideoOrientationURI }
+
+// Marshal returns the payload of the extension
+func (v VideoOrientation) Marshal() ([]byte, error) {
+	if v.Rotation%90 != 0 || v.Rotation >= 360 {
+		return nil, errInvalidRotation
+	}
+
+	payload := []byte{byte(v.Rotation / 90)}
+	if v.BackCamera {
+		payload[0] |= videoOrientationCameraFlag
+	}
+	if v.Flip {
+		payload[0] |= videoOrientationFlipFlag
+	}
+	return payload, nil
+}
+
+// Unmarshal parses the payload of the extension
+func (v *VideoOrientation) Unmarshal(payload []byte) error {
+	if len(payload) < videoOrientationSize {
+		return errShortPayload
+	}
+
+	v.BackCamera = payload[0]&videoOrientationCameraFlag != 0
+	v.Flip = payload[0]&videoOrientationFlipFlag != 0
+	v.Rotation = uint16(payload[0]&videoOrientationRotation) * 90
+	return nil
+}
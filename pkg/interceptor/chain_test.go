@@ -0,0 +1,129 @@
+package interceptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+type orderInterceptor struct {
+	NoOp
+	name     string
+	calls    *[]string
+	closeErr error
+}
+
+func (o *orderInterceptor) BindLocalStream(_ *StreamInfo, writer RTPWriter) RTPWriter {
+	return RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes Attributes) (int, error) {
+		*o.calls = append(*o.calls, o.name)
+		return writer.Write(header, payload, attributes)
+	})
+}
+
+func (o *orderInterceptor) BindRTCPWriter(writer RTCPWriter) RTCPWriter {
+	return RTCPWriterFunc(func(pkts []rtcp.Packet, attributes Attributes) (int, error) {
+		*o.calls = append(*o.calls, o.name)
+		return writer.Write(pkts, attributes)
+	})
+}
+
+func (o *orderInterceptor) Close() error {
+	return o.closeErr
+}
+
+type orderFactory struct {
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (f *orderFactory) NewInterceptor(id string) (Interceptor, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &orderInterceptor{name: f.name, calls: f.calls}, nil
+}
+
+func TestChain(t *testing.T) {
+	calls := []string{}
+	chain := NewChain([]Interceptor{
+		&orderInterceptor{name: "a", calls: &calls},
+		&orderInterceptor{name: "b", calls: &calls},
+	})
+
+	writer := chain.BindLocalStream(&StreamInfo{}, RTPWriterFunc(func(header *rtp.Header, payload []byte, _ Attributes) (int, error) {
+		calls = append(calls, "transport")
+		return len(payload), nil
+	}))
+
+	n, err := writer.Write(&rtp.Header{}, []byte{0x01, 0x02}, Attributes{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// The last bound interceptor is the first to see outgoing packets
+	assert.Equal(t, []string{"b", "a", "transport"}, calls)
+
+	calls = calls[:0]
+	rtcpWriter := chain.BindRTCPWriter(RTCPWriterFunc(func(pkts []rtcp.Packet, _ Attributes) (int, error) {
+		calls = append(calls, "transport")
+		return 0, nil
+	}))
+	_, err = rtcpWriter.Write([]rtcp.Packet{&rtcp.PictureLossIndication{}}, Attributes{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "a", "transport"}, calls)
+
+	interceptors := chain.Interceptors()
+	assert.Len(t, interceptors, 2)
+	assert.Equal(t, "a", interceptors[0].(*orderInterceptor).name)
+}
+
+func TestChain_Close(t *testing.T) {
+	errA := errors.New("a")
+	chain := NewChain([]Interceptor{
+		&orderInterceptor{name: "a", closeErr: errA},
+		&orderInterceptor{name: "b"},
+	})
+
+	assert.True(t, errors.Is(chain.Close(), errA))
+	assert.NoError(t, NewChain(nil).Close())
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		i, err := (&Registry{}).Build("")
+		assert.NoError(t, err)
+		assert.IsType(t, &NoOp{}, i)
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		calls := []string{}
+		r := &Registry{}
+		r.Add(&orderFactory{name: "a", calls: &calls})
+		r.Add(&orderFactory{name: "b", calls: &calls})
+
+		i, err := r.Build("")
+		assert.NoError(t, err)
+		assert.IsType(t, &Chain{}, i)
+		assert.Len(t, i.(*Chain).interceptors, 2)
+	})
+
+	t.Run("Factory Error", func(t *testing.T) {
+		errFactory := errors.New("factory")
+		r := &Registry{}
+		r.Add(&orderFactory{err: errFactory})
+
+		_, err := r.Build("")
+		assert.True(t, errors.Is(err, errFactory))
+	})
+}
+
+func TestAttributes(t *testing.T) {
+	a := Attributes{}
+	assert.Nil(t, a.Get("key"))
+
+	a.Set("key", 5)
+	assert.Equal(t, 5, a.Get("key"))
+}
@@ -0,0 +1,214 @@
+package fec
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor/internal/sequence"
+)
+
+const (
+	// decoderBufferSize is the number of received packets kept to recover lost packets
+	decoderBufferSize = 256
+
+	// decoderMaxPendingPackets is the number of FEC packets kept until the packets they protect are read
+	decoderMaxPendingPackets = 16
+)
+
+// Decoder recovers the lost packets of a remote stream from the packets of its FEC stream, or from the ULPFEC
+// packets sent inside RED in the stream itself. Every packet read from the media stream must be given to Push.
+// A packet is only recovered once a later packet of the media stream was read, so packets that are late rather
+// than lost aren't recovered a second time.
+type Decoder struct {
+	ssrc uint32
+
+	// redPayloadType and ulpfecPayloadType are set if the stream is protected with ULPFEC
+	ulpfec            bool
+	redPayloadType    uint8
+	ulpfecPayloadType uint8
+
+	mu                 sync.Mutex
+	packets            [decoderBufferSize]receivedPacket
+	hasPushed          bool
+	lastSequenceNumber uint16
+
+	// pending are the FEC packets protecting packets that weren't all read yet
+	pending []*fecPacket
+}
+
+type receivedPacket struct {
+	sequenceNumber uint16
+	raw            []byte
+}
+
+// NewDecoder returns a Decoder for the stream ssrc, protected with the FEC stream given to Recover
+func NewDecoder(ssrc uint32) *Decoder {
+	return &Decoder{ssrc: ssrc}
+}
+
+// NewULPFECDecoder returns a Decoder for the stream ssrc, protected with ULPFEC. The media and the ULPFEC
+// packets are sent inside RED, with the payload types redPayloadType and ulpfecPayloadType
+func NewULPFECDecoder(ssrc uint32, redPayloadType, ulpfecPayloadType uint8) *Decoder {
+	return &Decoder{ssrc: ssrc, ulpfec: true, redPayloadType: redPayloadType, ulpfecPayloadType: ulpfecPayloadType}
+}
+
+// Push records a marshaled RTP packet read from the media stream. It returns the packet to read in its place,
+// and the marshaled RTP packets recovered with the FEC packets that were waiting for the media stream to be read
+// this far. The packet to read is the media packet carried by packet if it was sent inside RED, and packet itself
+// otherwise. ULPFEC packets are returned as they are, see IsFEC.
+func (d *Decoder) Push(packet []byte) ([]byte, [][]byte) {
+	if len(packet) < rtpHeaderSize || binary.BigEndian.Uint32(packet[8:12]) != d.ssrc {
+		return packet, nil
+	}
+
+	media, ulpfec := packet, (*fecPacket)(nil)
+	if d.isRED(packet) {
+		header, payload, err := unwrapRED(packet)
+		if err != nil {
+			return packet, nil
+		}
+
+		if header.PayloadType == d.ulpfecPayloadType {
+			if ulpfec, err = unmarshalULPFEC(payload); err != nil {
+				return packet, nil
+			}
+			ulpfec.ssrc = d.ssrc
+		} else if media, err = (&rtp.Packet{Header: *header, Payload: payload}).Marshal(); err != nil {
+			return packet, nil
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The sequence number of a ULPFEC packet is the one of the stream, it counts as read
+	sequenceNumber := binary.BigEndian.Uint16(packet[2:4])
+	if ulpfec == nil {
+		d.push(sequenceNumber, media)
+	} else if len(ulpfec.offsets) != 0 {
+		d.addPending(ulpfec)
+	}
+	if !d.hasPushed || sequenceNumber-d.lastSequenceNumber < sequence.Uint16SizeHalf {
+		d.hasPushed = true
+		d.lastSequenceNumber = sequenceNumber
+	}
+
+	var recovered [][]byte
+	pending := d.pending[:0]
+	for _, f := range d.pending {
+		packet, done := d.recover(f)
+		if packet != nil {
+			recovered = append(recovered, packet)
+		}
+		if !done {
+			pending = append(pending, f)
+		}
+	}
+	d.pending = pending
+
+	return media, recovered
+}
+
+// IsFEC returns true if packet, read from the media stream, is a ULPFEC packet rather than a media packet.
+// Push returns them as they are, so they are counted along with the media packets, before being dropped
+func (d *Decoder) IsFEC(packet []byte) bool {
+	if !d.isRED(packet) {
+		return false
+	}
+
+	header, _, err := unwrapRED(packet)
+	return err == nil && header.PayloadType == d.ulpfecPayloadType
+}
+
+func (d *Decoder) isRED(packet []byte) bool {
+	return d.ulpfec && len(packet) >= rtpHeaderSize && packet[1]&0x7f == d.redPayloadType
+}
+
+func (d *Decoder) push(sequenceNumber uint16, packet []byte) {
+	p := &d.packets[sequenceNumber%decoderBufferSize]
+	p.sequenceNumber = sequenceNumber
+	p.raw = append(p.raw[:0], packet...)
+}
+
+func (d *Decoder) get(sequenceNumber uint16) []byte {
+	p := &d.packets[sequenceNumber%decoderBufferSize]
+	if p.raw == nil || p.sequenceNumber != sequenceNumber {
+		return nil
+	}
+	return p.raw
+}
+
+// Recover takes the payload of a packet of the FlexFEC stream, in the format of mimeType. It returns the
+// marshaled RTP packet recovered with it, or nil if none of the packets it protects is missing, or more than one.
+// If the media stream wasn't read past the packets it protects yet, the FEC packet is kept and the packet it
+// recovers is returned by Push instead.
+func (d *Decoder) Recover(mimeType string, payload []byte) ([]byte, error) {
+	if !isFlexFEC03(mimeType) {
+		return nil, errUnsupportedMechanism
+	}
+
+	f, err := unmarshalFlexFEC03(payload)
+	if err != nil {
+		return nil, err
+	} else if f.ssrc != d.ssrc || len(f.offsets) == 0 {
+		return nil, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	packet, done := d.recover(f)
+	if !done {
+		d.addPending(f)
+	}
+
+	return packet, nil
+}
+
+// addPending keeps f until the packets it protects were read, dropping the oldest FEC packet kept if there are too many
+func (d *Decoder) addPending(f *fecPacket) {
+	if len(d.pending) == decoderMaxPendingPackets {
+		d.pending = d.pending[1:]
+	}
+	d.pending = append(d.pending, f)
+}
+
+// recover returns the packet recovered with f. done is false if f has to be kept,
+// because the media stream wasn't read past the packets it protects yet
+func (d *Decoder) recover(f *fecPacket) (packet []byte, done bool) {
+	sequenceNumbers := f.sequenceNumbers()
+	last := sequenceNumbers[len(sequenceNumbers)-1]
+	if !d.hasPushed || d.lastSequenceNumber-last >= sequence.Uint16SizeHalf {
+		return nil, false
+	}
+
+	r := f.recovery
+	r.payload = append([]byte{}, f.payload...)
+
+	missing, hasMissing := uint16(0), false
+	for _, sequenceNumber := range sequenceNumbers {
+		packet := d.get(sequenceNumber)
+		if packet == nil {
+			if hasMissing {
+				return nil, true
+			}
+			missing, hasMissing = sequenceNumber, true
+			continue
+		}
+
+		r.add(packet)
+	}
+
+	if !hasMissing {
+		return nil, true
+	}
+
+	packet, ok := r.packet(d.ssrc, missing)
+	if !ok {
+		return nil, true
+	}
+
+	d.push(missing, packet)
+	return packet, true
+}
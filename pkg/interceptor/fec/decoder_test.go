@@ -0,0 +1,125 @@
+package fec
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	packets := []*rtp.Packet{
+		{Header: rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 65534, Timestamp: 3000, SSRC: 1}, Payload: []byte{0x01, 0x02, 0x03}},
+		{Header: rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 65535, Timestamp: 3000, SSRC: 1, Marker: true}, Payload: []byte{0x04}},
+		{Header: rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 0, Timestamp: 6000, SSRC: 1, CSRC: []uint32{7}}, Payload: []byte{0x05, 0x06, 0x07, 0x08, 0x09}},
+		{Header: rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 1, Timestamp: 6000, SSRC: 1}, Payload: []byte{0x0a, 0x0b}},
+	}
+	assert.NoError(t, packets[2].SetExtension(1, []byte{0xff}))
+
+	raw := [][]byte{}
+	for _, p := range packets {
+		b, err := p.Marshal()
+		assert.NoError(t, err)
+		raw = append(raw, b)
+	}
+
+	// next follows the protected packets, reading it passes all of them
+	next, err := (&rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 2, Timestamp: 9000, SSRC: 1}}).Marshal()
+	assert.NoError(t, err)
+
+	encoder := &encoderStream{ssrc: 1, fecSSRC: 2, groupSize: len(packets)}
+
+	var fecPayload []byte
+	for _, p := range packets {
+		_, payload, err := encoder.protect(&p.Header, p.Payload)
+		assert.NoError(t, err)
+		fecPayload = payload
+	}
+	assert.NotNil(t, fecPayload)
+
+	t.Run("Recover", func(t *testing.T) {
+		for lost := range packets {
+			d := NewDecoder(1)
+			for i := range raw {
+				if i != lost {
+					assert.Nil(t, pushRecovered(d, raw[i]))
+				}
+			}
+			assert.Nil(t, pushRecovered(d, next))
+
+			recovered, err := d.Recover(mimeTypeFlexFEC03, fecPayload)
+			assert.NoError(t, err)
+			assert.Equal(t, raw[lost], recovered)
+
+			// The recovered packet is kept, it isn't recovered again
+			recovered, err = d.Recover(mimeTypeFlexFEC03, fecPayload)
+			assert.NoError(t, err)
+			assert.Nil(t, recovered)
+		}
+	})
+
+	t.Run("Recover once read past", func(t *testing.T) {
+		d := NewDecoder(1)
+		for i := range raw[:len(raw)-1] {
+			d.Push(raw[i])
+		}
+
+		// The last packet may still be read, it isn't recovered yet
+		recovered, err := d.Recover(mimeTypeFlexFEC03, fecPayload)
+		assert.NoError(t, err)
+		assert.Nil(t, recovered)
+
+		assert.Equal(t, [][]byte{raw[len(raw)-1]}, pushRecovered(d, next))
+	})
+
+	t.Run("Late packets", func(t *testing.T) {
+		d := NewDecoder(1)
+		d.Push(raw[0])
+
+		recovered, err := d.Recover(mimeTypeFlexFEC03, fecPayload)
+		assert.NoError(t, err)
+		assert.Nil(t, recovered)
+
+		for i := range raw[1:] {
+			assert.Nil(t, pushRecovered(d, raw[i+1]))
+		}
+		assert.Nil(t, pushRecovered(d, next))
+		assert.Empty(t, d.pending)
+	})
+
+	t.Run("Too many lost packets", func(t *testing.T) {
+		d := NewDecoder(1)
+		d.Push(raw[0])
+		d.Push(raw[3])
+		d.Push(next)
+
+		recovered, err := d.Recover(mimeTypeFlexFEC03, fecPayload)
+		assert.NoError(t, err)
+		assert.Nil(t, recovered)
+	})
+
+	t.Run("Other stream", func(t *testing.T) {
+		d := NewDecoder(3)
+		for i := range raw[:len(raw)-1] {
+			d.Push(raw[i])
+		}
+		d.Push(next)
+
+		recovered, err := d.Recover(mimeTypeFlexFEC03, fecPayload)
+		assert.NoError(t, err)
+		assert.Nil(t, recovered)
+	})
+
+	t.Run("Unsupported mechanism", func(t *testing.T) {
+		for _, mimeType := range []string{"video/vp8", "video/ulpfec"} {
+			_, err := NewDecoder(1).Recover(mimeType, []byte{})
+			assert.Equal(t, errUnsupportedMechanism, err)
+		}
+	})
+}
+
+// pushRecovered pushes packet to d and returns the packets recovered
+func pushRecovered(d *Decoder, packet []byte) [][]byte {
+	_, recovered := d.Push(packet)
+	return recovered
+}
@@ -0,0 +1,388 @@
+package fec
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/internal/sequence"
+)
+
+const (
+	defaultGroupSize = 10
+
+	// sentHistorySize is the number of media packets of a stream protected with ULPFEC whose
+	// sequence numbers are remembered, to translate the NACKs and the retransmissions
+	sentHistorySize = 1024
+)
+
+// EncoderInterceptorFactory is a interceptor.Factory for a EncoderInterceptor
+type EncoderInterceptorFactory struct {
+	opts []EncoderOption
+}
+
+// NewInterceptor constructs a new EncoderInterceptor
+func (e *EncoderInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &EncoderInterceptor{
+		groupSize: defaultGroupSize,
+		log:       logging.NewDefaultLoggerFactory().NewLogger("fec_encoder"),
+		streams:   map[uint32]*encoderStream{},
+	}
+
+	for _, opt := range e.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// NewEncoderInterceptor returns a new EncoderInterceptorFactory
+func NewEncoderInterceptor(opts ...EncoderOption) (*EncoderInterceptorFactory, error) {
+	return &EncoderInterceptorFactory{opts}, nil
+}
+
+// EncoderInterceptor sends a FEC packet for each group of media packets of the local streams that negotiated FEC.
+// The packets are protected as they are written by the next Interceptors, so it should be the first Interceptor
+// added to the Registry, after which the packets aren't changed anymore.
+//
+// FlexFEC packets are sent on a stream of their own. ULPFEC packets are sent inside RED in the media stream, along
+// with the media packets, whose sequence numbers are shifted to make room for them. As the Interceptors added after
+// it only know the sequence numbers the media packets were written with, the NACKs they read are translated back
+// to them, and the retransmissions they write are translated to the sequence numbers the packets were sent with.
+type EncoderInterceptor struct {
+	interceptor.NoOp
+	groupSize int
+	log       logging.LeveledLogger
+
+	// streams are the streams protected with ULPFEC, by SSRC
+	streamsMu sync.Mutex
+	streams   map[uint32]*encoderStream
+}
+
+type encoderStream struct {
+	ssrc        uint32
+	payloadType uint8
+	groupSize   int
+
+	// fecSSRC is the SSRC of the FlexFEC stream. It is zero for ULPFEC, which is sent inside RED with
+	// redPayloadType. rtxSSRC is the SSRC of the RTX stream the retransmissions are sent on, if any
+	fecSSRC        uint32
+	redPayloadType uint8
+	rtxSSRC        uint32
+
+	mu             sync.Mutex
+	sequenceNumber uint16
+	group          *fecPacket
+
+	// With ULPFEC, the media packets are sent with their sequence number plus sequenceNumberOffset, the number
+	// of ULPFEC packets sent before them. sent and original map the sequence numbers of the last media packets
+	// written to the ones they were sent with, and back
+	lastSequenceNumber   uint16
+	sequenceNumberOffset uint16
+	sent                 [sentHistorySize]sequenceNumberPair
+	original             [sentHistorySize]sequenceNumberPair
+}
+
+// sequenceNumberPair holds the sequence number a media packet was written with, and the one it was sent with
+type sequenceNumberPair struct {
+	original uint16
+	sent     uint16
+	valid    bool
+}
+
+// BindRTCPReader lets you modify any incoming RTCP packets. It is called once per sender/receiver, however this might
+// change in the future. The returned method will be called once per packet batch.
+// The NACKs of the streams protected with ULPFEC are translated to the sequence numbers the media packets were written
+// with, NACKs only about ULPFEC packets are dropped.
+func (e *EncoderInterceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		for {
+			i, attr, err := reader.Read(b, a)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			pkts, err := rtcp.Unmarshal(b[:i])
+			if err != nil {
+				return i, attr, nil
+			}
+
+			translated, changed := e.translateNacks(pkts)
+			if !changed {
+				return i, attr, nil
+			} else if len(translated) == 0 {
+				continue
+			}
+
+			raw, err := rtcp.Marshal(translated)
+			if err != nil || len(raw) > len(b) {
+				return i, attr, nil
+			}
+			return copy(b, raw), attr, nil
+		}
+	})
+}
+
+// translateNacks returns pkts with the NACKs of the streams protected with ULPFEC translated. changed is false
+// if there is none
+func (e *EncoderInterceptor) translateNacks(pkts []rtcp.Packet) (translated []rtcp.Packet, changed bool) {
+	e.streamsMu.Lock()
+	defer e.streamsMu.Unlock()
+
+	translated = make([]rtcp.Packet, 0, len(pkts))
+	for _, pkt := range pkts {
+		nack, ok := pkt.(*rtcp.TransportLayerNack)
+		if !ok || e.streams[nack.MediaSSRC] == nil {
+			translated = append(translated, pkt)
+			continue
+		}
+
+		changed = true
+		if nacks := e.streams[nack.MediaSSRC].originalNacks(nack.Nacks); len(nacks) != 0 {
+			translated = append(translated, &rtcp.TransportLayerNack{SenderSSRC: nack.SenderSSRC, MediaSSRC: nack.MediaSSRC, Nacks: nacks})
+		}
+	}
+
+	return translated, changed
+}
+
+// BindLocalStream lets you modify any outgoing RTP packets. It is called once for per LocalStream. The returned method
+// will be called once per rtp packet.
+func (e *EncoderInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	switch {
+	case isFlexFEC03(info.MimeTypeForwardErrorCorrection) && info.SSRCForwardErrorCorrection != 0:
+		return e.bindFlexFEC(info, writer)
+	case isULPFEC(info.MimeTypeForwardErrorCorrection) && info.PayloadTypeRED != 0:
+		return e.bindULPFEC(info, writer)
+	default:
+		return writer
+	}
+}
+
+// UnbindLocalStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (e *EncoderInterceptor) UnbindLocalStream(info *interceptor.StreamInfo) {
+	e.streamsMu.Lock()
+	delete(e.streams, info.SSRC)
+	e.streamsMu.Unlock()
+}
+
+func (e *EncoderInterceptor) bindFlexFEC(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	stream := &encoderStream{
+		ssrc:           info.SSRC,
+		fecSSRC:        info.SSRCForwardErrorCorrection,
+		payloadType:    info.PayloadTypeForwardErrorCorrection,
+		groupSize:      e.groupSize,
+		sequenceNumber: uint16(rand.Uint32()), // #nosec
+	}
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		n, err := writer.Write(header, payload, attributes)
+		if err != nil || header.SSRC != stream.ssrc {
+			return n, err
+		}
+
+		fecHeader, fecPayload, err := stream.protect(header, payload)
+		if err != nil {
+			e.log.Warnf("failed protecting packet: %+v", err)
+		} else if fecHeader != nil {
+			if _, err := writer.Write(fecHeader, fecPayload, interceptor.Attributes{}); err != nil {
+				e.log.Warnf("failed sending FEC packet: %+v", err)
+			}
+		}
+
+		return n, nil
+	})
+}
+
+func (e *EncoderInterceptor) bindULPFEC(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	stream := &encoderStream{
+		ssrc:           info.SSRC,
+		payloadType:    info.PayloadTypeForwardErrorCorrection,
+		groupSize:      e.groupSize,
+		redPayloadType: info.PayloadTypeRED,
+		rtxSSRC:        info.SSRCRetransmission,
+	}
+
+	e.streamsMu.Lock()
+	e.streams[info.SSRC] = stream
+	e.streamsMu.Unlock()
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		switch {
+		case header.SSRC == stream.ssrc:
+			return stream.writeULPFEC(header, payload, attributes, writer, e.log)
+		case header.SSRC == stream.rtxSSRC && stream.rtxSSRC != 0:
+			return stream.writeRetransmission(header, payload, attributes, writer)
+		default:
+			return writer.Write(header, payload, attributes)
+		}
+	})
+}
+
+// addToGroup adds a marshaled media packet to the current group. It returns the group once it is complete.
+// s.mu must be held
+func (s *encoderStream) addToGroup(sequenceNumber uint16, raw []byte) *fecPacket {
+	// The group can't span a gap in the sequence numbers larger than a mask, it is dropped unprotected
+	if s.group != nil && sequenceNumber-s.group.sequenceNumberBase >= maxGroupSize {
+		s.group = nil
+	}
+
+	if s.group == nil {
+		s.group = &fecPacket{ssrc: s.ssrc, sequenceNumberBase: sequenceNumber}
+	}
+	s.group.offsets = append(s.group.offsets, sequenceNumber-s.group.sequenceNumberBase)
+	s.group.add(raw)
+
+	if len(s.group.offsets) < s.groupSize {
+		return nil
+	}
+
+	group := s.group
+	s.group = nil
+	return group
+}
+
+// protect adds a media packet to the current group. It returns the FlexFEC packet protecting the group, once it is complete
+func (s *encoderStream) protect(header *rtp.Header, payload []byte) (*rtp.Header, []byte, error) {
+	raw, err := (&rtp.Packet{Header: *header, Payload: payload}).Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group := s.addToGroup(header.SequenceNumber, raw)
+	if group == nil {
+		return nil, nil, nil
+	}
+
+	fecHeader := &rtp.Header{
+		Version:        2,
+		PayloadType:    s.payloadType,
+		SequenceNumber: s.sequenceNumber,
+		Timestamp:      header.Timestamp,
+		SSRC:           s.fecSSRC,
+	}
+	s.sequenceNumber++
+
+	return fecHeader, group.marshalFlexFEC03(), nil
+}
+
+// writeULPFEC sends a media packet inside RED, followed by the ULPFEC packet protecting its group once it is complete.
+// A retransmission of a media packet on the media stream is sent as it was written, with the sequence number the
+// packet was sent with
+func (s *encoderStream) writeULPFEC(header *rtp.Header, payload []byte, attributes interceptor.Attributes, writer interceptor.RTPWriter, log logging.LeveledLogger) (int, error) {
+	s.mu.Lock()
+	if sent, ok := s.sentSequenceNumber(header.SequenceNumber); ok && int16(header.SequenceNumber-s.lastSequenceNumber) <= 0 {
+		s.mu.Unlock()
+
+		retransmission := *header
+		retransmission.SequenceNumber = sent
+		return writer.Write(&retransmission, payload, attributes)
+	}
+
+	// The padding of the media packet isn't carried by RED, it isn't protected either
+	media := *header
+	media.Padding = false
+	media.SequenceNumber = header.SequenceNumber + s.sequenceNumberOffset
+	payload = unpadded(header, payload)
+
+	s.lastSequenceNumber = header.SequenceNumber
+	s.record(header.SequenceNumber, media.SequenceNumber)
+
+	var fecHeader *rtp.Header
+	var fecPayload []byte
+	raw, err := (&rtp.Packet{Header: media, Payload: payload}).Marshal()
+	if err != nil {
+		log.Warnf("failed protecting packet: %+v", err)
+	} else if group := s.addToGroup(media.SequenceNumber, raw); group != nil {
+		fecHeader = &rtp.Header{
+			Version:        2,
+			PayloadType:    s.redPayloadType,
+			SequenceNumber: media.SequenceNumber + 1,
+			Timestamp:      media.Timestamp,
+			SSRC:           s.ssrc,
+		}
+		fecPayload = group.marshalULPFEC()
+
+		s.sequenceNumberOffset++
+		s.original[fecHeader.SequenceNumber%sentHistorySize] = sequenceNumberPair{}
+	}
+	s.mu.Unlock()
+
+	redPayload, err := wrapRED(media.PayloadType, payload)
+	if err != nil {
+		return 0, err
+	}
+	media.PayloadType = s.redPayloadType
+
+	n, err := writer.Write(&media, redPayload, attributes)
+	if err != nil || fecHeader == nil {
+		return n, err
+	}
+
+	if fecPayload, err = wrapRED(s.payloadType, fecPayload); err != nil {
+		log.Warnf("failed protecting packet: %+v", err)
+	} else if _, err = writer.Write(fecHeader, fecPayload, interceptor.Attributes{}); err != nil {
+		log.Warnf("failed sending FEC packet: %+v", err)
+	}
+
+	return n, nil
+}
+
+// writeRetransmission sends a packet of the RTX stream with the sequence number the original packet was sent with.
+// Retransmissions of packets that aren't remembered anymore are dropped
+func (s *encoderStream) writeRetransmission(header *rtp.Header, payload []byte, attributes interceptor.Attributes, writer interceptor.RTPWriter) (int, error) {
+	if len(payload) < 2 {
+		return writer.Write(header, payload, attributes)
+	}
+
+	s.mu.Lock()
+	sent, ok := s.sentSequenceNumber(binary.BigEndian.Uint16(payload))
+	s.mu.Unlock()
+	if !ok {
+		return 0, nil
+	}
+
+	translated := append([]byte{}, payload...)
+	binary.BigEndian.PutUint16(translated, sent)
+	return writer.Write(header, translated, attributes)
+}
+
+// record remembers the sequence number a media packet was sent with. s.mu must be held
+func (s *encoderStream) record(original, sent uint16) {
+	pair := sequenceNumberPair{original: original, sent: sent, valid: true}
+	s.sent[original%sentHistorySize] = pair
+	s.original[sent%sentHistorySize] = pair
+}
+
+// sentSequenceNumber returns the sequence number the media packet written with original was sent with. s.mu must be held
+func (s *encoderStream) sentSequenceNumber(original uint16) (uint16, bool) {
+	pair := s.sent[original%sentHistorySize]
+	return pair.sent, pair.valid && pair.original == original
+}
+
+// originalNacks returns nacks, about the sequence numbers the packets were sent with,
+// about the ones the media packets were written with instead
+func (s *encoderStream) originalNacks(nacks []rtcp.NackPair) []rtcp.NackPair {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original := []uint16{}
+	for _, pair := range nacks {
+		for _, sent := range pair.PacketList() {
+			if p := s.original[sent%sentHistorySize]; p.valid && p.sent == sent {
+				original = append(original, p.original)
+			}
+		}
+	}
+
+	return sequence.NackPairs(original)
+}
@@ -0,0 +1,193 @@
+package fec
+
+import (
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderInterceptor(t *testing.T) {
+	f, err := NewEncoderInterceptor(EncoderProtectionRate(1.0 / 3))
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	written := []rtp.Header{}
+	writer := i.BindLocalStream(&interceptor.StreamInfo{
+		SSRC:                              1,
+		SSRCForwardErrorCorrection:        2,
+		PayloadTypeForwardErrorCorrection: 117,
+		MimeTypeForwardErrorCorrection:    "video/flexfec-03",
+	}, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		written = append(written, *header)
+		return len(payload), nil
+	}))
+
+	for sequenceNumber := uint16(10); sequenceNumber < 16; sequenceNumber++ {
+		n, writeErr := writer.Write(&rtp.Header{SequenceNumber: sequenceNumber, SSRC: 1}, []byte{0x0}, nil)
+		assert.NoError(t, writeErr)
+		assert.Equal(t, 1, n)
+
+		// Packets of other streams, like retransmissions, aren't protected
+		_, writeErr = writer.Write(&rtp.Header{SequenceNumber: sequenceNumber, SSRC: 3}, []byte{0x0}, nil)
+		assert.NoError(t, writeErr)
+	}
+
+	assert.Equal(t, 14, len(written))
+	for _, fecIndex := range []int{5, 12} {
+		assert.Equal(t, uint32(2), written[fecIndex].SSRC)
+		assert.Equal(t, uint8(117), written[fecIndex].PayloadType)
+	}
+	assert.Equal(t, written[5].SequenceNumber+1, written[12].SequenceNumber)
+
+	t.Run("Without FEC", func(t *testing.T) {
+		count := 0
+		writer := i.BindLocalStream(&interceptor.StreamInfo{SSRC: 1}, interceptor.RTPWriterFunc(func(_ *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+			count++
+			return len(payload), nil
+		}))
+
+		for sequenceNumber := uint16(0); sequenceNumber < 10; sequenceNumber++ {
+			_, writeErr := writer.Write(&rtp.Header{SequenceNumber: sequenceNumber, SSRC: 1}, []byte{0x0}, nil)
+			assert.NoError(t, writeErr)
+		}
+		assert.Equal(t, 10, count)
+	})
+
+	assert.NoError(t, i.Close())
+}
+
+func TestEncoderInterceptor_ULPFEC(t *testing.T) {
+	f, err := NewEncoderInterceptor(EncoderProtectionRate(1.0 / 3))
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	written := [][]byte{}
+	writer := i.BindLocalStream(&interceptor.StreamInfo{
+		SSRC:                              1,
+		SSRCRetransmission:                2,
+		PayloadTypeForwardErrorCorrection: 116,
+		MimeTypeForwardErrorCorrection:    "video/ulpfec",
+		PayloadTypeRED:                    114,
+	}, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		raw, marshalErr := (&rtp.Packet{Header: *header, Payload: payload}).Marshal()
+		assert.NoError(t, marshalErr)
+		written = append(written, raw)
+		return len(payload), nil
+	}))
+
+	media := map[uint16][]byte{}
+	for sequenceNumber := uint16(10); sequenceNumber < 16; sequenceNumber++ {
+		_, writeErr := writer.Write(&rtp.Header{Version: 2, SequenceNumber: sequenceNumber, Timestamp: 3000, PayloadType: 96, SSRC: 1}, []byte{byte(sequenceNumber)}, nil)
+		assert.NoError(t, writeErr)
+	}
+
+	// The media packets are sent inside RED, followed by a ULPFEC packet after each group
+	d := NewULPFECDecoder(1, 114, 116)
+	assert.Equal(t, 8, len(written))
+	for j, raw := range written {
+		header := &rtp.Header{}
+		assert.NoError(t, header.Unmarshal(raw))
+		assert.Equal(t, uint16(10+j), header.SequenceNumber)
+		assert.Equal(t, uint8(114), header.PayloadType)
+		assert.Equal(t, j == 3 || j == 7, d.IsFEC(raw))
+
+		if !d.IsFEC(raw) {
+			read, _ := d.Push(raw)
+			media[header.SequenceNumber] = read
+		}
+	}
+
+	// The packets read from RED are the ones written
+	expected, err := (&rtp.Packet{Header: rtp.Header{Version: 2, SequenceNumber: 11, Timestamp: 3000, PayloadType: 96, SSRC: 1}, Payload: []byte{0x0b}}).Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, media[11])
+
+	t.Run("Recover", func(t *testing.T) {
+		d := NewULPFECDecoder(1, 114, 116)
+		for j, raw := range written[:4] {
+			if j == 1 {
+				continue
+			}
+
+			read, recovered := d.Push(raw)
+			if j == 3 {
+				assert.Equal(t, raw, read)
+				assert.Equal(t, [][]byte{media[11]}, recovered)
+			} else {
+				assert.Equal(t, media[uint16(10+j)], read)
+				assert.Empty(t, recovered)
+			}
+		}
+	})
+
+	t.Run("Retransmissions", func(t *testing.T) {
+		written = written[:0]
+
+		// 13 was sent as 14, after the first ULPFEC packet
+		_, writeErr := writer.Write(&rtp.Header{Version: 2, SequenceNumber: 13, PayloadType: 96, SSRC: 1}, []byte{0x0d}, nil)
+		assert.NoError(t, writeErr)
+		_, writeErr = writer.Write(&rtp.Header{Version: 2, SequenceNumber: 7, PayloadType: 97, SSRC: 2}, []byte{0x00, 0x0d, 0x0d}, nil)
+		assert.NoError(t, writeErr)
+
+		assert.Equal(t, 2, len(written))
+		p := &rtp.Packet{}
+		assert.NoError(t, p.Unmarshal(written[0]))
+		assert.Equal(t, uint16(14), p.SequenceNumber)
+		assert.Equal(t, uint8(96), p.PayloadType)
+		assert.NoError(t, p.Unmarshal(written[1]))
+		assert.Equal(t, []byte{0x00, 0x0e, 0x0d}, p.Payload)
+	})
+
+	t.Run("NACKs", func(t *testing.T) {
+		queue := [][]rtcp.Packet{
+			// Only about the ULPFEC packet following 12, it is dropped
+			{&rtcp.TransportLayerNack{MediaSSRC: 1, Nacks: []rtcp.NackPair{{PacketID: 13}}}},
+			{
+				&rtcp.TransportLayerNack{MediaSSRC: 1, Nacks: []rtcp.NackPair{{PacketID: 12, LostPackets: 0x3}}},
+				&rtcp.TransportLayerNack{MediaSSRC: 3, Nacks: []rtcp.NackPair{{PacketID: 14}}},
+			},
+		}
+		reader := i.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			raw, marshalErr := rtcp.Marshal(queue[0])
+			assert.NoError(t, marshalErr)
+			queue = queue[1:]
+			return copy(b, raw), a, nil
+		}))
+
+		b := make([]byte, 1500)
+		n, _, readErr := reader.Read(b, nil)
+		assert.NoError(t, readErr)
+
+		pkts, unmarshalErr := rtcp.Unmarshal(b[:n])
+		assert.NoError(t, unmarshalErr)
+		assert.Equal(t, []rtcp.Packet{
+			&rtcp.TransportLayerNack{MediaSSRC: 1, Nacks: []rtcp.NackPair{{PacketID: 12, LostPackets: 0x1}}},
+			&rtcp.TransportLayerNack{MediaSSRC: 3, Nacks: []rtcp.NackPair{{PacketID: 14}}},
+		}, pkts)
+	})
+
+	assert.NoError(t, i.Close())
+}
+
+func TestEncoderProtectionRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, 1.5} {
+		f, err := NewEncoderInterceptor(EncoderProtectionRate(rate))
+		assert.NoError(t, err)
+
+		_, err = f.NewInterceptor("")
+		assert.Equal(t, errInvalidProtectionRate, err)
+	}
+
+	for rate, groupSize := range map[float64]int{1: 1, 0.1: 10, 0.3: 3, 0.001: maxGroupSize} {
+		e := &EncoderInterceptor{}
+		assert.NoError(t, EncoderProtectionRate(rate)(e))
+		assert.Equal(t, groupSize, e.groupSize)
+	}
+}
@@ -0,0 +1,35 @@
+package fec
+
+import (
+	"math"
+
+	"github.com/pion/logging"
+)
+
+// EncoderOption can be used to configure EncoderInterceptor
+type EncoderOption func(e *EncoderInterceptor) error
+
+// EncoderProtectionRate sets the number of FEC packets sent for each media packet, between 0 and 1.
+// A rate of 0.1 sends a FEC packet for every 10 media packets. As a FEC packet protects at most
+// 48 media packets, lower rates are raised to 1/48
+func EncoderProtectionRate(rate float64) EncoderOption {
+	return func(e *EncoderInterceptor) error {
+		if rate <= 0 || rate > 1 {
+			return errInvalidProtectionRate
+		}
+
+		e.groupSize = int(math.Round(1 / rate))
+		if e.groupSize > maxGroupSize {
+			e.groupSize = maxGroupSize
+		}
+		return nil
+	}
+}
+
+// EncoderLog sets a logger for the interceptor
+func EncoderLog(log logging.LeveledLogger) EncoderOption {
+	return func(e *EncoderInterceptor) error {
+		e.log = log
+		return nil
+	}
+}
@@ -0,0 +1,12 @@
+package fec
+
+import "errors"
+
+var (
+	errShortPacket              = errors.New("packet is not large enough")
+	errUnsupportedMechanism     = errors.New("unsupported FEC mechanism")
+	errUnsupportedFlexFECPacket = errors.New("FlexFEC retransmissions, fixed masks and packets protecting several streams are not supported")
+	errInvalidFlexFECMask       = errors.New("FlexFEC mask has no last part")
+	errUnsupportedULPFECPacket  = errors.New("ULPFEC header extensions are not supported")
+	errInvalidProtectionRate    = errors.New("protection rate must be larger than 0 and at most 1")
+)
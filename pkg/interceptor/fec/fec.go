@@ -0,0 +1,99 @@
+// Package fec provides an interceptor generating forward error correction (FEC) packets for local streams,
+// and a Decoder recovering the lost packets of remote streams from them.
+// FlexFEC (draft-ietf-payload-flexible-fec-scheme-03) packets are sent as a separate stream, with its own SSRC,
+// that protects a single media stream. ULPFEC (RFC 5109) packets are sent the way browsers do: inside RED (RFC 2198),
+// in the media stream itself, along with the media packets which are sent inside RED too.
+package fec
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+const (
+	mimeTypeFlexFEC03 = "video/flexfec-03"
+	mimeTypeULPFEC    = "video/ulpfec"
+
+	rtpHeaderSize = 12
+
+	// maxGroupSize is the largest number of media packets protected by a FEC packet.
+	// FlexFEC masks can be longer, larger groups would delay the recovery too much
+	maxGroupSize = 48
+)
+
+func isFlexFEC03(mimeType string) bool {
+	return strings.EqualFold(mimeType, mimeTypeFlexFEC03)
+}
+
+func isULPFEC(mimeType string) bool {
+	return strings.EqualFold(mimeType, mimeTypeULPFEC)
+}
+
+// recovery is the XOR of the fields of the protected packets. XORing it with all
+// protected packets except one gives back the fields of the missing packet
+type recovery struct {
+	// header holds the P, X, CC, M and PT fields, at their place in the first two bytes of the RTP header
+	header    [2]byte
+	timestamp uint32
+
+	// length and payload cover everything after the fixed RTP header: the CSRCs,
+	// the header extension, the payload and the padding
+	length  uint16
+	payload []byte
+}
+
+// add XORs a marshaled RTP packet into the recovery
+func (r *recovery) add(packet []byte) {
+	r.header[0] ^= packet[0] & 0x3f
+	r.header[1] ^= packet[1]
+	r.timestamp ^= binary.BigEndian.Uint32(packet[4:8])
+	r.length ^= uint16(len(packet) - rtpHeaderSize)
+
+	body := packet[rtpHeaderSize:]
+	for len(r.payload) < len(body) {
+		r.payload = append(r.payload, 0)
+	}
+	for i := range body {
+		r.payload[i] ^= body[i]
+	}
+}
+
+// packet returns the marshaled RTP packet the recovery stands for, once all other protected packets were added
+func (r *recovery) packet(ssrc uint32, sequenceNumber uint16) ([]byte, bool) {
+	if int(r.length) > len(r.payload) {
+		return nil, false
+	}
+
+	packet := make([]byte, rtpHeaderSize+int(r.length))
+	packet[0] = 0x80 | r.header[0]
+	packet[1] = r.header[1]
+	binary.BigEndian.PutUint16(packet[2:4], sequenceNumber)
+	binary.BigEndian.PutUint32(packet[4:8], r.timestamp)
+	binary.BigEndian.PutUint32(packet[8:12], ssrc)
+	copy(packet[rtpHeaderSize:], r.payload)
+
+	return packet, true
+}
+
+// fecPacket is the content of a FEC packet, independent of its format
+type fecPacket struct {
+	recovery
+
+	// ssrc is the SSRC of the protected stream
+	ssrc uint32
+
+	// sequenceNumberBase is the sequence number of the first protected packet,
+	// offsets are the offsets of all protected packets from it
+	sequenceNumberBase uint16
+	offsets            []uint16
+}
+
+// sequenceNumbers returns the sequence numbers of the protected packets
+func (f *fecPacket) sequenceNumbers() []uint16 {
+	sequenceNumbers := make([]uint16, 0, len(f.offsets))
+	for _, offset := range f.offsets {
+		sequenceNumbers = append(sequenceNumbers, f.sequenceNumberBase+offset)
+	}
+
+	return sequenceNumbers
+}
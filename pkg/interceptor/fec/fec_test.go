@@ -0,0 +1,110 @@
+package fec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFECPacket_FlexFEC03(t *testing.T) {
+	for _, test := range []struct {
+		Name    string
+		Offsets []uint16
+		Size    int
+	}{
+		{"One part mask", []uint16{0, 1, 14}, 20},
+		{"Two part mask", []uint16{0, 15, 45}, 24},
+		{"Three part mask", []uint16{0, 14, 46, 108}, 32},
+	} {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			f := &fecPacket{
+				recovery: recovery{
+					header:    [2]byte{0x21, 0xe0},
+					timestamp: 0x01020304,
+					length:    3,
+					payload:   []byte{0x05, 0x06, 0x07},
+				},
+				ssrc:               0x0a0b0c0d,
+				sequenceNumberBase: 65530,
+				offsets:            test.Offsets,
+			}
+
+			payload := f.marshalFlexFEC03()
+			assert.Equal(t, test.Size+len(f.payload), len(payload))
+
+			parsed, err := unmarshalFlexFEC03(payload)
+			assert.NoError(t, err)
+			assert.Equal(t, f, parsed)
+			assert.Equal(t, uint16(65530+test.Offsets[1]), parsed.sequenceNumbers()[1])
+		})
+	}
+
+	t.Run("Short packet", func(t *testing.T) {
+		_, err := unmarshalFlexFEC03(make([]byte, 19))
+		assert.Equal(t, errShortPacket, err)
+	})
+
+	t.Run("FlexFEC without last mask", func(t *testing.T) {
+		payload := make([]byte, 32)
+		payload[8] = flexfecSSRCCount
+		_, err := unmarshalFlexFEC03(payload)
+		assert.Equal(t, errInvalidFlexFECMask, err)
+	})
+
+	t.Run("FlexFEC retransmission", func(t *testing.T) {
+		payload := make([]byte, 20)
+		payload[0] = flexfecRetransmitFlag
+		_, err := unmarshalFlexFEC03(payload)
+		assert.Equal(t, errUnsupportedFlexFECPacket, err)
+	})
+}
+
+func TestFECPacket_ULPFEC(t *testing.T) {
+	for _, test := range []struct {
+		Name    string
+		Offsets []uint16
+		Size    int
+	}{
+		{"Short mask", []uint16{0, 1, 15}, 14},
+		{"Long mask", []uint16{0, 16, 47}, 18},
+	} {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			f := &fecPacket{
+				recovery: recovery{
+					header:    [2]byte{0x21, 0xe0},
+					timestamp: 0x01020304,
+					length:    3,
+					payload:   []byte{0x05, 0x06, 0x07},
+				},
+				sequenceNumberBase: 65530,
+				offsets:            test.Offsets,
+			}
+
+			payload := f.marshalULPFEC()
+			assert.Equal(t, test.Size+len(f.payload), len(payload))
+
+			parsed, err := unmarshalULPFEC(payload)
+			assert.NoError(t, err)
+			assert.Equal(t, f, parsed)
+		})
+	}
+
+	t.Run("Short packet", func(t *testing.T) {
+		_, err := unmarshalULPFEC(make([]byte, 13))
+		assert.Equal(t, errShortPacket, err)
+
+		payload := make([]byte, 16)
+		payload[0] = ulpfecLongMaskFlag
+		_, err = unmarshalULPFEC(payload)
+		assert.Equal(t, errShortPacket, err)
+	})
+
+	t.Run("Header extension", func(t *testing.T) {
+		payload := make([]byte, 14)
+		payload[0] = ulpfecExtensionFlag
+		_, err := unmarshalULPFEC(payload)
+		assert.Equal(t, errUnsupportedULPFECPacket, err)
+	})
+}
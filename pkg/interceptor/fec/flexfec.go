@@ -0,0 +1,121 @@
+package fec
+
+import "encoding/binary"
+
+const (
+	flexfecHeaderSize      = 18
+	flexfecRetransmitFlag  = 0x80
+	flexfecFixedMaskFlag   = 0x40
+	flexfecLastMaskBit     = 0x80
+	flexfecMaxMaskBitsSize = 112
+	flexfecSSRCCount       = 1
+)
+
+// flexfecMaskChunks are the sizes, in bits and including the k bit, of the parts of a FlexFEC mask
+var flexfecMaskChunks = []int{16, 32, 64}
+
+// flexfecMaskBitPosition returns the position of the bit of an offset in a FlexFEC mask,
+// skipping the k bits that start each part of the mask. The second return value is
+// the number of bits of the mask needed to hold it
+func flexfecMaskBitPosition(offset int) (int, int) {
+	start := 0
+	for _, chunk := range flexfecMaskChunks {
+		if offset < chunk-1 {
+			return start + 1 + offset, start + chunk
+		}
+		offset -= chunk - 1
+		start += chunk
+	}
+
+	return -1, -1
+}
+
+// marshalFlexFEC03 returns the payload of a FlexFEC packet with a flexible mask, protecting a single stream
+// https://tools.ietf.org/html/draft-ietf-payload-flexible-fec-scheme-03#section-4.2
+func (f *fecPacket) marshalFlexFEC03() []byte {
+	mask := make([]byte, flexfecMaxMaskBitsSize/8)
+	maskSize := flexfecMaskChunks[0]
+	for _, offset := range f.offsets {
+		position, size := flexfecMaskBitPosition(int(offset))
+		if position == -1 {
+			continue
+		}
+
+		mask[position/8] |= 0x80 >> uint(position%8)
+		if size > maskSize {
+			maskSize = size
+		}
+	}
+
+	// The k bit is set on the last part of the mask
+	for start, i := 0, 0; start < maskSize; start, i = start+flexfecMaskChunks[i], i+1 {
+		if start+flexfecMaskChunks[i] == maskSize {
+			mask[start/8] |= flexfecLastMaskBit
+		}
+	}
+
+	payload := make([]byte, flexfecHeaderSize+maskSize/8+len(f.payload))
+	payload[0] = f.header[0]
+	payload[1] = f.header[1]
+	binary.BigEndian.PutUint16(payload[2:4], f.length)
+	binary.BigEndian.PutUint32(payload[4:8], f.timestamp)
+	payload[8] = flexfecSSRCCount
+	binary.BigEndian.PutUint32(payload[12:16], f.ssrc)
+	binary.BigEndian.PutUint16(payload[16:18], f.sequenceNumberBase)
+	copy(payload[flexfecHeaderSize:], mask[:maskSize/8])
+	copy(payload[flexfecHeaderSize+maskSize/8:], f.payload)
+
+	return payload
+}
+
+// unmarshalFlexFEC03 parses the payload of a FlexFEC packet. Retransmissions, fixed
+// masks and packets protecting more than one stream aren't supported
+func unmarshalFlexFEC03(payload []byte) (*fecPacket, error) {
+	if len(payload) < flexfecHeaderSize+flexfecMaskChunks[0]/8 {
+		return nil, errShortPacket
+	}
+
+	if payload[0]&(flexfecRetransmitFlag|flexfecFixedMaskFlag) != 0 || payload[8] != flexfecSSRCCount {
+		return nil, errUnsupportedFlexFECPacket
+	}
+
+	// Each part of the mask starts with the k bit, which is set on the last one
+	mask := payload[flexfecHeaderSize:]
+	maskSize := 0
+	for _, chunk := range flexfecMaskChunks {
+		if len(mask) < (maskSize+chunk)/8 {
+			return nil, errShortPacket
+		}
+
+		last := mask[maskSize/8]&flexfecLastMaskBit != 0
+		maskSize += chunk
+		if last {
+			break
+		} else if maskSize == flexfecMaxMaskBitsSize {
+			return nil, errInvalidFlexFECMask
+		}
+	}
+
+	offsets := []uint16{}
+	for offset := 0; ; offset++ {
+		position, size := flexfecMaskBitPosition(offset)
+		if position == -1 || size > maskSize {
+			break
+		}
+		if mask[position/8]&(0x80>>uint(position%8)) != 0 {
+			offsets = append(offsets, uint16(offset))
+		}
+	}
+
+	return &fecPacket{
+		recovery: recovery{
+			header:    [2]byte{payload[0] & 0x3f, payload[1]},
+			timestamp: binary.BigEndian.Uint32(payload[4:8]),
+			length:    binary.BigEndian.Uint16(payload[2:4]),
+			payload:   append([]byte{}, mask[maskSize/8:]...),
+		},
+		ssrc:               binary.BigEndian.Uint32(payload[12:16]),
+		sequenceNumberBase: binary.BigEndian.Uint16(payload[16:18]),
+		offsets:            offsets,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package fec
+
+import (
+	"encoding/binary"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/codecs/red"
+)
+
+const (
+	ulpfecHeaderSize      = 10
+	ulpfecExtensionFlag   = 0x80
+	ulpfecLongMaskFlag    = 0x40
+	ulpfecShortMaskSize   = 2
+	ulpfecLongMaskSize    = 6
+	ulpfecProtectionSize  = 2
+	ulpfecShortMaskOffset = 16
+)
+
+// marshalULPFEC returns the payload of a ULPFEC packet with a single protection level, covering
+// the whole protected packets. The long mask is only used if the short one can't hold the offsets
+// https://tools.ietf.org/html/rfc5109#section-7.3
+func (f *fecPacket) marshalULPFEC() []byte {
+	maskSize := ulpfecShortMaskSize
+	for _, offset := range f.offsets {
+		if offset >= ulpfecShortMaskOffset {
+			maskSize = ulpfecLongMaskSize
+		}
+	}
+
+	levelHeaderSize := ulpfecProtectionSize + maskSize
+	payload := make([]byte, ulpfecHeaderSize+levelHeaderSize+len(f.payload))
+	payload[0] = f.header[0]
+	if maskSize == ulpfecLongMaskSize {
+		payload[0] |= ulpfecLongMaskFlag
+	}
+	payload[1] = f.header[1]
+	binary.BigEndian.PutUint16(payload[2:4], f.sequenceNumberBase)
+	binary.BigEndian.PutUint32(payload[4:8], f.timestamp)
+	binary.BigEndian.PutUint16(payload[8:10], f.length)
+	binary.BigEndian.PutUint16(payload[10:12], uint16(len(f.payload)))
+
+	mask := payload[ulpfecHeaderSize+ulpfecProtectionSize : ulpfecHeaderSize+levelHeaderSize]
+	for _, offset := range f.offsets {
+		mask[offset/8] |= 0x80 >> (offset % 8)
+	}
+	copy(payload[ulpfecHeaderSize+levelHeaderSize:], f.payload)
+
+	return payload
+}
+
+// unmarshalULPFEC parses the payload of a ULPFEC packet. Only the first protection level is used,
+// the packets it doesn't fully cover aren't recovered. ULPFEC packets don't carry the SSRC of
+// the stream they protect, it is left to the caller
+func unmarshalULPFEC(payload []byte) (*fecPacket, error) {
+	if len(payload) < ulpfecHeaderSize+ulpfecProtectionSize+ulpfecShortMaskSize {
+		return nil, errShortPacket
+	}
+
+	if payload[0]&ulpfecExtensionFlag != 0 {
+		return nil, errUnsupportedULPFECPacket
+	}
+
+	maskSize := ulpfecShortMaskSize
+	if payload[0]&ulpfecLongMaskFlag != 0 {
+		maskSize = ulpfecLongMaskSize
+	}
+
+	levelHeaderSize := ulpfecProtectionSize + maskSize
+	if len(payload) < ulpfecHeaderSize+levelHeaderSize {
+		return nil, errShortPacket
+	}
+
+	protectionLength := int(binary.BigEndian.Uint16(payload[10:12]))
+	body := payload[ulpfecHeaderSize+levelHeaderSize:]
+	if len(body) < protectionLength {
+		return nil, errShortPacket
+	}
+
+	mask := payload[ulpfecHeaderSize+ulpfecProtectionSize : ulpfecHeaderSize+levelHeaderSize]
+	offsets := []uint16{}
+	for offset := uint16(0); offset < uint16(maskSize*8); offset++ {
+		if mask[offset/8]&(0x80>>(offset%8)) != 0 {
+			offsets = append(offsets, offset)
+		}
+	}
+
+	return &fecPacket{
+		recovery: recovery{
+			header:    [2]byte{payload[0] & 0x3f, payload[1]},
+			timestamp: binary.BigEndian.Uint32(payload[4:8]),
+			length:    binary.BigEndian.Uint16(payload[8:10]),
+			payload:   append([]byte{}, body[:protectionLength]...),
+		},
+		sequenceNumberBase: binary.BigEndian.Uint16(payload[2:4]),
+		offsets:            offsets,
+	}, nil
+}
+
+// wrapRED returns the payload of a RED packet carrying payload, with the payload type payloadType, as its only block
+func wrapRED(payloadType uint8, payload []byte) ([]byte, error) {
+	return red.Marshal([]red.Block{{PayloadType: payloadType, Payload: payload}})
+}
+
+// unwrapRED returns the header and the payload of the packet carried by the last block of a RED packet
+func unwrapRED(packet []byte) (*rtp.Header, []byte, error) {
+	p := &rtp.Packet{}
+	if err := p.Unmarshal(packet); err != nil {
+		return nil, nil, err
+	}
+
+	blocks, err := red.Unmarshal(unpadded(&p.Header, p.Payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block := blocks[len(blocks)-1]
+	header := p.Header
+	header.Padding = false
+	header.PayloadType = block.PayloadType
+
+	return &header, block.Payload, nil
+}
+
+// unpadded returns payload without the padding header announces
+func unpadded(header *rtp.Header, payload []byte) []byte {
+	if header.Padding && len(payload) != 0 && int(payload[len(payload)-1]) <= len(payload) {
+		return payload[:len(payload)-int(payload[len(payload)-1])]
+	}
+
+	return payload
+}
@@ -0,0 +1,326 @@
+package gcc
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// packets sent within burstInterval are grouped, as they were likely sent as a burst
+	burstInterval = int64(5 * time.Millisecond / time.Microsecond)
+
+	trendlineWindowSize    = 20
+	trendlineSmoothing     = 0.9
+	trendlineThresholdGain = 4.0
+	maxNumDeltas           = 60
+
+	overuseTimeThreshold  = 10.0 // ms
+	initialThreshold      = 12.5
+	minThreshold          = 6.0
+	maxThreshold          = 600.0
+	thresholdGainUp       = 0.0087
+	thresholdGainDown     = 0.039
+	maxThresholdTimeDelta = 100.0 // ms
+	maxThresholdDeviation = 15.0
+
+	increaseFactorPerSecond = 1.08
+	decreaseFactor          = 0.85
+
+	receivedRateWindow = int64(time.Second / time.Microsecond)
+	minRateWindow      = int64(100 * time.Millisecond / time.Microsecond)
+)
+
+type usage int
+
+const (
+	usageNormal usage = iota
+	usageOver
+	usageUnder
+)
+
+type rateControlState int
+
+const (
+	rateControlHold rateControlState = iota
+	rateControlIncrease
+	rateControlDecrease
+)
+
+type packetGroup struct {
+	firstSendTime int64
+	lastSendTime  int64
+	arrivalTime   int64
+	size          int
+	valid         bool
+}
+
+// delayBasedBWE estimates the available bandwidth from the change of the one way delay between
+// groups of packets. A growing delay means queues are building up on the path.
+type delayBasedBWE struct {
+	current, previous packetGroup
+
+	trendline trendlineEstimator
+	detector  overuseDetector
+
+	received rateCounter
+
+	state   rateControlState
+	bitrate float64
+	// lastUpdate is in microseconds of the local clock
+	lastUpdate int64
+}
+
+// newDelayBasedBWE creates a delayBasedBWE starting at bitrate. If bitrate is 0 it starts at the received rate.
+func newDelayBasedBWE(bitrate int) *delayBasedBWE {
+	return &delayBasedBWE{
+		bitrate:  float64(bitrate),
+		detector: overuseDetector{threshold: initialThreshold, timeOverUsing: -1},
+		state:    rateControlIncrease,
+	}
+}
+
+// onAcknowledgements processes the packets acknowledged by a feedback and updates the estimate.
+func (d *delayBasedBWE) onAcknowledgements(acks []acknowledgement, now int64) {
+	for _, ack := range acks {
+		d.received.add(ack.arrivalTime, ack.size)
+
+		if d.current.valid && ack.sendTime-d.current.firstSendTime <= burstInterval && ack.sendTime >= d.current.firstSendTime {
+			d.current.lastSendTime = ack.sendTime
+			if ack.arrivalTime > d.current.arrivalTime {
+				d.current.arrivalTime = ack.arrivalTime
+			}
+			d.current.size += ack.size
+			continue
+		}
+
+		if d.current.valid && d.previous.valid {
+			sendDelta := d.current.lastSendTime - d.previous.lastSendTime
+			arrivalDelta := d.current.arrivalTime - d.previous.arrivalTime
+			d.trendline.update(float64(d.current.arrivalTime)/1000, float64(arrivalDelta-sendDelta)/1000)
+			d.detector.detect(d.trendline.trend, d.trendline.numDeltas, float64(sendDelta)/1000, now)
+			d.onUsage(d.detector.state)
+		}
+
+		d.previous = d.current
+		d.current = packetGroup{
+			firstSendTime: ack.sendTime,
+			lastSendTime:  ack.sendTime,
+			arrivalTime:   ack.arrivalTime,
+			size:          ack.size,
+			valid:         true,
+		}
+	}
+
+	d.updateBitrate(now)
+}
+
+func (d *delayBasedBWE) onUsage(u usage) {
+	switch u {
+	case usageOver:
+		d.state = rateControlDecrease
+	case usageUnder:
+		d.state = rateControlHold
+	case usageNormal:
+		if d.state == rateControlHold {
+			d.state = rateControlIncrease
+		}
+	}
+}
+
+func (d *delayBasedBWE) updateBitrate(now int64) {
+	if d.lastUpdate == 0 {
+		d.lastUpdate = now
+	}
+	elapsed := float64(now-d.lastUpdate) / float64(time.Second/time.Microsecond)
+	d.lastUpdate = now
+
+	receivedRate := d.received.rate()
+	if d.bitrate == 0 {
+		// Without an initial estimate, start from what is getting through
+		if receivedRate == 0 {
+			return
+		}
+		d.bitrate = receivedRate
+	}
+
+	switch d.state {
+	case rateControlIncrease:
+		d.bitrate *= math.Pow(increaseFactorPerSecond, math.Min(elapsed, 1))
+		// Don't move too far away from what is actually getting through
+		if receivedRate > 0 {
+			d.bitrate = math.Min(d.bitrate, 1.5*receivedRate+10000)
+		}
+	case rateControlDecrease:
+		if receivedRate > 0 {
+			d.bitrate = math.Min(d.bitrate, decreaseFactor*receivedRate)
+		} else {
+			d.bitrate *= decreaseFactor
+		}
+		d.state = rateControlHold
+	case rateControlHold:
+	}
+}
+
+// trendlineEstimator estimates the trend of the accumulated delay variation by linear regression.
+type trendlineEstimator struct {
+	numDeltas        int
+	accumulatedDelay float64
+	smoothedDelay    float64
+	firstArrival     float64
+	samples          []trendlineSample
+	trend            float64
+}
+
+type trendlineSample struct {
+	arrivalTime, smoothedDelay float64
+}
+
+// update adds the delay variation of a packet group. Times are in milliseconds.
+func (t *trendlineEstimator) update(arrivalTime, delayVariation float64) {
+	if t.numDeltas == 0 {
+		t.firstArrival = arrivalTime
+	}
+	if t.numDeltas < maxNumDeltas {
+		t.numDeltas++
+	}
+
+	t.accumulatedDelay += delayVariation
+	t.smoothedDelay = trendlineSmoothing*t.smoothedDelay + (1-trendlineSmoothing)*t.accumulatedDelay
+
+	t.samples = append(t.samples, trendlineSample{arrivalTime - t.firstArrival, t.smoothedDelay})
+	if len(t.samples) > trendlineWindowSize {
+		t.samples = t.samples[1:]
+	}
+
+	if len(t.samples) == trendlineWindowSize {
+		if trend, ok := linearFitSlope(t.samples); ok {
+			t.trend = trend
+		}
+	}
+}
+
+func linearFitSlope(samples []trendlineSample) (float64, bool) {
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.arrivalTime
+		sumY += s.smoothedDelay
+	}
+	avgX := sumX / float64(len(samples))
+	avgY := sumY / float64(len(samples))
+
+	var numerator, denominator float64
+	for _, s := range samples {
+		numerator += (s.arrivalTime - avgX) * (s.smoothedDelay - avgY)
+		denominator += (s.arrivalTime - avgX) * (s.arrivalTime - avgX)
+	}
+	if denominator == 0 {
+		return 0, false
+	}
+
+	return numerator / denominator, true
+}
+
+// overuseDetector compares the delay trend with an adaptive threshold.
+type overuseDetector struct {
+	state         usage
+	threshold     float64
+	timeOverUsing float64
+	overuseCount  int
+	prevTrend     float64
+	// lastUpdate is in microseconds of the local clock
+	lastUpdate int64
+}
+
+// detect updates the state of the detector. sendDelta is in milliseconds.
+func (o *overuseDetector) detect(trend float64, numDeltas int, sendDelta float64, now int64) {
+	if numDeltas < 2 {
+		return
+	}
+
+	modifiedTrend := float64(numDeltas) * trend * trendlineThresholdGain
+	switch {
+	case modifiedTrend > o.threshold:
+		if o.timeOverUsing == -1 {
+			// Assume the overuse started in the middle of the last two groups
+			o.timeOverUsing = sendDelta / 2
+		} else {
+			o.timeOverUsing += sendDelta
+		}
+		o.overuseCount++
+
+		if o.timeOverUsing > overuseTimeThreshold && o.overuseCount > 1 && trend >= o.prevTrend {
+			o.timeOverUsing = 0
+			o.overuseCount = 0
+			o.state = usageOver
+		}
+	case modifiedTrend < -o.threshold:
+		o.timeOverUsing = -1
+		o.overuseCount = 0
+		o.state = usageUnder
+	default:
+		o.timeOverUsing = -1
+		o.overuseCount = 0
+		o.state = usageNormal
+	}
+	o.prevTrend = trend
+
+	o.updateThreshold(modifiedTrend, now)
+}
+
+func (o *overuseDetector) updateThreshold(modifiedTrend float64, now int64) {
+	if o.lastUpdate == 0 {
+		o.lastUpdate = now
+	}
+
+	absTrend := math.Abs(modifiedTrend)
+	if absTrend > o.threshold+maxThresholdDeviation {
+		// Don't adapt to sudden spikes
+		o.lastUpdate = now
+		return
+	}
+
+	gain := thresholdGainUp
+	if absTrend < o.threshold {
+		gain = thresholdGainDown
+	}
+
+	timeDelta := math.Min(float64(now-o.lastUpdate)/1000, maxThresholdTimeDelta)
+	o.threshold += gain * (absTrend - o.threshold) * timeDelta
+	o.threshold = math.Max(minThreshold, math.Min(o.threshold, maxThreshold))
+	o.lastUpdate = now
+}
+
+// rateCounter measures the rate at which acknowledged packets arrived at the remote.
+type rateCounter struct {
+	samples []rateSample
+	bytes   int
+}
+
+type rateSample struct {
+	arrivalTime int64
+	size        int
+}
+
+func (r *rateCounter) add(arrivalTime int64, size int) {
+	r.samples = append(r.samples, rateSample{arrivalTime, size})
+	r.bytes += size
+
+	for len(r.samples) > 0 && arrivalTime-r.samples[0].arrivalTime > receivedRateWindow {
+		r.bytes -= r.samples[0].size
+		r.samples = r.samples[1:]
+	}
+}
+
+// rate returns the rate in bits per second, or 0 if there are not enough samples yet
+func (r *rateCounter) rate() float64 {
+	if len(r.samples) < 2 {
+		return 0
+	}
+
+	window := r.samples[len(r.samples)-1].arrivalTime - r.samples[0].arrivalTime
+	if window < minRateWindow {
+		return 0
+	}
+
+	return float64(r.bytes*8) * float64(time.Second/time.Microsecond) / float64(window)
+}
@@ -0,0 +1,80 @@
+package gcc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// simulateLink sends a 1200 byte packet every 5ms over a link with the given capacity in bits per second
+// for duration microseconds, and gives feedback every 100ms.
+func simulateLink(d *delayBasedBWE, capacity float64, duration int64) {
+	const (
+		packetSize = 1200
+		sendEvery  = 5000
+		feedback   = 100000
+		baseDelay  = 20000
+	)
+
+	acks := []acknowledgement{}
+	lastArrival := int64(0)
+	for sendTime := int64(sendEvery); sendTime <= duration; sendTime += sendEvery {
+		arrival := sendTime + baseDelay
+		if capacity > 0 {
+			if queued := lastArrival + int64(packetSize*8*1e6/capacity); queued > arrival {
+				arrival = queued
+			}
+		}
+		lastArrival = arrival
+
+		acks = append(acks, acknowledgement{sendTime: sendTime, arrivalTime: arrival, size: packetSize})
+		if sendTime%feedback == 0 {
+			d.onAcknowledgements(acks, arrival)
+			acks = acks[:0]
+		}
+	}
+}
+
+func TestDelayBasedBWE(t *testing.T) {
+	t.Run("Overuse", func(t *testing.T) {
+		d := newDelayBasedBWE(1000000)
+		simulateLink(d, 1000000, 2000000)
+
+		assert.Less(t, d.bitrate, 1000000.0)
+		assert.Greater(t, d.bitrate, 500000.0)
+	})
+
+	t.Run("No congestion", func(t *testing.T) {
+		d := newDelayBasedBWE(1000000)
+		simulateLink(d, 0, 2000000)
+
+		assert.Greater(t, d.bitrate, 1100000.0)
+		assert.Equal(t, rateControlIncrease, d.state)
+	})
+}
+
+func TestRateCounter(t *testing.T) {
+	r := rateCounter{}
+	assert.Equal(t, 0.0, r.rate())
+
+	for i := int64(0); i <= 20; i++ {
+		r.add(i*100000, 1250)
+	}
+
+	// 1250 bytes every 100ms is 100kbit/s, only the last second is counted
+	assert.InDelta(t, 110000.0, r.rate(), 1)
+	assert.Len(t, r.samples, 11)
+}
+
+func TestLossBasedBWE(t *testing.T) {
+	l := newLossBasedBWE(1000000)
+	l.onFractionLost(0, 1000000)
+	l.onFractionLost(0, 2000000)
+	assert.InDelta(t, 1080000.0, l.bitrate, 1)
+
+	l.onFractionLost(0.05, 3000000)
+	assert.InDelta(t, 1080000.0, l.bitrate, 1)
+
+	l.onFractionLost(0.2, 4000000)
+	assert.InDelta(t, 972000.0, l.bitrate, 1)
+}
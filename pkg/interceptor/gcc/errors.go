@@ -0,0 +1,6 @@
+package gcc
+
+import "errors"
+
+// ErrInvalidBitrate is returned by NewInterceptor when the configured bitrates contradict each other.
+var ErrInvalidBitrate = errors.New("invalid bitrate, expected 0 < min <= initial <= max")
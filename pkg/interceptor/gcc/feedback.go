@@ -0,0 +1,117 @@
+package gcc
+
+import (
+	"github.com/pion/rtcp"
+)
+
+const (
+	// sendHistorySize is the number of sent packets remembered until feedback for them arrives
+	sendHistorySize = 1 << 11
+
+	// referenceTimeUnit is the resolution of the feedback reference time in microseconds
+	referenceTimeUnit = 64000
+	referenceTimeMax  = 1 << 24
+)
+
+type sentPacket struct {
+	sequenceNumber uint16
+	// sendTime is in microseconds
+	sendTime int64
+	size     int
+	valid    bool
+}
+
+// acknowledgement is a sent packet the remote reported as received
+type acknowledgement struct {
+	sequenceNumber uint16
+	// sendTime and arrivalTime are in microseconds, arrivalTime uses the clock of the remote
+	sendTime    int64
+	arrivalTime int64
+	size        int
+}
+
+// sendHistory remembers the send time and size of packets by their transport wide sequence number
+type sendHistory struct {
+	packets []sentPacket
+	// the reference time is only 24 bits and wraps around
+	referenceTime unwrapper
+}
+
+func newSendHistory() *sendHistory {
+	return &sendHistory{
+		packets:       make([]sentPacket, sendHistorySize),
+		referenceTime: unwrapper{size: referenceTimeMax},
+	}
+}
+
+func (h *sendHistory) add(sequenceNumber uint16, sendTime int64, size int) {
+	h.packets[sequenceNumber%sendHistorySize] = sentPacket{
+		sequenceNumber: sequenceNumber,
+		sendTime:       sendTime,
+		size:           size,
+		valid:          true,
+	}
+}
+
+func (h *sendHistory) get(sequenceNumber uint16) (sentPacket, bool) {
+	p := h.packets[sequenceNumber%sendHistorySize]
+	if !p.valid || p.sequenceNumber != sequenceNumber {
+		return sentPacket{}, false
+	}
+
+	return p, true
+}
+
+// acknowledgements matches the packets reported as received in feedback with the send history.
+// It also returns the number of packets reported as lost.
+func (h *sendHistory) acknowledgements(feedback *rtcp.TransportLayerCC) (acks []acknowledgement, lost int) {
+	symbols := packetStatusSymbols(feedback)
+	arrivalTime := h.referenceTime.unwrap(int64(feedback.ReferenceTime)) * referenceTimeUnit
+	deltas := feedback.RecvDeltas
+
+	for i, symbol := range symbols {
+		if symbol == rtcp.TypeTCCPacketNotReceived {
+			lost++
+			continue
+		}
+		if len(deltas) == 0 {
+			break
+		}
+
+		arrivalTime += deltas[0].Delta
+		deltas = deltas[1:]
+
+		sequenceNumber := feedback.BaseSequenceNumber + uint16(i)
+		if p, ok := h.get(sequenceNumber); ok {
+			acks = append(acks, acknowledgement{
+				sequenceNumber: sequenceNumber,
+				sendTime:       p.sendTime,
+				arrivalTime:    arrivalTime,
+				size:           p.size,
+			})
+		}
+	}
+
+	return acks, lost
+}
+
+// packetStatusSymbols expands the packet chunks of feedback into one symbol per reported packet
+func packetStatusSymbols(feedback *rtcp.TransportLayerCC) []uint16 {
+	symbols := make([]uint16, 0, feedback.PacketStatusCount)
+	for _, chunk := range feedback.PacketChunks {
+		switch c := chunk.(type) {
+		case *rtcp.RunLengthChunk:
+			for i := uint16(0); i < c.RunLength; i++ {
+				symbols = append(symbols, c.PacketStatusSymbol)
+			}
+		case *rtcp.StatusVectorChunk:
+			symbols = append(symbols, c.SymbolList...)
+		}
+	}
+
+	if len(symbols) > int(feedback.PacketStatusCount) {
+		symbols = symbols[:feedback.PacketStatusCount]
+	}
+
+	return symbols
+}
@@ -0,0 +1,41 @@
+package gcc
+
+import (
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3/pkg/interceptor/twcc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendHistory(t *testing.T) {
+	h := newSendHistory()
+	for i := uint16(0); i < 10; i++ {
+		h.add(65530+i, int64(i)*1000, 100+int(i))
+	}
+
+	// Packets fall out of the history once it is full
+	h.add(65530+sendHistorySize-1<<16, 0, 0)
+	_, ok := h.get(65530)
+	assert.False(t, ok)
+
+	r := twcc.NewRecorder(1)
+	r.Record(2, 65531, 64000*10+500)
+	r.Record(2, 65533, 64000*10+1500)
+	r.Record(2, 1, 64000*10+100000)
+
+	pkts := r.BuildFeedbackPacket()
+	assert.Len(t, pkts, 1)
+	raw, err := pkts[0].Marshal()
+	assert.NoError(t, err)
+	feedback := &rtcp.TransportLayerCC{}
+	assert.NoError(t, feedback.Unmarshal(raw))
+
+	acks, lost := h.acknowledgements(feedback)
+	assert.Equal(t, 4, lost)
+	assert.Equal(t, []acknowledgement{
+		{sequenceNumber: 65531, sendTime: 1000, arrivalTime: 64000*10 + 500, size: 101},
+		{sequenceNumber: 65533, sendTime: 3000, arrivalTime: 64000*10 + 1500, size: 103},
+		{sequenceNumber: 1, sendTime: 7000, arrivalTime: 64000*10 + 100000, size: 107},
+	}, acks)
+}
@@ -0,0 +1,19 @@
+// Package gcc provides send and receive side bandwidth estimators, based on the ideas of Google Congestion Control.
+// https://tools.ietf.org/html/draft-ietf-rmcat-gcc-02
+package gcc
+
+import (
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// headerExtensionID returns the negotiated ID of the header extension with uri,
+// or 0 if it wasn't negotiated for the stream.
+func headerExtensionID(info *interceptor.StreamInfo, uri string) uint8 {
+	for _, e := range info.RTPHeaderExtensions {
+		if e.URI == uri {
+			return uint8(e.ID)
+		}
+	}
+
+	return 0
+}
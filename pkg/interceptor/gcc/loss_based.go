@@ -0,0 +1,40 @@
+package gcc
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	lowLossThreshold  = 0.02
+	highLossThreshold = 0.1
+)
+
+// lossBasedBWE estimates the available bandwidth from the fraction of packets lost reported by the remote.
+type lossBasedBWE struct {
+	bitrate  float64
+	reported bool
+	// lastUpdate is in microseconds of the local clock
+	lastUpdate int64
+}
+
+func newLossBasedBWE(bitrate int) *lossBasedBWE {
+	return &lossBasedBWE{bitrate: float64(bitrate)}
+}
+
+// onFractionLost updates the estimate with the fraction of packets lost, 0 to 1, since the last report.
+func (l *lossBasedBWE) onFractionLost(fractionLost float64, now int64) {
+	if !l.reported {
+		l.reported = true
+		l.lastUpdate = now
+	}
+	elapsed := float64(now-l.lastUpdate) / float64(time.Second/time.Microsecond)
+	l.lastUpdate = now
+
+	switch {
+	case fractionLost < lowLossThreshold:
+		l.bitrate *= math.Pow(increaseFactorPerSecond, math.Min(elapsed, 1))
+	case fractionLost > highLossThreshold:
+		l.bitrate *= 1 - 0.5*fractionLost
+	}
+}
@@ -0,0 +1,269 @@
+package gcc
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+const (
+	// absSendTimeFraction is the number of fractional bits of the 6.18 fixed point abs-send-time
+	absSendTimeFraction = 18
+	absSendTimeMax      = 1 << 24
+	rtpTimestampMax     = 1 << 32
+)
+
+// ReceiveSideBWEFactory is a interceptor.Factory for a ReceiveSideBWE
+type ReceiveSideBWEFactory struct {
+	opts []ReceiveSideBWEOption
+}
+
+// NewInterceptor constructs a new ReceiveSideBWE
+func (r *ReceiveSideBWEFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &ReceiveSideBWE{
+		interval:   time.Second,
+		now:        time.Now,
+		log:        logging.NewDefaultLoggerFactory().NewLogger("gcc_receive_side_bwe"),
+		senderSSRC: rand.Uint32(), // #nosec
+		streams:    map[uint32]*receiveStream{},
+		close:      make(chan struct{}),
+	}
+
+	for _, opt := range r.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// NewReceiveSideBWE returns a new ReceiveSideBWEFactory
+func NewReceiveSideBWE(opts ...ReceiveSideBWEOption) (*ReceiveSideBWEFactory, error) {
+	return &ReceiveSideBWEFactory{opts}, nil
+}
+
+// ReceiveSideBWE interceptor estimates the bandwidth available to each remote video stream, from the
+// inter-arrival delay of its packets, and sends it as Receiver Estimated Maximum Bitrate (REMB).
+// The send time is taken from the abs-send-time header extension if negotiated, from the RTP timestamp otherwise.
+// Only streams which negotiated goog-remb feedback are estimated.
+type ReceiveSideBWE struct {
+	interceptor.NoOp
+	interval   time.Duration
+	now        func() time.Time
+	log        logging.LeveledLogger
+	senderSSRC uint32
+	m          sync.Mutex
+	wg         sync.WaitGroup
+	close      chan struct{}
+
+	rtcpWriter  interceptor.RTCPWriter
+	loopStarted bool
+
+	streams   map[uint32]*receiveStream
+	streamsMu sync.Mutex
+}
+
+type receiveStream struct {
+	estimator     *delayBasedBWE
+	absSendTimeID uint8
+	clockRate     uint32
+	sendTime      unwrapper
+	started       bool
+}
+
+func streamSupportREMB(info *interceptor.StreamInfo) bool {
+	if !strings.HasPrefix(strings.ToLower(info.MimeType), "video/") {
+		return false
+	}
+
+	for _, fb := range info.RTCPFeedback {
+		if fb.Type == "goog-remb" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BindRTCPWriter lets you modify any outgoing RTCP packets. It is called once per PeerConnection. The returned method
+// will be called once per packet batch.
+func (r *ReceiveSideBWE) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.rtcpWriter = writer
+	r.startLoop()
+
+	return writer
+}
+
+// BindRemoteStream lets you modify any incoming RTP packets. It is called once for per RemoteStream. The returned method
+// will be called once per rtp packet.
+func (r *ReceiveSideBWE) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	absSendTimeID := headerExtensionID(info, sdp.ABSSendTimeURI)
+	if !streamSupportREMB(info) {
+		return reader
+	}
+
+	stream := &receiveStream{
+		estimator:     newDelayBasedBWE(0),
+		absSendTimeID: absSendTimeID,
+		clockRate:     info.ClockRate,
+	}
+
+	r.streamsMu.Lock()
+	r.streams[info.SSRC] = stream
+	r.streamsMu.Unlock()
+
+	r.m.Lock()
+	r.startLoop()
+	r.m.Unlock()
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		header := rtp.Header{}
+		if err = header.Unmarshal(b[:i]); err != nil {
+			return 0, nil, err
+		}
+
+		arrivalTime := r.now().UnixNano() / int64(time.Microsecond)
+		r.streamsMu.Lock()
+		stream.processRTP(&header, i, arrivalTime)
+		r.streamsMu.Unlock()
+
+		return i, attr, nil
+	})
+}
+
+// UnbindRemoteStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (r *ReceiveSideBWE) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	r.streamsMu.Lock()
+	delete(r.streams, info.SSRC)
+	r.streamsMu.Unlock()
+}
+
+// Close closes the interceptor.
+func (r *ReceiveSideBWE) Close() error {
+	defer r.wg.Wait()
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if !r.isClosed() {
+		close(r.close)
+	}
+
+	return nil
+}
+
+// processRTP feeds a received packet to the estimator. arrivalTime is in microseconds.
+// The first packet decides the time base of the stream, as abs-send-time may be negotiated but not sent.
+func (s *receiveStream) processRTP(header *rtp.Header, size int, arrivalTime int64) {
+	if !s.started {
+		s.started = true
+		if s.absSendTimeID != 0 && len(header.GetExtension(s.absSendTimeID)) < 3 {
+			s.absSendTimeID = 0
+		}
+
+		s.sendTime = unwrapper{size: rtpTimestampMax}
+		if s.absSendTimeID != 0 {
+			s.sendTime.size = absSendTimeMax
+		}
+	}
+
+	var sendTime int64
+	switch {
+	case s.absSendTimeID != 0:
+		ext := header.GetExtension(s.absSendTimeID)
+		if len(ext) < 3 {
+			return
+		}
+
+		absSendTime := int64(ext[0])<<16 | int64(ext[1])<<8 | int64(ext[2])
+		sendTime = s.sendTime.unwrap(absSendTime) * int64(time.Second/time.Microsecond) >> absSendTimeFraction
+	case s.clockRate != 0:
+		sendTime = s.sendTime.unwrap(int64(header.Timestamp)) * int64(time.Second/time.Microsecond) / int64(s.clockRate)
+	default:
+		return
+	}
+
+	s.estimator.onAcknowledgements([]acknowledgement{{
+		sequenceNumber: header.SequenceNumber,
+		sendTime:       sendTime,
+		arrivalTime:    arrivalTime,
+		size:           size,
+	}}, arrivalTime)
+}
+
+// startLoop starts sending REMB once there is both a RTCPWriter and a stream to estimate. r.m must be held.
+func (r *ReceiveSideBWE) startLoop() {
+	if r.loopStarted || r.rtcpWriter == nil || r.isClosed() {
+		return
+	}
+
+	r.streamsMu.Lock()
+	hasStreams := len(r.streams) != 0
+	r.streamsMu.Unlock()
+	if !hasStreams {
+		return
+	}
+
+	r.loopStarted = true
+	r.wg.Add(1)
+	go r.loop(r.rtcpWriter)
+}
+
+func (r *ReceiveSideBWE) loop(rtcpWriter interceptor.RTCPWriter) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.streamsMu.Lock()
+			pkts := make([]rtcp.Packet, 0, len(r.streams))
+			for ssrc, stream := range r.streams {
+				if stream.estimator.bitrate == 0 {
+					continue
+				}
+
+				pkts = append(pkts, &rtcp.ReceiverEstimatedMaximumBitrate{
+					SenderSSRC: r.senderSSRC,
+					Bitrate:    uint64(stream.estimator.bitrate),
+					SSRCs:      []uint32{ssrc},
+				})
+			}
+			r.streamsMu.Unlock()
+
+			for _, pkt := range pkts {
+				if _, err := rtcpWriter.Write([]rtcp.Packet{pkt}, interceptor.Attributes{}); err != nil {
+					r.log.Warnf("failed sending: %+v", err)
+				}
+			}
+
+		case <-r.close:
+			return
+		}
+	}
+}
+
+func (r *ReceiveSideBWE) isClosed() bool {
+	select {
+	case <-r.close:
+		return true
+	default:
+		return false
+	}
+}
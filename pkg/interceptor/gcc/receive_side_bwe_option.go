@@ -0,0 +1,34 @@
+package gcc
+
+import (
+	"time"
+
+	"github.com/pion/logging"
+)
+
+// ReceiveSideBWEOption can be used to configure ReceiveSideBWE.
+type ReceiveSideBWEOption func(*ReceiveSideBWE) error
+
+// ReceiveSideBWELog sets a logger for the interceptor.
+func ReceiveSideBWELog(log logging.LeveledLogger) ReceiveSideBWEOption {
+	return func(r *ReceiveSideBWE) error {
+		r.log = log
+		return nil
+	}
+}
+
+// ReceiveSideBWEInterval sets the interval at which REMB packets are sent.
+func ReceiveSideBWEInterval(interval time.Duration) ReceiveSideBWEOption {
+	return func(r *ReceiveSideBWE) error {
+		r.interval = interval
+		return nil
+	}
+}
+
+// ReceiveSideBWENow sets an alternative for the time.Now function.
+func ReceiveSideBWENow(f func() time.Time) ReceiveSideBWEOption {
+	return func(r *ReceiveSideBWE) error {
+		r.now = f
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package gcc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiveSideBWE(t *testing.T) {
+	var nowMu sync.Mutex
+	now := time.Unix(1000, 0)
+
+	f, err := NewReceiveSideBWE(
+		ReceiveSideBWEInterval(time.Millisecond*10),
+		ReceiveSideBWENow(func() time.Time {
+			nowMu.Lock()
+			defer nowMu.Unlock()
+			return now
+		}),
+	)
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	rtcpWritten := make(chan []rtcp.Packet, 100)
+	i.BindRTCPWriter(interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+		rtcpWritten <- pkts
+		return 0, nil
+	}))
+
+	bind := func(info *interceptor.StreamInfo, rtpIn chan []byte) interceptor.RTPReader {
+		return i.BindRemoteStream(info, interceptor.RTPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+			return copy(b, <-rtpIn), nil, nil
+		}))
+	}
+
+	rtpIn := make(chan []byte, 1)
+	reader := bind(&interceptor.StreamInfo{
+		SSRC:                123456,
+		MimeType:            "video/VP8",
+		ClockRate:           90000,
+		RTCPFeedback:        []interceptor.RTCPFeedback{{Type: "goog-remb"}},
+		RTPHeaderExtensions: []interceptor.RTPHeaderExtension{{URI: sdp.ABSSendTimeURI, ID: 3}},
+	}, rtpIn)
+
+	// Streams without goog-remb are not estimated
+	withoutREMB := make(chan []byte, 1)
+	bind(&interceptor.StreamInfo{SSRC: 7, MimeType: "video/VP8", ClockRate: 90000}, withoutREMB)
+
+	// 1200 bytes every 5ms, sent with abs-send-time
+	for n := 0; n < 200; n++ {
+		nowMu.Lock()
+		now = now.Add(5 * time.Millisecond)
+		nowMu.Unlock()
+
+		absSendTime := uint32(n*5) << 18 / 1000
+		header := rtp.Header{SSRC: 123456, SequenceNumber: uint16(n), Timestamp: uint32(n * 450)}
+		assert.NoError(t, header.SetExtension(3, []byte{byte(absSendTime >> 16), byte(absSendTime >> 8), byte(absSendTime)}))
+		raw, err := (&rtp.Packet{Header: header, Payload: make([]byte, 1200-header.MarshalSize())}).Marshal()
+		assert.NoError(t, err)
+
+		rtpIn <- raw
+		_, _, err = reader.Read(make([]byte, 1500), nil)
+		assert.NoError(t, err)
+	}
+
+	for {
+		pkts := <-rtcpWritten
+		assert.Len(t, pkts, 1)
+		remb, ok := pkts[0].(*rtcp.ReceiverEstimatedMaximumBitrate)
+		assert.True(t, ok)
+		assert.Equal(t, []uint32{123456}, remb.SSRCs)
+		if remb.Bitrate > 1920000 {
+			break
+		}
+	}
+
+	assert.NoError(t, i.Close())
+}
@@ -0,0 +1,214 @@
+package gcc
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+const (
+	defaultInitialBitrate = 1000000
+	defaultMinBitrate     = 30000
+	defaultMaxBitrate     = 50000000
+)
+
+// SendSideBWEFactory is a interceptor.Factory for a SendSideBWE
+type SendSideBWEFactory struct {
+	opts []SendSideBWEOption
+}
+
+// NewInterceptor constructs a new SendSideBWE
+func (s *SendSideBWEFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &SendSideBWE{
+		initialBitrate: defaultInitialBitrate,
+		minBitrate:     defaultMinBitrate,
+		maxBitrate:     defaultMaxBitrate,
+		now:            time.Now,
+		log:            logging.NewDefaultLoggerFactory().NewLogger("gcc_send_side_bwe"),
+		history:        newSendHistory(),
+	}
+
+	for _, opt := range s.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.minBitrate <= 0 || i.minBitrate > i.initialBitrate || i.initialBitrate > i.maxBitrate {
+		return nil, ErrInvalidBitrate
+	}
+
+	i.delayBased = newDelayBasedBWE(i.initialBitrate)
+	i.lossBased = newLossBasedBWE(i.initialBitrate)
+	i.targetBitrate = i.initialBitrate
+
+	return i, nil
+}
+
+// NewSendSideBWE returns a new SendSideBWEFactory
+func NewSendSideBWE(opts ...SendSideBWEOption) (*SendSideBWEFactory, error) {
+	return &SendSideBWEFactory{opts}, nil
+}
+
+// SendSideBWE interceptor estimates the bandwidth available for sending. It combines a delay based estimate,
+// fed by TransportLayerCC feedback, with a loss based estimate, fed by Receiver Reports.
+// The transport wide sequence number must already be set on outgoing packets when they reach SendSideBWE,
+// so it has to be registered before the interceptor adding it.
+type SendSideBWE struct {
+	interceptor.NoOp
+	initialBitrate int
+	minBitrate     int
+	maxBitrate     int
+	now            func() time.Time
+	log            logging.LeveledLogger
+
+	history   *sendHistory
+	historyMu sync.Mutex
+
+	delayBased *delayBasedBWE
+	lossBased  *lossBasedBWE
+
+	targetBitrate         int
+	onTargetBitrateChange func(bitrate int)
+	m                     sync.Mutex
+}
+
+// GetTargetBitrate returns the current estimate in bits per second.
+func (s *SendSideBWE) GetTargetBitrate() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.targetBitrate
+}
+
+// OnTargetBitrateChange sets a handler that is called with the new estimate in bits per second
+// each time it changes.
+func (s *SendSideBWE) OnTargetBitrateChange(f func(bitrate int)) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.onTargetBitrateChange = f
+}
+
+// BindLocalStream lets you modify any outgoing RTP packets. It is called once for per LocalStream. The returned method
+// will be called once per rtp packet.
+func (s *SendSideBWE) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	hdrExtID := headerExtensionID(info, sdp.TransportCCURI)
+	if hdrExtID == 0 {
+		return writer
+	}
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		if ext := header.GetExtension(hdrExtID); ext != nil {
+			tcc := rtp.TransportCCExtension{}
+			if err := tcc.Unmarshal(ext); err == nil {
+				sendTime := s.now().UnixNano() / int64(time.Microsecond)
+				s.historyMu.Lock()
+				s.history.add(tcc.TransportSequence, sendTime, header.MarshalSize()+len(payload))
+				s.historyMu.Unlock()
+			}
+		}
+
+		return writer.Write(header, payload, attributes)
+	})
+}
+
+// BindRTCPReader lets you modify any incoming RTCP packets. It is called once per sender/receiver, however this might
+// change in the future. The returned method will be called once per packet batch.
+// The estimate is only updated if the RTCP of the RTPSenders is read.
+func (s *SendSideBWE) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkts, err := rtcp.Unmarshal(b[:i])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		for _, pkt := range pkts {
+			switch pkt := pkt.(type) {
+			case *rtcp.TransportLayerCC:
+				s.onTransportLayerCC(pkt)
+			case *rtcp.ReceiverReport:
+				s.onReceptionReports(pkt.Reports)
+			case *rtcp.SenderReport:
+				s.onReceptionReports(pkt.Reports)
+			}
+		}
+
+		return i, attr, nil
+	})
+}
+
+func (s *SendSideBWE) onTransportLayerCC(feedback *rtcp.TransportLayerCC) {
+	s.historyMu.Lock()
+	acks, _ := s.history.acknowledgements(feedback)
+	s.historyMu.Unlock()
+
+	now := s.now().UnixNano() / int64(time.Microsecond)
+	s.update(func() {
+		s.delayBased.onAcknowledgements(acks, now)
+	})
+}
+
+func (s *SendSideBWE) onReceptionReports(reports []rtcp.ReceptionReport) {
+	if len(reports) == 0 {
+		return
+	}
+
+	// Use the stream with the most loss, it is the first to suffer from congestion
+	fractionLost := uint8(0)
+	for _, r := range reports {
+		if r.FractionLost > fractionLost {
+			fractionLost = r.FractionLost
+		}
+	}
+
+	now := s.now().UnixNano() / int64(time.Microsecond)
+	s.update(func() {
+		s.lossBased.onFractionLost(float64(fractionLost)/256, now)
+	})
+}
+
+// update runs updateEstimate, then combines both estimates and notifies the handler if the result changed.
+func (s *SendSideBWE) update(updateEstimate func()) {
+	s.m.Lock()
+	updateEstimate()
+
+	// The loss based estimate is bounded by the delay based one. Until the remote
+	// reports loss, it follows the delay based estimate.
+	s.delayBased.bitrate = s.clamp(s.delayBased.bitrate)
+	if s.lossBased.reported {
+		s.lossBased.bitrate = s.clamp(math.Min(s.lossBased.bitrate, s.delayBased.bitrate))
+	} else {
+		s.lossBased.bitrate = s.delayBased.bitrate
+	}
+
+	target := int(s.lossBased.bitrate)
+	changed := target != s.targetBitrate
+	s.targetBitrate = target
+	handler := s.onTargetBitrateChange
+	s.m.Unlock()
+
+	if !changed {
+		return
+	}
+
+	s.log.Debugf("target bitrate changed to %d", target)
+	if handler != nil {
+		handler(target)
+	}
+}
+
+func (s *SendSideBWE) clamp(bitrate float64) float64 {
+	return math.Max(float64(s.minBitrate), math.Min(bitrate, float64(s.maxBitrate)))
+}
@@ -0,0 +1,50 @@
+package gcc
+
+import (
+	"time"
+
+	"github.com/pion/logging"
+)
+
+// SendSideBWEOption can be used to configure SendSideBWE.
+type SendSideBWEOption func(*SendSideBWE) error
+
+// SendSideBWELog sets a logger for the interceptor.
+func SendSideBWELog(log logging.LeveledLogger) SendSideBWEOption {
+	return func(s *SendSideBWE) error {
+		s.log = log
+		return nil
+	}
+}
+
+// SendSideBWEInitialBitrate sets the estimate in bits per second used until feedback arrives.
+func SendSideBWEInitialBitrate(bitrate int) SendSideBWEOption {
+	return func(s *SendSideBWE) error {
+		s.initialBitrate = bitrate
+		return nil
+	}
+}
+
+// SendSideBWEMinBitrate sets the lowest estimate in bits per second.
+func SendSideBWEMinBitrate(bitrate int) SendSideBWEOption {
+	return func(s *SendSideBWE) error {
+		s.minBitrate = bitrate
+		return nil
+	}
+}
+
+// SendSideBWEMaxBitrate sets the highest estimate in bits per second.
+func SendSideBWEMaxBitrate(bitrate int) SendSideBWEOption {
+	return func(s *SendSideBWE) error {
+		s.maxBitrate = bitrate
+		return nil
+	}
+}
+
+// SendSideBWENow sets an alternative for the time.Now function.
+func SendSideBWENow(f func() time.Time) SendSideBWEOption {
+	return func(s *SendSideBWE) error {
+		s.now = f
+		return nil
+	}
+}
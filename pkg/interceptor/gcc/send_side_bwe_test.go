@@ -0,0 +1,93 @@
+package gcc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/twcc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendSideBWE(t *testing.T) {
+	now := time.Unix(0, 0)
+	f, err := NewSendSideBWE(
+		SendSideBWEInitialBitrate(500000),
+		SendSideBWENow(func() time.Time { return now }),
+	)
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+	bwe, ok := i.(*SendSideBWE)
+	assert.True(t, ok)
+	assert.Equal(t, 500000, bwe.GetTargetBitrate())
+
+	changes := make(chan int, 100)
+	bwe.OnTargetBitrateChange(func(bitrate int) {
+		changes <- bitrate
+	})
+
+	writer := i.BindLocalStream(&interceptor.StreamInfo{
+		SSRC:                1,
+		RTPHeaderExtensions: []interceptor.RTPHeaderExtension{{URI: sdp.TransportCCURI, ID: 2}},
+	}, interceptor.RTPWriterFunc(func(_ *rtp.Header, _ []byte, _ interceptor.Attributes) (int, error) {
+		return 0, nil
+	}))
+
+	rtcpIn := make(chan []rtcp.Packet, 1)
+	reader := i.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		raw, err := rtcp.Marshal(<-rtcpIn)
+		if err != nil {
+			return 0, nil, err
+		}
+		return copy(b, raw), nil, nil
+	}))
+
+	// Send 1200 bytes every 5ms, without any congestion the estimate grows
+	recorder := twcc.NewRecorder(5)
+	for sequenceNumber := uint16(0); sequenceNumber < 400; sequenceNumber++ {
+		now = now.Add(5 * time.Millisecond)
+
+		tcc, err := (&rtp.TransportCCExtension{TransportSequence: sequenceNumber}).Marshal()
+		assert.NoError(t, err)
+		header := &rtp.Header{SSRC: 1}
+		assert.NoError(t, header.SetExtension(2, tcc))
+		_, err = writer.Write(header, make([]byte, 1200-header.MarshalSize()), nil)
+		assert.NoError(t, err)
+
+		recorder.Record(1, sequenceNumber, now.Add(20*time.Millisecond).UnixNano()/int64(time.Microsecond))
+		if sequenceNumber%20 == 19 {
+			rtcpIn <- recorder.BuildFeedbackPacket()
+			_, _, err = reader.Read(make([]byte, 1500), nil)
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Greater(t, bwe.GetTargetBitrate(), 500000)
+	lastChange := 0
+	for len(changes) > 0 {
+		lastChange = <-changes
+	}
+	assert.Equal(t, bwe.GetTargetBitrate(), lastChange)
+
+	// Heavy loss reduces the estimate
+	target := bwe.GetTargetBitrate()
+	rtcpIn <- []rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{{SSRC: 1, FractionLost: 128}}}}
+	_, _, err = reader.Read(make([]byte, 1500), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int(float64(target)*0.75), bwe.GetTargetBitrate())
+
+	assert.NoError(t, i.Close())
+}
+
+func TestSendSideBWE_InvalidBitrate(t *testing.T) {
+	f, err := NewSendSideBWE(SendSideBWEMinBitrate(100000), SendSideBWEMaxBitrate(50000))
+	assert.NoError(t, err)
+
+	_, err = f.NewInterceptor("")
+	assert.Equal(t, ErrInvalidBitrate, err)
+}
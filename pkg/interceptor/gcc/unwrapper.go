@@ -0,0 +1,33 @@
+package gcc
+
+// unwrapper extends a counter that wraps around after size to an int64
+type unwrapper struct {
+	size int64
+
+	started bool
+	cycles  int64
+	last    int64
+}
+
+func (u *unwrapper) unwrap(value int64) int64 {
+	value %= u.size
+	if !u.started {
+		u.started = true
+		u.last = value
+	}
+
+	cycles := u.cycles
+	switch diff := value - u.last; {
+	case diff < -u.size/2:
+		u.cycles += u.size
+		cycles = u.cycles
+		u.last = value
+	case diff > u.size/2:
+		// late value from before the last wraparound
+		cycles -= u.size
+	case diff > 0:
+		u.last = value
+	}
+
+	return cycles + value
+}
@@ -0,0 +1,16 @@
+package gcc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnwrapper(t *testing.T) {
+	u := unwrapper{size: 1 << 24}
+	assert.Equal(t, int64(1<<24-1), u.unwrap(1<<24-1))
+	assert.Equal(t, int64(1<<24+1), u.unwrap(1))
+	assert.Equal(t, int64(1<<24-2), u.unwrap(1<<24-2))
+	assert.Equal(t, int64(1<<24+2), u.unwrap(2))
+	assert.Equal(t, int64(1<<24+5), u.unwrap(1<<24+5))
+}
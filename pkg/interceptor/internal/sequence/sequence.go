@@ -0,0 +1,90 @@
+// Package sequence provides helpers for the RTP sequence numbers shared by the interceptors
+package sequence
+
+import "github.com/pion/rtcp"
+
+// Uint16SizeHalf is used to tell apart sequence number wraparound from reordering
+const Uint16SizeHalf = 1 << 15
+
+// historySize is the number of most recent sequence numbers a History remembers
+const historySize = 512
+
+// History remembers which of the most recent sequence numbers of a stream were received,
+// to tell a packet received twice, such as a repaired packet whose original arrived late,
+// apart from a new one. It isn't safe for concurrent use.
+type History struct {
+	received [historySize / 64]uint64
+	started  bool
+	highest  uint16
+}
+
+// Add records seq, it returns false if seq was already recorded. Sequence numbers older
+// than the ones remembered are always reported as new.
+func (h *History) Add(seq uint16) bool {
+	if !h.started {
+		h.started = true
+		h.highest = seq
+		h.set(seq)
+		return true
+	}
+
+	diff := seq - h.highest
+	switch {
+	case diff == 0:
+		return false
+	case diff < Uint16SizeHalf:
+		// seq is newer than highest, forget the sequence numbers skipped
+		// between them as they may still be set from a previous lap
+		if diff >= historySize {
+			h.received = [historySize / 64]uint64{}
+		} else {
+			for i := h.highest + 1; i != seq; i++ {
+				h.clear(i)
+			}
+		}
+		h.highest = seq
+	case h.highest-seq >= historySize:
+		return true
+	case h.isSet(seq):
+		return false
+	}
+
+	h.set(seq)
+	return true
+}
+
+func (h *History) set(seq uint16) {
+	pos := seq % historySize
+	h.received[pos/64] |= 1 << (pos % 64)
+}
+
+func (h *History) clear(seq uint16) {
+	pos := seq % historySize
+	h.received[pos/64] &^= 1 << (pos % 64)
+}
+
+func (h *History) isSet(seq uint16) bool {
+	pos := seq % historySize
+	return h.received[pos/64]&(1<<(pos%64)) != 0
+}
+
+// NackPairs packs a sorted list of missing sequence numbers into NackPairs
+func NackPairs(seqNums []uint16) []rtcp.NackPair {
+	pairs := []rtcp.NackPair{}
+	if len(seqNums) == 0 {
+		return pairs
+	}
+
+	current := rtcp.NackPair{PacketID: seqNums[0]}
+	for _, seq := range seqNums[1:] {
+		if diff := seq - current.PacketID; diff > 0 && diff <= 16 {
+			current.LostPackets |= 1 << (diff - 1)
+			continue
+		}
+
+		pairs = append(pairs, current)
+		current = rtcp.NackPair{PacketID: seq}
+	}
+
+	return append(pairs, current)
+}
@@ -0,0 +1,53 @@
+package sequence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	t.Run("duplicates", func(t *testing.T) {
+		h := History{}
+		assert.True(t, h.Add(10))
+		assert.False(t, h.Add(10))
+		assert.True(t, h.Add(12))
+
+		// A late packet is new once, then a duplicate
+		assert.True(t, h.Add(11))
+		assert.False(t, h.Add(11))
+		assert.False(t, h.Add(12))
+	})
+
+	t.Run("sequence number wraparound", func(t *testing.T) {
+		h := History{}
+		assert.True(t, h.Add(0xfffe))
+		assert.True(t, h.Add(0x0001))
+		assert.True(t, h.Add(0xffff))
+		assert.True(t, h.Add(0x0000))
+		assert.False(t, h.Add(0xfffe))
+		assert.False(t, h.Add(0x0000))
+	})
+
+	t.Run("skipped sequence numbers are forgotten", func(t *testing.T) {
+		// historySize+1 uses the same bit as 1, a lap earlier
+		h := History{}
+		assert.True(t, h.Add(1))
+		assert.True(t, h.Add(3))
+		assert.True(t, h.Add(historySize+2))
+		assert.True(t, h.Add(historySize+1))
+		assert.False(t, h.Add(3))
+
+		h = History{}
+		assert.True(t, h.Add(1))
+		assert.True(t, h.Add(10*historySize))
+		assert.True(t, h.Add(9*historySize+1))
+	})
+
+	t.Run("too old to tell", func(t *testing.T) {
+		h := History{}
+		assert.True(t, h.Add(1000))
+		assert.True(t, h.Add(1000-historySize))
+		assert.True(t, h.Add(1000-historySize))
+	})
+}
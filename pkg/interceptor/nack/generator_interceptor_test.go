@@ -0,0 +1,71 @@
+package nack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorInterceptor(t *testing.T) {
+	f, err := NewGeneratorInterceptor(
+		GeneratorSize(64),
+		GeneratorSkipLastN(2),
+		GeneratorInterval(time.Millisecond*10),
+	)
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	rtcpWritten := make(chan []rtcp.Packet, 10)
+	i.BindRTCPWriter(interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+		rtcpWritten <- pkts
+		return 0, nil
+	}))
+
+	rtpIn := make(chan []byte, 10)
+	reader := i.BindRemoteStream(&interceptor.StreamInfo{
+		SSRC:         1,
+		RTCPFeedback: []interceptor.RTCPFeedback{{Type: "nack"}},
+	}, interceptor.RTPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-rtpIn), nil, nil
+	}))
+
+	for _, seqNum := range []uint16{10, 11, 12, 14, 16, 18} {
+		raw, marshalErr := (&rtp.Packet{Header: rtp.Header{SequenceNumber: seqNum, SSRC: 1}}).Marshal()
+		assert.NoError(t, marshalErr)
+		rtpIn <- raw
+
+		buf := make([]byte, 1500)
+		n, _, readErr := reader.Read(buf, nil)
+		assert.NoError(t, readErr)
+		assert.Equal(t, len(raw), n)
+	}
+
+	select {
+	case pkts := <-rtcpWritten:
+		assert.Equal(t, 1, len(pkts), "single packet RTCP Compound Packet expected")
+
+		p, ok := pkts[0].(*rtcp.TransportLayerNack)
+		assert.True(t, ok, "TransportLayerNack rtcp packet expected, found: %T", pkts[0])
+
+		assert.Equal(t, uint32(1), p.MediaSSRC)
+		assert.Equal(t, uint16(13), p.Nacks[0].PacketID)
+		assert.Equal(t, rtcp.PacketBitmap(0x2), p.Nacks[0].LostPackets) // we want packets: 13, 15 (not packet 17, because skipLastN is set to 2)
+	case <-time.After(time.Second):
+		t.Fatal("written rtcp packet not found")
+	}
+
+	assert.NoError(t, i.Close())
+}
+
+func TestGeneratorInterceptor_InvalidSize(t *testing.T) {
+	f, _ := NewGeneratorInterceptor(GeneratorSize(5))
+
+	_, err := f.NewInterceptor("")
+	assert.Error(t, err, ErrInvalidSize)
+}
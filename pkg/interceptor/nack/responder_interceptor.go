@@ -0,0 +1,175 @@
+package nack
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// ResponderInterceptorFactory is a interceptor.Factory for a ResponderInterceptor
+type ResponderInterceptorFactory struct {
+	opts []ResponderOption
+}
+
+// NewInterceptor constructs a new ResponderInterceptor
+func (r *ResponderInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &ResponderInterceptor{
+		size:    8192,
+		log:     logging.NewDefaultLoggerFactory().NewLogger("nack_responder"),
+		streams: map[uint32]*localStream{},
+	}
+
+	for _, opt := range r.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := newSendBuffer(i.size); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// NewResponderInterceptor returns a new ResponderInterceptorFactory
+func NewResponderInterceptor(opts ...ResponderOption) (*ResponderInterceptorFactory, error) {
+	return &ResponderInterceptorFactory{opts}, nil
+}
+
+// ResponderInterceptor responds to nack feedback messages
+type ResponderInterceptor struct {
+	interceptor.NoOp
+	size      uint16
+	log       logging.LeveledLogger
+	streams   map[uint32]*localStream
+	streamsMu sync.Mutex
+}
+
+type localStream struct {
+	sendBuffer *sendBuffer
+	rtpWriter  interceptor.RTPWriter
+
+	// Set if retransmissions are sent as a RTX stream
+	rtxSSRC           uint32
+	rtxPayloadType    uint8
+	rtxPayloadTypes   map[uint8]uint8
+	rtxSequenceNumber uint16
+	rtxMu             sync.Mutex
+}
+
+// BindRTCPReader lets you modify any incoming RTCP packets. It is called once per sender/receiver, however this might
+// change in the future. The returned method will be called once per packet batch.
+func (n *ResponderInterceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkts, err := rtcp.Unmarshal(b[:i])
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, rtcpPacket := range pkts {
+			nack, ok := rtcpPacket.(*rtcp.TransportLayerNack)
+			if !ok {
+				continue
+			}
+
+			go n.resendPackets(nack)
+		}
+
+		return i, attr, err
+	})
+}
+
+// BindLocalStream lets you modify any outgoing RTP packets. It is called once for per LocalStream. The returned method
+// will be called once per rtp packet.
+func (n *ResponderInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	if !streamSupportNack(info) {
+		return writer
+	}
+
+	// error is already checked in NewInterceptor
+	sendBuffer, _ := newSendBuffer(n.size)
+	n.streamsMu.Lock()
+	n.streams[info.SSRC] = &localStream{
+		sendBuffer:        sendBuffer,
+		rtpWriter:         writer,
+		rtxSSRC:           info.SSRCRetransmission,
+		rtxPayloadType:    info.PayloadTypeRetransmission,
+		rtxPayloadTypes:   info.PayloadTypesRetransmission,
+		rtxSequenceNumber: uint16(rand.Uint32()), // #nosec
+	}
+	n.streamsMu.Unlock()
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		// The caller owns payload and may reuse it, so keep a copy for retransmission
+		sendBuffer.add(&rtp.Packet{Header: *header, Payload: append([]byte{}, payload...)})
+		return writer.Write(header, payload, attributes)
+	})
+}
+
+// UnbindLocalStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (n *ResponderInterceptor) UnbindLocalStream(info *interceptor.StreamInfo) {
+	n.streamsMu.Lock()
+	delete(n.streams, info.SSRC)
+	n.streamsMu.Unlock()
+}
+
+func (n *ResponderInterceptor) resendPackets(nack *rtcp.TransportLayerNack) {
+	n.streamsMu.Lock()
+	stream, ok := n.streams[nack.MediaSSRC]
+	n.streamsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	for i := range nack.Nacks {
+		for _, seq := range nack.Nacks[i].PacketList() {
+			p := stream.sendBuffer.get(seq)
+			if p == nil {
+				continue
+			}
+
+			if err := stream.resend(p); err != nil {
+				n.log.Warnf("failed resending nacked packet: %+v", err)
+			}
+		}
+	}
+}
+
+// resend writes p again. If a RTX stream was negotiated p is sent in the RTX payload format,
+// with the original sequence number prepended to the payload. https://tools.ietf.org/html/rfc4588#section-4
+func (s *localStream) resend(p *rtp.Packet) error {
+	if s.rtxSSRC == 0 {
+		_, err := s.rtpWriter.Write(&p.Header, p.Payload, interceptor.Attributes{})
+		return err
+	}
+
+	s.rtxMu.Lock()
+	sequenceNumber := s.rtxSequenceNumber
+	s.rtxSequenceNumber++
+	s.rtxMu.Unlock()
+
+	header := p.Header
+	header.SSRC = s.rtxSSRC
+	header.PayloadType = s.rtxPayloadType
+	if rtxPayloadType, ok := s.rtxPayloadTypes[p.PayloadType]; ok {
+		header.PayloadType = rtxPayloadType
+	}
+	header.SequenceNumber = sequenceNumber
+
+	payload := make([]byte, 2+len(p.Payload))
+	binary.BigEndian.PutUint16(payload, p.SequenceNumber)
+	copy(payload[2:], p.Payload)
+
+	_, err := s.rtpWriter.Write(&header, payload, interceptor.Attributes{})
+	return err
+}
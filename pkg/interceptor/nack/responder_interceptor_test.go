@@ -0,0 +1,151 @@
+package nack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponderInterceptor(t *testing.T) {
+	f, err := NewResponderInterceptor(ResponderSize(8))
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	rtpWritten := make(chan uint16, 20)
+	writer := i.BindLocalStream(&interceptor.StreamInfo{
+		SSRC:         1,
+		RTCPFeedback: []interceptor.RTCPFeedback{{Type: "nack"}},
+	}, interceptor.RTPWriterFunc(func(header *rtp.Header, _ []byte, _ interceptor.Attributes) (int, error) {
+		rtpWritten <- header.SequenceNumber
+		return 0, nil
+	}))
+
+	for _, seqNum := range []uint16{10, 11, 12, 14, 15} {
+		_, writeErr := writer.Write(&rtp.Header{SequenceNumber: seqNum, SSRC: 1}, []byte{0x0}, nil)
+		assert.NoError(t, writeErr)
+
+		select {
+		case seq := <-rtpWritten:
+			assert.Equal(t, seqNum, seq)
+		case <-time.After(10 * time.Millisecond):
+			t.Fatal("written rtp packet not found")
+		}
+	}
+
+	rtcpIn := make(chan []byte, 1)
+	reader := i.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-rtcpIn), nil, nil
+	}))
+
+	raw, err := rtcp.Marshal([]rtcp.Packet{&rtcp.TransportLayerNack{
+		MediaSSRC:  1,
+		SenderSSRC: 2,
+		Nacks: []rtcp.NackPair{
+			{PacketID: 11, LostPackets: 0xb}, // sequence numbers: 11, 12, 13, 15
+		},
+	}})
+	assert.NoError(t, err)
+	rtcpIn <- raw
+
+	buf := make([]byte, 1500)
+	_, _, err = reader.Read(buf, nil)
+	assert.NoError(t, err)
+
+	// seq number 13 was never sent, so it can't be resent
+	for _, seqNum := range []uint16{11, 12, 15} {
+		select {
+		case seq := <-rtpWritten:
+			assert.Equal(t, seqNum, seq)
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("written rtp packet not found")
+		}
+	}
+
+	select {
+	case seq := <-rtpWritten:
+		t.Errorf("no more rtp packets expected, found sequence number: %v", seq)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.NoError(t, i.Close())
+}
+
+func TestResponderInterceptor_RTX(t *testing.T) {
+	f, err := NewResponderInterceptor(ResponderSize(8))
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	rtpWritten := make(chan *rtp.Packet, 20)
+	writer := i.BindLocalStream(&interceptor.StreamInfo{
+		SSRC:                      1,
+		PayloadType:               96,
+		SSRCRetransmission:        2,
+		PayloadTypeRetransmission: 97,
+		// The stream switches to a second codec, with its own RTX payload type
+		PayloadTypesRetransmission: map[uint8]uint8{96: 97, 98: 99},
+		RTCPFeedback:               []interceptor.RTCPFeedback{{Type: "nack"}},
+	}, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		rtpWritten <- &rtp.Packet{Header: *header, Payload: append([]byte{}, payload...)}
+		return 0, nil
+	}))
+
+	for _, seqNum := range []uint16{10, 11} {
+		_, writeErr := writer.Write(&rtp.Header{SequenceNumber: seqNum, SSRC: 1, PayloadType: 96}, []byte{0xaa, 0xbb}, nil)
+		assert.NoError(t, writeErr)
+		<-rtpWritten
+	}
+	_, err = writer.Write(&rtp.Header{SequenceNumber: 12, SSRC: 1, PayloadType: 98}, []byte{0xcc}, nil)
+	assert.NoError(t, err)
+	<-rtpWritten
+
+	rtcpIn := make(chan []byte, 1)
+	reader := i.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-rtcpIn), nil, nil
+	}))
+
+	raw, err := rtcp.Marshal([]rtcp.Packet{&rtcp.TransportLayerNack{
+		MediaSSRC:  1,
+		SenderSSRC: 3,
+		Nacks:      []rtcp.NackPair{{PacketID: 11, LostPackets: 1}},
+	}})
+	assert.NoError(t, err)
+	rtcpIn <- raw
+
+	_, _, err = reader.Read(make([]byte, 1500), nil)
+	assert.NoError(t, err)
+
+	select {
+	case p := <-rtpWritten:
+		assert.Equal(t, uint32(2), p.SSRC)
+		assert.Equal(t, uint8(97), p.PayloadType)
+		assert.Equal(t, []byte{0x00, 0x0b, 0xaa, 0xbb}, p.Payload) // original sequence number followed by the original payload
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("written rtx packet not found")
+	}
+
+	select {
+	case p := <-rtpWritten:
+		assert.Equal(t, uint32(2), p.SSRC)
+		assert.Equal(t, uint8(99), p.PayloadType)
+		assert.Equal(t, []byte{0x00, 0x0c, 0xcc}, p.Payload)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("written rtx packet not found")
+	}
+
+	assert.NoError(t, i.Close())
+}
+
+func TestResponderInterceptor_InvalidSize(t *testing.T) {
+	f, _ := NewResponderInterceptor(ResponderSize(5))
+
+	_, err := f.NewInterceptor("")
+	assert.Error(t, err, ErrInvalidSize)
+}
@@ -0,0 +1,87 @@
+package nack
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor/internal/sequence"
+)
+
+// sendBuffer is a ring buffer of the most recently sent packets for a single SSRC
+type sendBuffer struct {
+	packets   []*rtp.Packet
+	size      uint16
+	lastAdded uint16
+	started   bool
+
+	m sync.RWMutex
+}
+
+func newSendBuffer(size uint16) (*sendBuffer, error) {
+	allowedSizes := make([]uint16, 0)
+	correctSize := false
+	for i := uint(0); i < 16; i++ {
+		if size == 1<<i {
+			correctSize = true
+			break
+		}
+		allowedSizes = append(allowedSizes, 1<<i)
+	}
+
+	if !correctSize {
+		return nil, fmt.Errorf("%w: %d is not a valid size, allowed sizes: %v", ErrInvalidSize, size, allowedSizes)
+	}
+
+	return &sendBuffer{
+		packets: make([]*rtp.Packet, size),
+		size:    size,
+	}, nil
+}
+
+func (s *sendBuffer) add(packet *rtp.Packet) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	seq := packet.SequenceNumber
+	if !s.started {
+		s.packets[seq%s.size] = packet
+		s.lastAdded = seq
+		s.started = true
+		return
+	}
+
+	switch diff := seq - s.lastAdded; {
+	case diff == 0:
+		return
+	case diff < sequence.Uint16SizeHalf:
+		// seq is newer than lastAdded (accounting for rollover), drop
+		// anything that was skipped so it isn't confused with a previous lap
+		for i := s.lastAdded + 1; i != seq; i++ {
+			s.packets[i%s.size] = nil
+		}
+		s.lastAdded = seq
+	case s.lastAdded-seq >= s.size:
+		// Older than anything the buffer can hold
+		return
+	}
+
+	s.packets[seq%s.size] = packet
+}
+
+func (s *sendBuffer) get(seq uint16) *rtp.Packet {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	diff := s.lastAdded - seq
+	if diff >= sequence.Uint16SizeHalf || diff >= s.size {
+		return nil
+	}
+
+	pkt := s.packets[seq%s.size]
+	if pkt != nil && pkt.SequenceNumber != seq {
+		return nil
+	}
+
+	return pkt
+}
@@ -0,0 +1,208 @@
+package report
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// ReceiverInterceptorFactory is a interceptor.Factory for a ReceiverInterceptor
+type ReceiverInterceptorFactory struct {
+	opts []ReceiverOption
+}
+
+// NewInterceptor constructs a new ReceiverInterceptor
+func (r *ReceiverInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &ReceiverInterceptor{
+		interval: 1 * time.Second,
+		now:      time.Now,
+		streams:  map[uint32]*receiverStream{},
+		log:      logging.NewDefaultLoggerFactory().NewLogger("receiver_interceptor"),
+		close:    make(chan struct{}),
+	}
+
+	for _, opt := range r.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// NewReceiverInterceptor returns a new ReceiverInterceptorFactory
+func NewReceiverInterceptor(opts ...ReceiverOption) (*ReceiverInterceptorFactory, error) {
+	return &ReceiverInterceptorFactory{opts}, nil
+}
+
+// ReceiverInterceptor interceptor generates receiver reports.
+type ReceiverInterceptor struct {
+	interceptor.NoOp
+	interval time.Duration
+	now      func() time.Time
+	log      logging.LeveledLogger
+	m        sync.Mutex
+	wg       sync.WaitGroup
+	close    chan struct{}
+
+	rtcpWriter  interceptor.RTCPWriter
+	loopStarted bool
+
+	streams   map[uint32]*receiverStream
+	streamsMu sync.Mutex
+}
+
+// BindRTCPWriter lets you modify any outgoing RTCP packets. It is called once per PeerConnection. The returned method
+// will be called once per packet batch.
+func (r *ReceiverInterceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.rtcpWriter = writer
+	r.startLoop()
+
+	return writer
+}
+
+// BindRemoteStream lets you modify any incoming RTP packets. It is called once for per RemoteStream. The returned method
+// will be called once per rtp packet.
+func (r *ReceiverInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	stream := newReceiverStream(info.SSRC, info.ClockRate)
+	r.streamsMu.Lock()
+	r.streams[info.SSRC] = stream
+	r.streamsMu.Unlock()
+
+	r.m.Lock()
+	r.startLoop()
+	r.m.Unlock()
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		header := rtp.Header{}
+		if err = header.Unmarshal(b[:i]); err != nil {
+			return 0, nil, err
+		}
+		stream.processRTP(r.now(), &header)
+
+		return i, attr, nil
+	})
+}
+
+// UnbindRemoteStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (r *ReceiverInterceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	r.streamsMu.Lock()
+	delete(r.streams, info.SSRC)
+	r.streamsMu.Unlock()
+}
+
+// BindRTCPReader lets you modify any incoming RTCP packets. It is called once per sender/receiver, however this might
+// change in the future. The returned method will be called once per packet batch.
+// Sender Reports are used to fill LSR/DLSR, so they are only reported if the RTCP of the RTPReceiver is read.
+func (r *ReceiverInterceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkts, err := rtcp.Unmarshal(b[:i])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		for _, pkt := range pkts {
+			sr, ok := pkt.(*rtcp.SenderReport)
+			if !ok {
+				continue
+			}
+
+			r.streamsMu.Lock()
+			stream, ok := r.streams[sr.SSRC]
+			r.streamsMu.Unlock()
+			if ok {
+				stream.processSenderReport(r.now(), sr)
+			}
+		}
+
+		return i, attr, nil
+	})
+}
+
+// Close closes the interceptor.
+func (r *ReceiverInterceptor) Close() error {
+	defer r.wg.Wait()
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if !r.isClosed() {
+		close(r.close)
+	}
+
+	return nil
+}
+
+// startLoop starts sending reports once there is both a RTCPWriter and a stream to report on. r.m must be held.
+func (r *ReceiverInterceptor) startLoop() {
+	if r.loopStarted || r.rtcpWriter == nil || r.isClosed() {
+		return
+	}
+
+	r.streamsMu.Lock()
+	hasStreams := len(r.streams) != 0
+	r.streamsMu.Unlock()
+	if !hasStreams {
+		return
+	}
+
+	r.loopStarted = true
+	r.wg.Add(1)
+	go r.loop(r.rtcpWriter)
+}
+
+func (r *ReceiverInterceptor) loop(rtcpWriter interceptor.RTCPWriter) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := r.now()
+
+			r.streamsMu.Lock()
+			reports := make([]rtcp.Packet, 0, len(r.streams))
+			for _, stream := range r.streams {
+				if rr := stream.generateReport(now); rr != nil {
+					reports = append(reports, rr)
+				}
+			}
+			r.streamsMu.Unlock()
+
+			for _, rr := range reports {
+				if _, err := rtcpWriter.Write([]rtcp.Packet{rr}, interceptor.Attributes{}); err != nil {
+					r.log.Warnf("failed sending: %+v", err)
+				}
+			}
+
+		case <-r.close:
+			return
+		}
+	}
+}
+
+func (r *ReceiverInterceptor) isClosed() bool {
+	select {
+	case <-r.close:
+		return true
+	default:
+		return false
+	}
+}
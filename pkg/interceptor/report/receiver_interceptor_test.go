@@ -0,0 +1,209 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+type receiverTestEnv struct {
+	interceptor interceptor.Interceptor
+	rtpIn       chan []byte
+	rtcpIn      chan []byte
+	reader      interceptor.RTPReader
+	rtcpReader  interceptor.RTCPReader
+	rtcpWritten chan []rtcp.Packet
+	now         chan time.Time
+}
+
+func newReceiverTestEnv(t *testing.T, mNow time.Time) *receiverTestEnv {
+	env := &receiverTestEnv{
+		rtpIn:       make(chan []byte, 10),
+		rtcpIn:      make(chan []byte, 10),
+		rtcpWritten: make(chan []rtcp.Packet, 10),
+		now:         make(chan time.Time, 1),
+	}
+	env.now <- mNow
+
+	f, err := NewReceiverInterceptor(
+		ReceiverInterval(time.Millisecond*10),
+		ReceiverNow(func() time.Time {
+			t := <-env.now
+			env.now <- t
+			return t
+		}),
+	)
+	assert.NoError(t, err)
+
+	env.interceptor, err = f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	env.interceptor.BindRTCPWriter(interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+		env.rtcpWritten <- pkts
+		return 0, nil
+	}))
+	env.reader = env.interceptor.BindRemoteStream(&interceptor.StreamInfo{
+		SSRC:      123456,
+		ClockRate: 90000,
+	}, interceptor.RTPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-env.rtpIn), nil, nil
+	}))
+	env.rtcpReader = env.interceptor.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-env.rtcpIn), nil, nil
+	}))
+
+	return env
+}
+
+func (env *receiverTestEnv) setNow(now time.Time) {
+	<-env.now
+	env.now <- now
+}
+
+func (env *receiverTestEnv) receiveRTP(t *testing.T, seqNums ...uint16) {
+	for _, seqNum := range seqNums {
+		raw, err := (&rtp.Packet{Header: rtp.Header{SequenceNumber: seqNum, SSRC: 123456}}).Marshal()
+		assert.NoError(t, err)
+		env.rtpIn <- raw
+
+		_, _, err = env.reader.Read(make([]byte, 1500), nil)
+		assert.NoError(t, err)
+	}
+}
+
+// waitForReport skips reports that were generated before the last change
+func (env *receiverTestEnv) waitForReport(t *testing.T, match func(rtcp.ReceptionReport) bool) rtcp.ReceptionReport {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case pkts := <-env.rtcpWritten:
+			assert.Equal(t, 1, len(pkts))
+			rr, ok := pkts[0].(*rtcp.ReceiverReport)
+			assert.True(t, ok)
+			assert.Equal(t, 1, len(rr.Reports))
+			if ok && len(rr.Reports) == 1 && match(rr.Reports[0]) {
+				return rr.Reports[0]
+			}
+		case <-timeout:
+			t.Fatal("receiver report not found")
+			return rtcp.ReceptionReport{}
+		}
+	}
+}
+
+func TestReceiverInterceptor(t *testing.T) {
+	mNow := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	t.Run("no reports before the first packet", func(t *testing.T) {
+		env := newReceiverTestEnv(t, mNow)
+
+		select {
+		case pkts := <-env.rtcpWritten:
+			t.Fatalf("unexpected rtcp packets: %v", pkts)
+		case <-time.After(30 * time.Millisecond):
+		}
+
+		assert.NoError(t, env.interceptor.Close())
+	})
+
+	t.Run("after RTP packets", func(t *testing.T) {
+		env := newReceiverTestEnv(t, mNow)
+
+		env.receiveRTP(t, 0x01, 0x03, 0x04)
+		report := env.waitForReport(t, func(r rtcp.ReceptionReport) bool { return r.LastSequenceNumber == 0x04 })
+		assert.Equal(t, uint32(123456), report.SSRC)
+		assert.Equal(t, uint32(1), report.TotalLost)
+
+		assert.NoError(t, env.interceptor.Close())
+	})
+
+	t.Run("last sender report", func(t *testing.T) {
+		env := newReceiverTestEnv(t, mNow)
+		env.receiveRTP(t, 0x01)
+
+		raw, err := rtcp.Marshal([]rtcp.Packet{&rtcp.SenderReport{
+			SSRC:    123456,
+			NTPTime: 0xaaaabbbbccccdddd,
+		}})
+		assert.NoError(t, err)
+		env.rtcpIn <- raw
+		_, _, err = env.rtcpReader.Read(make([]byte, 1500), nil)
+		assert.NoError(t, err)
+
+		env.setNow(mNow.Add(time.Second))
+		report := env.waitForReport(t, func(r rtcp.ReceptionReport) bool { return r.Delay == 65536 })
+		assert.Equal(t, uint32(0xbbbbcccc), report.LastSenderReport)
+
+		assert.NoError(t, env.interceptor.Close())
+	})
+}
+
+func TestReceiverStream_Jitter(t *testing.T) {
+	mNow := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	stream := newReceiverStream(123456, 90000)
+
+	// Packets sent every 20ms, arriving every 20ms: no jitter
+	for i := 0; i < 10; i++ {
+		stream.processRTP(mNow.Add(time.Duration(i)*20*time.Millisecond), &rtp.Header{SequenceNumber: uint16(i), Timestamp: uint32(i * 1800)})
+	}
+	assert.Equal(t, uint32(0), stream.generateReport(mNow).Reports[0].Jitter)
+
+	// One packet arriving 10ms (900 RTP units) late
+	stream.processRTP(mNow.Add(210*time.Millisecond), &rtp.Header{SequenceNumber: 10, Timestamp: 10 * 1800})
+	assert.Equal(t, uint32(900/16), stream.generateReport(mNow).Reports[0].Jitter)
+}
+
+func TestReceiverStream_Loss(t *testing.T) {
+	mNow := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	receive := func(stream *receiverStream, seqNums ...uint16) {
+		for _, seqNum := range seqNums {
+			stream.processRTP(mNow, &rtp.Header{SequenceNumber: seqNum})
+		}
+	}
+
+	t.Run("lost packets", func(t *testing.T) {
+		stream := newReceiverStream(123456, 90000)
+
+		receive(stream, 0x01, 0x03, 0x04)
+		report := stream.generateReport(mNow).Reports[0]
+		assert.Equal(t, uint32(0x04), report.LastSequenceNumber)
+		assert.Equal(t, uint32(1), report.TotalLost)
+		assert.Equal(t, uint8(256*1/4), report.FractionLost)
+
+		// The fraction only covers the packets since the previous report
+		receive(stream, 0x05, 0x06, 0x07, 0x08)
+		report = stream.generateReport(mNow).Reports[0]
+		assert.Equal(t, uint32(0x08), report.LastSequenceNumber)
+		assert.Equal(t, uint32(1), report.TotalLost)
+		assert.Equal(t, uint8(0), report.FractionLost)
+	})
+
+	t.Run("sequence number wraparound", func(t *testing.T) {
+		stream := newReceiverStream(123456, 90000)
+
+		receive(stream, 0xfffe, 0xffff, 0x0001)
+		report := stream.generateReport(mNow).Reports[0]
+		assert.Equal(t, uint32(1<<16+0x0001), report.LastSequenceNumber)
+		assert.Equal(t, uint32(1), report.TotalLost)
+
+		// A late packet doesn't move the highest sequence number back
+		receive(stream, 0x0000)
+		report = stream.generateReport(mNow).Reports[0]
+		assert.Equal(t, uint32(1<<16+0x0001), report.LastSequenceNumber)
+		assert.Equal(t, uint32(0), report.TotalLost)
+	})
+	t.Run("packets received twice are counted once", func(t *testing.T) {
+		stream := newReceiverStream(123456, 90000)
+
+		// 0x02 is lost, 0x03 and 0x04 are received again when repaired after they arrived late
+		receive(stream, 0x01, 0x03, 0x04, 0x03, 0x04)
+		report := stream.generateReport(mNow).Reports[0]
+		assert.Equal(t, uint32(0x04), report.LastSequenceNumber)
+		assert.Equal(t, uint32(1), report.TotalLost)
+		assert.Equal(t, uint8(256*1/4), report.FractionLost)
+	})
+}
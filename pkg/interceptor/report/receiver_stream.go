@@ -0,0 +1,137 @@
+package report
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor/internal/sequence"
+)
+
+// receiverStream keeps the statistics of https://tools.ietf.org/html/rfc3550#appendix-A.1 for a single remote SSRC
+type receiverStream struct {
+	ssrc         uint32
+	receiverSSRC uint32
+	clockRate    float64
+
+	m sync.Mutex
+
+	// history skips the packets received twice, such as a packet repaired after its original arrived late
+	history              sequence.History
+	started              bool
+	baseSeq              uint32
+	cycles               uint32
+	maxSeq               uint16
+	received             uint32
+	expectedPrior        uint32
+	receivedPrior        uint32
+	lastTransit          float64
+	jitter               float64
+	lastSenderReport     uint32
+	lastSenderReportTime time.Time
+}
+
+func newReceiverStream(ssrc uint32, clockRate uint32) *receiverStream {
+	return &receiverStream{
+		ssrc:         ssrc,
+		receiverSSRC: rand.Uint32(), // #nosec
+		clockRate:    float64(clockRate),
+	}
+}
+
+func (stream *receiverStream) processRTP(now time.Time, header *rtp.Header) {
+	stream.m.Lock()
+	defer stream.m.Unlock()
+
+	if !stream.history.Add(header.SequenceNumber) {
+		return
+	}
+
+	if !stream.started {
+		stream.started = true
+		stream.baseSeq = uint32(header.SequenceNumber)
+		stream.maxSeq = header.SequenceNumber
+	} else if diff := header.SequenceNumber - stream.maxSeq; diff > 0 && diff < sequence.Uint16SizeHalf {
+		// in order, with permissible gap
+		if header.SequenceNumber < stream.maxSeq {
+			stream.cycles += 1 << 16
+		}
+		stream.maxSeq = header.SequenceNumber
+	}
+	stream.received++
+
+	// Interarrival jitter, https://tools.ietf.org/html/rfc3550#appendix-A.8
+	arrival := float64(now.UnixNano()) / float64(time.Second) * stream.clockRate
+	transit := arrival - float64(header.Timestamp)
+	if stream.received > 1 {
+		d := transit - stream.lastTransit
+		if d < 0 {
+			d = -d
+		}
+		stream.jitter += (d - stream.jitter) / 16
+	}
+	stream.lastTransit = transit
+}
+
+func (stream *receiverStream) processSenderReport(now time.Time, sr *rtcp.SenderReport) {
+	stream.m.Lock()
+	defer stream.m.Unlock()
+
+	// The middle 32 bits of the NTP timestamp
+	stream.lastSenderReport = uint32(sr.NTPTime >> 16)
+	stream.lastSenderReportTime = now
+}
+
+// generateReport returns nil until the first packet was received
+func (stream *receiverStream) generateReport(now time.Time) *rtcp.ReceiverReport {
+	stream.m.Lock()
+	defer stream.m.Unlock()
+
+	if !stream.started {
+		return nil
+	}
+
+	// https://tools.ietf.org/html/rfc3550#appendix-A.3
+	extendedMax := stream.cycles + uint32(stream.maxSeq)
+	expected := extendedMax - stream.baseSeq + 1
+
+	totalLost := uint32(0)
+	if expected > stream.received {
+		totalLost = expected - stream.received
+	}
+	// cumulative number of packets lost is a signed 24 bit value, clamp it
+	if totalLost > 0x7fffff {
+		totalLost = 0x7fffff
+	}
+
+	expectedInterval := expected - stream.expectedPrior
+	receivedInterval := stream.received - stream.receivedPrior
+	stream.expectedPrior = expected
+	stream.receivedPrior = stream.received
+
+	fractionLost := uint8(0)
+	if expectedInterval != 0 && expectedInterval > receivedInterval {
+		fractionLost = uint8(((expectedInterval - receivedInterval) << 8) / expectedInterval)
+	}
+
+	var delay uint32
+	if !stream.lastSenderReportTime.IsZero() {
+		// delay since last sender report in units of 1/65536 seconds
+		delay = uint32(now.Sub(stream.lastSenderReportTime).Seconds() * 65536)
+	}
+
+	return &rtcp.ReceiverReport{
+		SSRC: stream.receiverSSRC,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC:               stream.ssrc,
+			FractionLost:       fractionLost,
+			TotalLost:          totalLost,
+			LastSequenceNumber: extendedMax,
+			Jitter:             uint32(stream.jitter),
+			LastSenderReport:   stream.lastSenderReport,
+			Delay:              delay,
+		}},
+	}
+}
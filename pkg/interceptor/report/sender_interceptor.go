@@ -0,0 +1,166 @@
+package report
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// SenderInterceptorFactory is a interceptor.Factory for a SenderInterceptor
+type SenderInterceptorFactory struct {
+	opts []SenderOption
+}
+
+// NewInterceptor constructs a new SenderInterceptor
+func (s *SenderInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &SenderInterceptor{
+		interval: 1 * time.Second,
+		now:      time.Now,
+		streams:  map[uint32]*senderStream{},
+		log:      logging.NewDefaultLoggerFactory().NewLogger("sender_interceptor"),
+		close:    make(chan struct{}),
+	}
+
+	for _, opt := range s.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// NewSenderInterceptor returns a new SenderInterceptorFactory
+func NewSenderInterceptor(opts ...SenderOption) (*SenderInterceptorFactory, error) {
+	return &SenderInterceptorFactory{opts}, nil
+}
+
+// SenderInterceptor interceptor generates sender reports.
+type SenderInterceptor struct {
+	interceptor.NoOp
+	interval time.Duration
+	now      func() time.Time
+	log      logging.LeveledLogger
+	m        sync.Mutex
+	wg       sync.WaitGroup
+	close    chan struct{}
+
+	rtcpWriter  interceptor.RTCPWriter
+	loopStarted bool
+
+	streams   map[uint32]*senderStream
+	streamsMu sync.Mutex
+}
+
+// BindRTCPWriter lets you modify any outgoing RTCP packets. It is called once per PeerConnection. The returned method
+// will be called once per packet batch.
+func (s *SenderInterceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.rtcpWriter = writer
+	s.startLoop()
+
+	return writer
+}
+
+// BindLocalStream lets you modify any outgoing RTP packets. It is called once for per LocalStream. The returned method
+// will be called once per rtp packet.
+func (s *SenderInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	stream := newSenderStream(info.SSRC, info.ClockRate)
+	s.streamsMu.Lock()
+	s.streams[info.SSRC] = stream
+	s.streamsMu.Unlock()
+
+	s.m.Lock()
+	s.startLoop()
+	s.m.Unlock()
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, a interceptor.Attributes) (int, error) {
+		stream.processRTP(s.now(), header, payload)
+
+		return writer.Write(header, payload, a)
+	})
+}
+
+// UnbindLocalStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (s *SenderInterceptor) UnbindLocalStream(info *interceptor.StreamInfo) {
+	s.streamsMu.Lock()
+	delete(s.streams, info.SSRC)
+	s.streamsMu.Unlock()
+}
+
+// Close closes the interceptor.
+func (s *SenderInterceptor) Close() error {
+	defer s.wg.Wait()
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !s.isClosed() {
+		close(s.close)
+	}
+
+	return nil
+}
+
+// startLoop starts sending reports once there is both a RTCPWriter and a stream to report on. s.m must be held.
+func (s *SenderInterceptor) startLoop() {
+	if s.loopStarted || s.rtcpWriter == nil || s.isClosed() {
+		return
+	}
+
+	s.streamsMu.Lock()
+	hasStreams := len(s.streams) != 0
+	s.streamsMu.Unlock()
+	if !hasStreams {
+		return
+	}
+
+	s.loopStarted = true
+	s.wg.Add(1)
+	go s.loop(s.rtcpWriter)
+}
+
+func (s *SenderInterceptor) loop(rtcpWriter interceptor.RTCPWriter) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := s.now()
+
+			s.streamsMu.Lock()
+			reports := make([]rtcp.Packet, 0, len(s.streams))
+			for _, stream := range s.streams {
+				if sr := stream.generateReport(now); sr != nil {
+					reports = append(reports, sr)
+				}
+			}
+			s.streamsMu.Unlock()
+
+			for _, sr := range reports {
+				if _, err := rtcpWriter.Write([]rtcp.Packet{sr}, interceptor.Attributes{}); err != nil {
+					s.log.Warnf("failed sending: %+v", err)
+				}
+			}
+
+		case <-s.close:
+			return
+		}
+	}
+}
+
+func (s *SenderInterceptor) isClosed() bool {
+	select {
+	case <-s.close:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/internal/ntp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSenderInterceptor(t *testing.T) {
+	mNow := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	now := make(chan time.Time, 1)
+	now <- mNow
+	mockNow := func() time.Time {
+		t := <-now
+		now <- t
+		return t
+	}
+
+	f, err := NewSenderInterceptor(
+		SenderInterval(time.Millisecond*10),
+		SenderNow(mockNow),
+	)
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	rtcpWritten := make(chan []rtcp.Packet, 10)
+	i.BindRTCPWriter(interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+		rtcpWritten <- pkts
+		return 0, nil
+	}))
+
+	writer := i.BindLocalStream(&interceptor.StreamInfo{
+		SSRC:      123456,
+		ClockRate: 90000,
+	}, interceptor.RTPWriterFunc(func(_ *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		return len(payload), nil
+	}))
+
+	t.Run("no reports before the first packet", func(t *testing.T) {
+		select {
+		case pkts := <-rtcpWritten:
+			t.Fatalf("unexpected rtcp packets: %v", pkts)
+		case <-time.After(30 * time.Millisecond):
+		}
+	})
+
+	t.Run("after RTP packets", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			_, err := writer.Write(&rtp.Header{SequenceNumber: uint16(i), Timestamp: 1000}, []byte("\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09"), nil)
+			assert.NoError(t, err)
+		}
+
+		// One second later the RTP timestamp has moved by the clock rate
+		<-now
+		now <- mNow.Add(time.Second)
+
+		sr := waitForReport(t, rtcpWritten, ntp.FromTime(mNow.Add(time.Second)))
+		assert.Equal(t, &rtcp.SenderReport{
+			SSRC:        123456,
+			NTPTime:     ntp.FromTime(mNow.Add(time.Second)),
+			RTPTime:     1000 + 90000,
+			PacketCount: 10,
+			OctetCount:  100,
+		}, sr)
+	})
+
+	assert.NoError(t, i.Close())
+}
+
+// waitForReport skips reports that were generated before the clock was moved
+func waitForReport(t *testing.T, c chan []rtcp.Packet, ntpTime uint64) *rtcp.SenderReport {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case pkts := <-c:
+			assert.Equal(t, 1, len(pkts))
+			sr, ok := pkts[0].(*rtcp.SenderReport)
+			assert.True(t, ok)
+			if ok && sr.NTPTime == ntpTime {
+				return sr
+			}
+		case <-timeout:
+			t.Fatal("sender report not found")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// InterceptorFactory is a interceptor.Factory for a Interceptor
+type InterceptorFactory struct {
+	opts []Option
+}
+
+// NewInterceptor constructs a new Interceptor
+func (f *InterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &Interceptor{
+		now:           time.Now,
+		localStreams:  map[uint32]*outboundStream{},
+		remoteStreams: map[uint32]*inboundStream{},
+	}
+
+	for _, opt := range f.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// NewInterceptor returns a new InterceptorFactory
+func NewInterceptor(opts ...Option) (*InterceptorFactory, error) {
+	return &InterceptorFactory{opts}, nil
+}
+
+// Interceptor records the statistics of the local and remote streams. It must be the first interceptor
+// of the chain, the closest to the network, to record the packets as they are sent and received.
+// The RTCP of the remote is only recorded if the RTCP of the RTPSenders and RTPReceivers is read.
+type Interceptor struct {
+	interceptor.NoOp
+	now func() time.Time
+
+	mu            sync.Mutex
+	localStreams  map[uint32]*outboundStream
+	remoteStreams map[uint32]*inboundStream
+}
+
+// GetOutboundStats returns the statistics of the local stream with the given SSRC
+func (i *Interceptor) GetOutboundStats(ssrc uint32) (OutboundStats, bool) {
+	stream, ok := i.localStream(ssrc)
+	if !ok {
+		return OutboundStats{}, false
+	}
+
+	return stream.getStats(), true
+}
+
+// GetInboundStats returns the statistics of the remote stream with the given SSRC
+func (i *Interceptor) GetInboundStats(ssrc uint32) (InboundStats, bool) {
+	stream, ok := i.remoteStream(ssrc)
+	if !ok {
+		return InboundStats{}, false
+	}
+
+	return stream.getStats(), true
+}
+
+// BindRTCPReader lets you modify any incoming RTCP packets. It is called once per sender/receiver, however this might
+// change in the future. The returned method will be called once per packet batch.
+func (i *Interceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkts, err := rtcp.Unmarshal(b[:n])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		now := i.now()
+		for _, pkt := range pkts {
+			switch pkt := pkt.(type) {
+			case *rtcp.SenderReport:
+				if stream, ok := i.remoteStream(pkt.SSRC); ok {
+					stream.processSenderReport(now, pkt)
+				}
+				i.processReceptionReports(now, pkt.Reports)
+			case *rtcp.ReceiverReport:
+				i.processReceptionReports(now, pkt.Reports)
+			case *rtcp.TransportLayerNack, *rtcp.PictureLossIndication, *rtcp.FullIntraRequest:
+				for _, ssrc := range pkt.DestinationSSRC() {
+					if stream, ok := i.localStream(ssrc); ok {
+						stream.processFeedback(pkt)
+					}
+				}
+			}
+		}
+
+		return n, attr, nil
+	})
+}
+
+// BindRTCPWriter lets you modify any outgoing RTCP packets. It is called once per PeerConnection. The returned method
+// will be called once per packet batch.
+func (i *Interceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	return interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, attributes interceptor.Attributes) (int, error) {
+		for _, pkt := range pkts {
+			switch pkt.(type) {
+			case *rtcp.TransportLayerNack, *rtcp.PictureLossIndication, *rtcp.FullIntraRequest:
+				for _, ssrc := range pkt.DestinationSSRC() {
+					if stream, ok := i.remoteStream(ssrc); ok {
+						stream.processFeedback(pkt)
+					}
+				}
+			}
+		}
+
+		return writer.Write(pkts, attributes)
+	})
+}
+
+// BindLocalStream lets you modify any outgoing RTP packets. It is called once for per LocalStream. The returned method
+// will be called once per rtp packet.
+func (i *Interceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	stream := newOutboundStream(info)
+	i.mu.Lock()
+	i.localStreams[info.SSRC] = stream
+	i.mu.Unlock()
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		n, err := writer.Write(header, payload, attributes)
+		if err == nil {
+			stream.processRTP(i.now(), header, payload)
+		}
+
+		return n, err
+	})
+}
+
+// UnbindLocalStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (i *Interceptor) UnbindLocalStream(info *interceptor.StreamInfo) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.localStreams, info.SSRC)
+}
+
+// BindRemoteStream lets you modify any incoming RTP packets. It is called once for per RemoteStream. The returned method
+// will be called once per rtp packet.
+func (i *Interceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	stream := newInboundStream(info)
+	i.mu.Lock()
+	i.remoteStreams[info.SSRC] = stream
+	i.mu.Unlock()
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		header := rtp.Header{}
+		if err = header.Unmarshal(b[:n]); err != nil {
+			return 0, nil, err
+		}
+		stream.processRTP(i.now(), &header, b[header.PayloadOffset:n])
+
+		return n, attr, nil
+	})
+}
+
+// UnbindRemoteStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (i *Interceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.remoteStreams, info.SSRC)
+}
+
+func (i *Interceptor) processReceptionReports(now time.Time, reports []rtcp.ReceptionReport) {
+	for _, report := range reports {
+		if stream, ok := i.localStream(report.SSRC); ok {
+			stream.processReceptionReport(now, report)
+		}
+	}
+}
+
+func (i *Interceptor) localStream(ssrc uint32) (*outboundStream, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	stream, ok := i.localStreams[ssrc]
+	return stream, ok
+}
+
+func (i *Interceptor) remoteStream(ssrc uint32) (*inboundStream, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	stream, ok := i.remoteStreams[ssrc]
+	return stream, ok
+}
@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/internal/ntp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestInterceptor(t *testing.T, now *time.Time) *Interceptor {
+	f, err := NewInterceptor(Now(func() time.Time { return *now }))
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+	return i.(*Interceptor)
+}
+
+func rtcpReader(t *testing.T, i *Interceptor, pkts ...rtcp.Packet) {
+	raw, err := rtcp.Marshal(pkts)
+	assert.NoError(t, err)
+
+	reader := i.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, raw), a, nil
+	}))
+	_, _, err = reader.Read(make([]byte, 1500), interceptor.Attributes{})
+	assert.NoError(t, err)
+}
+
+func TestInterceptor_Outbound(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	i := newTestInterceptor(t, &now)
+
+	info := &interceptor.StreamInfo{SSRC: 1000, ClockRate: 90000, MimeType: "video/VP8", SSRCForwardErrorCorrection: 2000}
+	writer := i.BindLocalStream(info, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		return header.MarshalSize() + len(payload), nil
+	}))
+
+	_, ok := i.GetOutboundStats(1001)
+	assert.False(t, ok)
+
+	for _, p := range []struct {
+		header  rtp.Header
+		payload []byte
+	}{
+		{rtp.Header{Version: 2, SSRC: 1000, SequenceNumber: 1}, make([]byte, 100)},
+		{rtp.Header{Version: 2, SSRC: 1000, SequenceNumber: 2, Marker: true}, make([]byte, 50)},
+		{rtp.Header{Version: 2, SSRC: 1000, SequenceNumber: 3, Marker: true, Padding: true}, append(make([]byte, 10), 0, 0, 3)},
+		{rtp.Header{Version: 2, SSRC: 2000, SequenceNumber: 1}, make([]byte, 20)},
+	} {
+		p := p
+		_, err := writer.Write(&p.header, p.payload, interceptor.Attributes{})
+		assert.NoError(t, err)
+	}
+
+	rtcpReader(t, i,
+		&rtcp.TransportLayerNack{MediaSSRC: 1000, Nacks: []rtcp.NackPair{{PacketID: 1}}},
+		&rtcp.PictureLossIndication{MediaSSRC: 1000},
+		&rtcp.PictureLossIndication{MediaSSRC: 1001},
+		&rtcp.FullIntraRequest{FIR: []rtcp.FIREntry{{SSRC: 1000}}},
+	)
+
+	// The remote got the Sender Report sent 1s ago 250ms after it was sent
+	lastSenderReport := uint32(ntp.FromTime(now.Add(-time.Second)) >> 16)
+	rtcpReader(t, i, &rtcp.ReceiverReport{SSRC: 5000, Reports: []rtcp.ReceptionReport{{
+		SSRC:             1000,
+		FractionLost:     64,
+		TotalLost:        0xffffff,
+		Jitter:           9000,
+		LastSenderReport: lastSenderReport,
+		Delay:            65536 / 4,
+	}}})
+
+	stats, ok := i.GetOutboundStats(1000)
+	assert.True(t, ok)
+	assert.Equal(t, OutboundStats{
+		PacketsSent:     3,
+		BytesSent:       160,
+		HeaderBytesSent: 39,
+		FECPacketsSent:  1,
+		FramesSent:      2,
+		LastPacketSent:  now,
+		NACKCount:       1,
+		PLICount:        1,
+		FIRCount:        1,
+		RemoteInbound: RemoteInboundStats{
+			ReportsReceived: 1,
+			Timestamp:       now,
+			PacketsLost:     -1,
+			FractionLost:    0.25,
+			Jitter:          0.1,
+			RoundTripTime:   750 * time.Millisecond,
+		},
+	}, stats)
+
+	i.UnbindLocalStream(info)
+	_, ok = i.GetOutboundStats(1000)
+	assert.False(t, ok)
+}
+
+func TestInterceptor_OutboundULPFEC(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	i := newTestInterceptor(t, &now)
+
+	info := &interceptor.StreamInfo{SSRC: 1000, ClockRate: 90000, MimeType: "video/VP8", MimeTypeForwardErrorCorrection: "video/ulpfec", PayloadTypeForwardErrorCorrection: 116, PayloadTypeRED: 114}
+	writer := i.BindLocalStream(info, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		return header.MarshalSize() + len(payload), nil
+	}))
+
+	// The media and the ULPFEC packets are both sent inside RED
+	for _, payload := range [][]byte{{96, 0x01}, {116, 0x02}, {96, 0x03}} {
+		_, err := writer.Write(&rtp.Header{Version: 2, SSRC: 1000, PayloadType: 114}, payload, interceptor.Attributes{})
+		assert.NoError(t, err)
+	}
+
+	stats, ok := i.GetOutboundStats(1000)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(2), stats.PacketsSent)
+	assert.Equal(t, uint32(1), stats.FECPacketsSent)
+
+	assert.NoError(t, i.Close())
+}
+
+func TestInterceptor_Inbound(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	i := newTestInterceptor(t, &now)
+
+	packets := make(chan []byte, 10)
+	info := &interceptor.StreamInfo{SSRC: 1000, ClockRate: 48000, MimeType: "audio/opus"}
+	reader := i.BindRemoteStream(info, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-packets), a, nil
+	}))
+
+	// 65535 is lost, 1 arrives 20ms late
+	for _, p := range []struct {
+		sequenceNumber uint16
+		timestamp      uint32
+		arrival        time.Duration
+	}{
+		{65533, 0, 0},
+		{65534, 960, 20 * time.Millisecond},
+		{0, 2880, 60 * time.Millisecond},
+		{1, 3840, 100 * time.Millisecond},
+	} {
+		raw, err := (&rtp.Packet{Header: rtp.Header{Version: 2, SSRC: 1000, SequenceNumber: p.sequenceNumber, Timestamp: p.timestamp}, Payload: make([]byte, 40)}).Marshal()
+		assert.NoError(t, err)
+		packets <- raw
+
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Add(p.arrival)
+		_, _, err = reader.Read(make([]byte, 1500), interceptor.Attributes{})
+		assert.NoError(t, err)
+	}
+
+	writer := i.BindRTCPWriter(interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, attributes interceptor.Attributes) (int, error) {
+		return 0, nil
+	}))
+	_, err := writer.Write([]rtcp.Packet{
+		&rtcp.TransportLayerNack{MediaSSRC: 1000, Nacks: []rtcp.NackPair{{PacketID: 65535}}},
+		&rtcp.PictureLossIndication{MediaSSRC: 1000},
+	}, interceptor.Attributes{})
+	assert.NoError(t, err)
+
+	remoteTimestamp := time.Unix(1609459201, 0)
+	rtcpReader(t, i, &rtcp.SenderReport{SSRC: 1000, NTPTime: ntp.FromTime(remoteTimestamp), PacketCount: 5, OctetCount: 200})
+
+	stats, ok := i.GetInboundStats(1000)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.020/16, stats.Jitter, 1e-9)
+	stats.Jitter = 0
+	assert.Equal(t, InboundStats{
+		PacketsReceived:     4,
+		BytesReceived:       160,
+		HeaderBytesReceived: 48,
+		PacketsLost:         1,
+		LastPacketReceived:  now,
+		NACKCount:           1,
+		PLICount:            1,
+		RemoteOutbound: RemoteOutboundStats{
+			ReportsReceived: 1,
+			Timestamp:       now,
+			RemoteTimestamp: remoteTimestamp,
+			PacketsSent:     5,
+			BytesSent:       200,
+		},
+	}, stats)
+
+	i.UnbindRemoteStream(info)
+	_, ok = i.GetInboundStats(1000)
+	assert.False(t, ok)
+}
+
+func TestInboundStream_ReceivedTwice(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	stream := newInboundStream(&interceptor.StreamInfo{SSRC: 1000, ClockRate: 90000, MimeType: "video/vp8"})
+
+	// 2 is lost, 3 and 4 are received again when repaired after they arrived late
+	for _, sequenceNumber := range []uint16{1, 3, 4, 3, 4} {
+		stream.processRTP(now, &rtp.Header{SSRC: 1000, SequenceNumber: sequenceNumber, Marker: true}, make([]byte, 10))
+	}
+
+	stats := stream.getStats()
+	assert.Equal(t, uint32(3), stats.PacketsReceived)
+	assert.Equal(t, uint64(30), stats.BytesReceived)
+	assert.Equal(t, uint32(3), stats.FramesReceived)
+	assert.Equal(t, int32(1), stats.PacketsLost)
+}
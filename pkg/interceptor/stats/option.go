@@ -0,0 +1,14 @@
+package stats
+
+import "time"
+
+// Option can be used to configure Interceptor.
+type Option func(i *Interceptor) error
+
+// Now sets an alternative for the time.Now function.
+func Now(f func() time.Time) Option {
+	return func(i *Interceptor) error {
+		i.now = f
+		return nil
+	}
+}
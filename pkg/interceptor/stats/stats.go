@@ -0,0 +1,95 @@
+// Package stats provides an interceptor recording the statistics of the RTP streams of a PeerConnection:
+// the packets sent and received, the RTCP feedback exchanged about them and the reports of the remote.
+package stats
+
+import "time"
+
+// OutboundStats are the statistics of a local stream
+type OutboundStats struct {
+	PacketsSent uint32
+	BytesSent   uint64
+
+	// HeaderBytesSent is the size of the RTP headers and padding of the packets sent
+	HeaderBytesSent uint64
+
+	// FECPacketsSent is the number of FEC packets sent to protect the stream, on their own stream
+	// with FlexFEC, or inside RED in the stream itself with ULPFEC
+	FECPacketsSent uint32
+
+	// FramesSent is the number of video frames sent, the number of packets with the marker bit
+	FramesSent uint32
+
+	LastPacketSent time.Time
+
+	// NACKCount, PLICount and FIRCount are the number of feedback packets received about the stream
+	NACKCount uint32
+	PLICount  uint32
+	FIRCount  uint32
+
+	// RemoteInbound is the last report of the remote about the stream
+	RemoteInbound RemoteInboundStats
+}
+
+// RemoteInboundStats are the statistics of a local stream, measured by the remote and
+// received in RTCP Receiver or Sender Reports
+type RemoteInboundStats struct {
+	// ReportsReceived is zero until a report was received
+	ReportsReceived uint32
+
+	// Timestamp is the time the last report was received at
+	Timestamp time.Time
+
+	PacketsLost  int32
+	FractionLost float64
+
+	// Jitter is in seconds
+	Jitter float64
+
+	// RoundTripTime is zero until a report referring to a Sender Report of the stream was received
+	RoundTripTime time.Duration
+}
+
+// InboundStats are the statistics of a remote stream
+type InboundStats struct {
+	PacketsReceived uint32
+	BytesReceived   uint64
+
+	// HeaderBytesReceived is the size of the RTP headers and padding of the packets received
+	HeaderBytesReceived uint64
+
+	// PacketsLost is the number of packets expected minus the number of packets received.
+	// Packets received twice, such as a packet repaired after its original arrived late,
+	// are only counted once
+	PacketsLost int32
+
+	// Jitter is the interarrival jitter, in seconds
+	Jitter float64
+
+	// FramesReceived is the number of video frames received, the number of packets with the marker bit
+	FramesReceived uint32
+
+	LastPacketReceived time.Time
+
+	// NACKCount, PLICount and FIRCount are the number of feedback packets sent about the stream
+	NACKCount uint32
+	PLICount  uint32
+	FIRCount  uint32
+
+	// RemoteOutbound is the last Sender Report of the remote for the stream
+	RemoteOutbound RemoteOutboundStats
+}
+
+// RemoteOutboundStats are the statistics of a remote stream, as sent by the remote in RTCP Sender Reports
+type RemoteOutboundStats struct {
+	// ReportsReceived is zero until a report was received
+	ReportsReceived uint32
+
+	// Timestamp is the time the last report was received at
+	Timestamp time.Time
+
+	// RemoteTimestamp is the time the last report was sent at, on the clock of the remote
+	RemoteTimestamp time.Time
+
+	PacketsSent uint32
+	BytesSent   uint64
+}
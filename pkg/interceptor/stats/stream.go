@@ -0,0 +1,238 @@
+package stats
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/internal/ntp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/internal/sequence"
+)
+
+// outboundStream records the statistics of a local stream
+type outboundStream struct {
+	ssrc      uint32
+	fecSSRC   uint32
+	clockRate float64
+	video     bool
+
+	// ULPFEC packets are sent inside RED in the stream itself, with the payload type ulpfecPayloadType
+	redPayloadType    uint8
+	ulpfecPayloadType uint8
+
+	mu    sync.Mutex
+	stats OutboundStats
+}
+
+func newOutboundStream(info *interceptor.StreamInfo) *outboundStream {
+	s := &outboundStream{
+		ssrc:      info.SSRC,
+		fecSSRC:   info.SSRCForwardErrorCorrection,
+		clockRate: float64(info.ClockRate),
+		video:     strings.HasPrefix(strings.ToLower(info.MimeType), "video/"),
+	}
+	if strings.EqualFold(info.MimeTypeForwardErrorCorrection, "video/ulpfec") {
+		s.redPayloadType, s.ulpfecPayloadType = info.PayloadTypeRED, info.PayloadTypeForwardErrorCorrection
+	}
+
+	return s
+}
+
+func (s *outboundStream) processRTP(now time.Time, header *rtp.Header, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if header.SSRC == s.fecSSRC && s.fecSSRC != 0 {
+		s.stats.FECPacketsSent++
+		return
+	}
+	if header.SSRC != s.ssrc {
+		return
+	}
+	if s.isULPFEC(header, payload) {
+		s.stats.FECPacketsSent++
+		return
+	}
+
+	payloadSize := payloadSize(header, payload)
+	s.stats.PacketsSent++
+	s.stats.BytesSent += uint64(payloadSize)
+	s.stats.HeaderBytesSent += uint64(header.MarshalSize() + len(payload) - payloadSize)
+	s.stats.LastPacketSent = now
+	if s.video && header.Marker {
+		s.stats.FramesSent++
+	}
+}
+
+// isULPFEC returns true if the packet is a RED packet whose only block is a ULPFEC packet
+func (s *outboundStream) isULPFEC(header *rtp.Header, payload []byte) bool {
+	return s.redPayloadType != 0 && header.PayloadType == s.redPayloadType && len(payload) != 0 && payload[0] == s.ulpfecPayloadType
+}
+
+func (s *outboundStream) processFeedback(pkt rtcp.Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	countFeedback(pkt, &s.stats.NACKCount, &s.stats.PLICount, &s.stats.FIRCount)
+}
+
+// processReceptionReport records a report block of a Receiver or Sender Report of the remote
+func (s *outboundStream) processReceptionReport(now time.Time, report rtcp.ReceptionReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remote := &s.stats.RemoteInbound
+	remote.ReportsReceived++
+	remote.Timestamp = now
+
+	// The cumulative number of packets lost is a signed 24 bit value
+	remote.PacketsLost = int32(report.TotalLost<<8) >> 8
+	remote.FractionLost = float64(report.FractionLost) / 256
+	if s.clockRate != 0 {
+		remote.Jitter = float64(report.Jitter) / s.clockRate
+	}
+
+	// https://tools.ietf.org/html/rfc3550#section-6.4.1, in units of 1/65536 seconds
+	if report.LastSenderReport != 0 {
+		rtt := uint32(ntp.FromTime(now)>>16) - report.LastSenderReport - report.Delay
+		if int32(rtt) >= 0 {
+			remote.RoundTripTime = time.Duration(rtt) * time.Second / 65536
+		}
+	}
+}
+
+func (s *outboundStream) getStats() OutboundStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
+
+// inboundStream records the statistics of a remote stream,
+// following https://tools.ietf.org/html/rfc3550#appendix-A.1
+type inboundStream struct {
+	ssrc      uint32
+	clockRate float64
+	video     bool
+
+	mu sync.Mutex
+
+	// history skips the packets received twice, such as a packet repaired after its original arrived late
+	history     sequence.History
+	started     bool
+	baseSeq     uint32
+	cycles      uint32
+	maxSeq      uint16
+	lastTransit float64
+	jitter      float64
+	stats       InboundStats
+}
+
+func newInboundStream(info *interceptor.StreamInfo) *inboundStream {
+	return &inboundStream{
+		ssrc:      info.SSRC,
+		clockRate: float64(info.ClockRate),
+		video:     strings.HasPrefix(strings.ToLower(info.MimeType), "video/"),
+	}
+}
+
+func (s *inboundStream) processRTP(now time.Time, header *rtp.Header, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.history.Add(header.SequenceNumber) {
+		return
+	}
+
+	if !s.started {
+		s.started = true
+		s.baseSeq = uint32(header.SequenceNumber)
+		s.maxSeq = header.SequenceNumber
+	} else if diff := header.SequenceNumber - s.maxSeq; diff > 0 && diff < sequence.Uint16SizeHalf {
+		// in order, with permissible gap
+		if header.SequenceNumber < s.maxSeq {
+			s.cycles += 1 << 16
+		}
+		s.maxSeq = header.SequenceNumber
+	}
+
+	payloadSize := payloadSize(header, payload)
+	s.stats.PacketsReceived++
+	s.stats.BytesReceived += uint64(payloadSize)
+	s.stats.HeaderBytesReceived += uint64(header.PayloadOffset + len(payload) - payloadSize)
+	s.stats.LastPacketReceived = now
+	if s.video && header.Marker {
+		s.stats.FramesReceived++
+	}
+
+	// Interarrival jitter, https://tools.ietf.org/html/rfc3550#appendix-A.8
+	arrival := float64(now.UnixNano()) / float64(time.Second) * s.clockRate
+	transit := arrival - float64(header.Timestamp)
+	if s.stats.PacketsReceived > 1 {
+		d := transit - s.lastTransit
+		if d < 0 {
+			d = -d
+		}
+		s.jitter += (d - s.jitter) / 16
+	}
+	s.lastTransit = transit
+}
+
+func (s *inboundStream) processFeedback(pkt rtcp.Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	countFeedback(pkt, &s.stats.NACKCount, &s.stats.PLICount, &s.stats.FIRCount)
+}
+
+func (s *inboundStream) processSenderReport(now time.Time, sr *rtcp.SenderReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remote := &s.stats.RemoteOutbound
+	remote.ReportsReceived++
+	remote.Timestamp = now
+	remote.RemoteTimestamp = ntp.ToTime(sr.NTPTime)
+	remote.PacketsSent = sr.PacketCount
+	remote.BytesSent = uint64(sr.OctetCount)
+}
+
+func (s *inboundStream) getStats() InboundStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	if s.started {
+		expected := s.cycles + uint32(s.maxSeq) - s.baseSeq + 1
+		stats.PacketsLost = int32(expected - stats.PacketsReceived)
+	}
+	if s.clockRate != 0 {
+		stats.Jitter = s.jitter / s.clockRate
+	}
+
+	return stats
+}
+
+// payloadSize returns the size of the payload of a packet without its padding
+func payloadSize(header *rtp.Header, payload []byte) int {
+	if header.Padding && len(payload) != 0 && int(payload[len(payload)-1]) <= len(payload) {
+		return len(payload) - int(payload[len(payload)-1])
+	}
+
+	return len(payload)
+}
+
+// countFeedback increments the counter of the kind of feedback pkt is
+func countFeedback(pkt rtcp.Packet, nackCount, pliCount, firCount *uint32) {
+	switch pkt.(type) {
+	case *rtcp.TransportLayerNack:
+		*nackCount++
+	case *rtcp.PictureLossIndication:
+		*pliCount++
+	case *rtcp.FullIntraRequest:
+		*firCount++
+	}
+}
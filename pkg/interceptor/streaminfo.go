@@ -0,0 +1,54 @@
+package interceptor
+
+// RTPHeaderExtension represents a negotiated RFC5285 RTP header extension.
+type RTPHeaderExtension struct {
+	URI string
+	ID  int
+}
+
+// StreamInfo is the Context passed when a StreamLocal or StreamRemote has been Binded or Unbinded
+type StreamInfo struct {
+	ID                  string
+	Attributes          Attributes
+	SSRC                uint32
+	PayloadType         uint8
+	RTPHeaderExtensions []RTPHeaderExtension
+	MimeType            string
+	ClockRate           uint32
+	Channels            uint16
+	SDPFmtpLine         string
+	RTCPFeedback        []RTCPFeedback
+
+	// SSRCRetransmission and PayloadTypeRetransmission describe the RTX (RFC 4588)
+	// stream for this stream. They are zero if RTX wasn't negotiated.
+	SSRCRetransmission        uint32
+	PayloadTypeRetransmission uint8
+
+	// PayloadTypesRetransmission maps the payload types of the other codecs the stream
+	// can switch to, without renegotiation, to the payload type of their RTX stream.
+	PayloadTypesRetransmission map[uint8]uint8
+
+	// SSRCForwardErrorCorrection, PayloadTypeForwardErrorCorrection and MimeTypeForwardErrorCorrection
+	// describe the FEC protecting this stream. They are zero if FEC wasn't negotiated. SSRCForwardErrorCorrection
+	// is also zero for ULPFEC (RFC 5109), which is sent inside RED in this stream rather than as a stream of its own.
+	SSRCForwardErrorCorrection        uint32
+	PayloadTypeForwardErrorCorrection uint8
+	MimeTypeForwardErrorCorrection    string
+
+	// PayloadTypeRED is the payload type of RED (RFC 2198), which carries both the media and the FEC packets
+	// of this stream when it is protected with ULPFEC. It is zero otherwise.
+	PayloadTypeRED uint8
+}
+
+// RTCPFeedback signals the connection to use additional RTCP packet types.
+// https://draft.ortc.org/#dom-rtcrtcpfeedback
+type RTCPFeedback struct {
+	// Type is the type of feedback.
+	// see: https://draft.ortc.org/#dom-rtcrtcpfeedback
+	// valid: ack, ccm, nack, goog-remb, transport-cc
+	Type string
+
+	// The parameter value depends on the type.
+	// For example, type="nack" parameter="pli" will send Picture Loss Indicator packets.
+	Parameter string
+}
@@ -0,0 +1,54 @@
+package twcc
+
+import (
+	"sync/atomic"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// HeaderExtensionInterceptorFactory is a interceptor.Factory for a HeaderExtensionInterceptor
+type HeaderExtensionInterceptorFactory struct{}
+
+// NewInterceptor constructs a new HeaderExtensionInterceptor
+func (h *HeaderExtensionInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &HeaderExtensionInterceptor{}, nil
+}
+
+// NewHeaderExtensionInterceptor returns a HeaderExtensionInterceptorFactory
+func NewHeaderExtensionInterceptor() (*HeaderExtensionInterceptorFactory, error) {
+	return &HeaderExtensionInterceptorFactory{}, nil
+}
+
+// HeaderExtensionInterceptor adds a transport wide sequence number as header extension to each outgoing RTP packet.
+// The sequence number is shared by all streams of a PeerConnection.
+type HeaderExtensionInterceptor struct {
+	interceptor.NoOp
+	nextSequenceNumber uint32
+}
+
+// BindLocalStream lets you modify any outgoing RTP packets. It is called once for per LocalStream. The returned method
+// will be called once per rtp packet.
+func (h *HeaderExtensionInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	hdrExtID := transportCCExtensionID(info)
+	if hdrExtID == 0 {
+		return writer
+	}
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		sequenceNumber := uint16(atomic.AddUint32(&h.nextSequenceNumber, 1) - 1)
+		tcc, err := (&rtp.TransportCCExtension{TransportSequence: sequenceNumber}).Marshal()
+		if err != nil {
+			return 0, err
+		}
+
+		// The caller may reuse the header, so the extensions are set on a copy
+		withExtension := *header
+		withExtension.Extensions = append([]rtp.Extension{}, header.Extensions...)
+		if err = withExtension.SetExtension(hdrExtID, tcc); err != nil {
+			return 0, err
+		}
+
+		return writer.Write(&withExtension, payload, attributes)
+	})
+}
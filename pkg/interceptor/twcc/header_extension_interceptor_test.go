@@ -0,0 +1,49 @@
+package twcc
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderExtensionInterceptor(t *testing.T) {
+	f, err := NewHeaderExtensionInterceptor()
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	written := make(chan *rtp.Header, 10)
+	bind := func(info *interceptor.StreamInfo) interceptor.RTPWriter {
+		return i.BindLocalStream(info, interceptor.RTPWriterFunc(func(header *rtp.Header, _ []byte, _ interceptor.Attributes) (int, error) {
+			written <- header
+			return 0, nil
+		}))
+	}
+
+	withTWCC := []interceptor.RTPHeaderExtension{{URI: sdp.TransportCCURI, ID: 3}}
+	first := bind(&interceptor.StreamInfo{SSRC: 1, RTPHeaderExtensions: withTWCC})
+	second := bind(&interceptor.StreamInfo{SSRC: 2, RTPHeaderExtensions: withTWCC})
+	without := bind(&interceptor.StreamInfo{SSRC: 3})
+
+	// The sequence number is shared by all streams
+	for n, writer := range []interceptor.RTPWriter{first, second, first} {
+		header := &rtp.Header{}
+		_, err = writer.Write(header, nil, nil)
+		assert.NoError(t, err)
+		assert.False(t, header.Extension, "header of the caller must not be modified")
+
+		tcc := rtp.TransportCCExtension{}
+		assert.NoError(t, tcc.Unmarshal((<-written).GetExtension(3)))
+		assert.Equal(t, uint16(n), tcc.TransportSequence)
+	}
+
+	_, err = without.Write(&rtp.Header{}, nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, (<-written).Extension)
+
+	assert.NoError(t, i.Close())
+}
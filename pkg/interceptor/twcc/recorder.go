@@ -0,0 +1,270 @@
+package twcc
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3/pkg/interceptor/internal/sequence"
+)
+
+const (
+	// maxPacketStatusCount limits the size of a single feedback packet, so it stays below the MTU
+	maxPacketStatusCount = 500
+
+	// maxMissingPackets is the largest gap to the previous feedback that is reported as lost
+	maxMissingPackets = 1 << 14
+
+	// referenceTimeUnit is the resolution of the reference time in microseconds
+	referenceTimeUnit = 64000
+
+	maxRunLength     = 1<<13 - 1
+	oneBitSymbols    = 14
+	twoBitSymbols    = 7
+	referenceTimeMax = 1 << 24
+)
+
+type packetArrival struct {
+	// sequenceNumber is the transport wide sequence number extended with its wraparound count
+	sequenceNumber int64
+	// arrivalTime is in microseconds
+	arrivalTime int64
+}
+
+// Recorder records the arrival of packets carrying a transport wide sequence number and builds
+// TransportLayerCC feedback from them. Recorder is not safe for concurrent use.
+type Recorder struct {
+	senderSSRC uint32
+	mediaSSRC  uint32
+	fbPktCount uint8
+
+	started            bool
+	cycles             int64
+	lastSequenceNumber uint16
+
+	// nextSequenceNumber is the first sequence number not covered by a previous feedback
+	nextSequenceNumber int64
+	reported           bool
+
+	packets []packetArrival
+}
+
+// NewRecorder creates a new Recorder which uses the given senderSSRC in the created feedback packets.
+func NewRecorder(senderSSRC uint32) *Recorder {
+	return &Recorder{senderSSRC: senderSSRC}
+}
+
+// Record marks a packet with mediaSSRC and a transport wide sequence number as received at arrivalTime,
+// given in microseconds.
+func (r *Recorder) Record(mediaSSRC uint32, sequenceNumber uint16, arrivalTime int64) {
+	r.mediaSSRC = mediaSSRC
+
+	if !r.started {
+		r.started = true
+		r.lastSequenceNumber = sequenceNumber
+	}
+
+	cycles := r.cycles
+	diff := int(sequenceNumber) - int(r.lastSequenceNumber)
+	switch {
+	case diff < -sequence.Uint16SizeHalf:
+		// wrapped around
+		r.cycles += 1 << 16
+		cycles = r.cycles
+		r.lastSequenceNumber = sequenceNumber
+	case diff > sequence.Uint16SizeHalf:
+		// late packet from before the last wraparound
+		cycles -= 1 << 16
+	case diff > 0:
+		r.lastSequenceNumber = sequenceNumber
+	}
+
+	extended := cycles + int64(sequenceNumber)
+	if extended < 0 || r.reported && extended < r.nextSequenceNumber {
+		// already reported as lost
+		return
+	}
+
+	r.packets = append(r.packets, packetArrival{sequenceNumber: extended, arrivalTime: arrivalTime})
+}
+
+// BuildFeedbackPacket creates the feedback packets for all packets recorded since the last call.
+func (r *Recorder) BuildFeedbackPacket() []rtcp.Packet {
+	if len(r.packets) == 0 {
+		return nil
+	}
+
+	sort.Slice(r.packets, func(i, j int) bool {
+		return r.packets[i].sequenceNumber < r.packets[j].sequenceNumber
+	})
+
+	baseSequenceNumber := r.packets[0].sequenceNumber
+	if r.reported && baseSequenceNumber-r.nextSequenceNumber < maxMissingPackets {
+		baseSequenceNumber = r.nextSequenceNumber
+	}
+
+	var pkts []rtcp.Packet
+	fb := r.newFeedback(baseSequenceNumber, r.packets[0].arrivalTime)
+	for _, p := range r.packets {
+		if p.sequenceNumber < fb.nextSequenceNumber {
+			// duplicate
+			continue
+		}
+
+		if !fb.addReceived(p.sequenceNumber, p.arrivalTime) {
+			pkts = append(pkts, fb.getRTCP())
+
+			nextBaseSequenceNumber := fb.nextSequenceNumber
+			if p.sequenceNumber-nextBaseSequenceNumber >= maxPacketStatusCount {
+				nextBaseSequenceNumber = p.sequenceNumber
+			}
+			fb = r.newFeedback(nextBaseSequenceNumber, p.arrivalTime)
+			fb.addReceived(p.sequenceNumber, p.arrivalTime)
+		}
+	}
+	pkts = append(pkts, fb.getRTCP())
+
+	r.reported = true
+	r.nextSequenceNumber = fb.nextSequenceNumber
+	r.packets = r.packets[:0]
+
+	return pkts
+}
+
+func (r *Recorder) newFeedback(baseSequenceNumber, arrivalTime int64) *feedback {
+	referenceTime := arrivalTime / referenceTimeUnit
+	fb := &feedback{
+		senderSSRC:         r.senderSSRC,
+		mediaSSRC:          r.mediaSSRC,
+		fbPktCount:         r.fbPktCount,
+		baseSequenceNumber: baseSequenceNumber,
+		nextSequenceNumber: baseSequenceNumber,
+		referenceTime:      referenceTime,
+		lastTimestamp:      referenceTime * referenceTimeUnit,
+	}
+	r.fbPktCount++
+
+	return fb
+}
+
+type feedback struct {
+	senderSSRC uint32
+	mediaSSRC  uint32
+	fbPktCount uint8
+
+	baseSequenceNumber int64
+	nextSequenceNumber int64
+	referenceTime      int64
+	lastTimestamp      int64
+
+	symbols []uint16
+	deltas  []*rtcp.RecvDelta
+}
+
+// addReceived adds a received packet and the packets missing before it. It returns false if
+// the packet doesn't fit into this feedback.
+func (f *feedback) addReceived(sequenceNumber, arrivalTime int64) bool {
+	delta := (arrivalTime - f.lastTimestamp) / rtcp.TypeTCCDeltaScaleFactor
+	if delta < math.MinInt16 || delta > math.MaxInt16 {
+		return false
+	}
+	if sequenceNumber-f.baseSequenceNumber >= maxPacketStatusCount {
+		return false
+	}
+
+	for ; f.nextSequenceNumber < sequenceNumber; f.nextSequenceNumber++ {
+		f.symbols = append(f.symbols, rtcp.TypeTCCPacketNotReceived)
+	}
+
+	symbol := rtcp.TypeTCCPacketReceivedLargeDelta
+	if delta >= 0 && delta <= math.MaxUint8 {
+		symbol = rtcp.TypeTCCPacketReceivedSmallDelta
+	}
+	f.symbols = append(f.symbols, symbol)
+	f.deltas = append(f.deltas, &rtcp.RecvDelta{Type: symbol, Delta: delta * rtcp.TypeTCCDeltaScaleFactor})
+
+	f.lastTimestamp += delta * rtcp.TypeTCCDeltaScaleFactor
+	f.nextSequenceNumber = sequenceNumber + 1
+	return true
+}
+
+func (f *feedback) getRTCP() *rtcp.TransportLayerCC {
+	chunks := encodeChunks(f.symbols)
+
+	// header, SSRCs, base sequence number, status count, reference time and feedback count
+	length := 20 + 2*len(chunks)
+	for _, d := range f.deltas {
+		if d.Type == rtcp.TypeTCCPacketReceivedSmallDelta {
+			length++
+		} else {
+			length += 2
+		}
+	}
+	padding := length%4 != 0
+	if padding {
+		length += 4 - length%4
+	}
+
+	return &rtcp.TransportLayerCC{
+		Header: rtcp.Header{
+			Padding: padding,
+			Count:   rtcp.FormatTCC,
+			Type:    rtcp.TypeTransportSpecificFeedback,
+			Length:  uint16(length/4 - 1),
+		},
+		SenderSSRC:         f.senderSSRC,
+		MediaSSRC:          f.mediaSSRC,
+		BaseSequenceNumber: uint16(f.baseSequenceNumber),
+		PacketStatusCount:  uint16(len(f.symbols)),
+		ReferenceTime:      uint32(f.referenceTime % referenceTimeMax),
+		FbPktCount:         f.fbPktCount,
+		PacketChunks:       chunks,
+		RecvDeltas:         f.deltas,
+	}
+}
+
+// encodeChunks encodes packet status symbols, using run length chunks for long runs and
+// status vector chunks otherwise.
+func encodeChunks(symbols []uint16) []rtcp.PacketStatusChunk {
+	chunks := []rtcp.PacketStatusChunk{}
+	for i := 0; i < len(symbols); {
+		runLength := 1
+		for i+runLength < len(symbols) && symbols[i+runLength] == symbols[i] && runLength < maxRunLength {
+			runLength++
+		}
+
+		if runLength >= oneBitSymbols || (symbols[i] == rtcp.TypeTCCPacketReceivedLargeDelta && runLength >= twoBitSymbols) {
+			chunks = append(chunks, &rtcp.RunLengthChunk{
+				Type:               rtcp.TypeTCCRunLengthChunk,
+				PacketStatusSymbol: symbols[i],
+				RunLength:          uint16(runLength),
+			})
+			i += runLength
+			continue
+		}
+
+		end := i + oneBitSymbols
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		symbolSize := uint16(rtcp.TypeTCCSymbolSizeOneBit)
+		for _, s := range symbols[i:end] {
+			if s == rtcp.TypeTCCPacketReceivedLargeDelta {
+				symbolSize = rtcp.TypeTCCSymbolSizeTwoBit
+				break
+			}
+		}
+		if symbolSize == rtcp.TypeTCCSymbolSizeTwoBit && end > i+twoBitSymbols {
+			end = i + twoBitSymbols
+		}
+
+		chunks = append(chunks, &rtcp.StatusVectorChunk{
+			Type:       rtcp.TypeTCCStatusVectorChunk,
+			SymbolSize: symbolSize,
+			SymbolList: append([]uint16{}, symbols[i:end]...),
+		})
+		i = end
+	}
+
+	return chunks
+}
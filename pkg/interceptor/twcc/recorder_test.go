@@ -0,0 +1,132 @@
+package twcc
+
+import (
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// unmarshalFeedback marshals and unmarshals the packets, to make sure they are valid on the wire
+func unmarshalFeedback(t *testing.T, pkts []rtcp.Packet) []*rtcp.TransportLayerCC {
+	feedbacks := []*rtcp.TransportLayerCC{}
+	for _, pkt := range pkts {
+		raw, err := pkt.Marshal()
+		assert.NoError(t, err)
+
+		fb := &rtcp.TransportLayerCC{}
+		assert.NoError(t, fb.Unmarshal(raw))
+		feedbacks = append(feedbacks, fb)
+	}
+
+	return feedbacks
+}
+
+func TestRecorder(t *testing.T) {
+	const base = int64(64000 * 1000)
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Nil(t, NewRecorder(1).BuildFeedbackPacket())
+	})
+
+	t.Run("Deltas", func(t *testing.T) {
+		r := NewRecorder(1)
+		r.Record(2, 10, base+1000)
+		r.Record(2, 11, base+2000)
+		r.Record(2, 13, base+100000) // 98ms after the previous one, a large delta
+		r.Record(2, 12, base+3000)   // reordered
+
+		fbs := unmarshalFeedback(t, r.BuildFeedbackPacket())
+		assert.Len(t, fbs, 1)
+		fb := fbs[0]
+		assert.Equal(t, uint32(1), fb.SenderSSRC)
+		assert.Equal(t, uint32(2), fb.MediaSSRC)
+		assert.Equal(t, uint16(10), fb.BaseSequenceNumber)
+		assert.Equal(t, uint16(4), fb.PacketStatusCount)
+		assert.Equal(t, uint32(1000), fb.ReferenceTime)
+		assert.Equal(t, uint8(0), fb.FbPktCount)
+		assert.Equal(t, []*rtcp.RecvDelta{
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedLargeDelta, Delta: 97000},
+		}, fb.RecvDeltas)
+	})
+
+	t.Run("Loss", func(t *testing.T) {
+		r := NewRecorder(1)
+		r.Record(2, 0, base)
+		r.Record(2, 20, base+1000)
+
+		fbs := unmarshalFeedback(t, r.BuildFeedbackPacket())
+		assert.Len(t, fbs, 1)
+		assert.Equal(t, uint16(21), fbs[0].PacketStatusCount)
+		assert.Len(t, fbs[0].RecvDeltas, 2)
+
+		// Packets missing between two feedbacks are reported as lost by the next one
+		r.Record(2, 25, base+2000)
+		fbs = unmarshalFeedback(t, r.BuildFeedbackPacket())
+		assert.Len(t, fbs, 1)
+		assert.Equal(t, uint16(21), fbs[0].BaseSequenceNumber)
+		assert.Equal(t, uint16(5), fbs[0].PacketStatusCount)
+		assert.Equal(t, uint8(1), fbs[0].FbPktCount)
+
+		// Packets arriving after they were reported as lost are ignored
+		r.Record(2, 22, base+3000)
+		assert.Nil(t, r.BuildFeedbackPacket())
+	})
+
+	t.Run("Wraparound", func(t *testing.T) {
+		r := NewRecorder(1)
+		r.Record(2, 65534, base)
+		r.Record(2, 1, base+1000)
+		r.Record(2, 65535, base+2000)
+
+		fbs := unmarshalFeedback(t, r.BuildFeedbackPacket())
+		assert.Len(t, fbs, 1)
+		assert.Equal(t, uint16(65534), fbs[0].BaseSequenceNumber)
+		assert.Equal(t, uint16(4), fbs[0].PacketStatusCount)
+		assert.Len(t, fbs[0].RecvDeltas, 3)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		r := NewRecorder(1)
+		r.Record(2, 0, base)
+		r.Record(2, 1, base+10000000) // 10s later, more than a large delta can hold
+		for i := uint16(2); i < 2+maxPacketStatusCount; i++ {
+			r.Record(2, i, base+10000000+int64(i))
+		}
+
+		fbs := unmarshalFeedback(t, r.BuildFeedbackPacket())
+		assert.Len(t, fbs, 3)
+		assert.Equal(t, uint16(1), fbs[0].PacketStatusCount)
+		assert.Equal(t, uint16(1), fbs[1].BaseSequenceNumber)
+		assert.Equal(t, uint16(maxPacketStatusCount), fbs[1].PacketStatusCount)
+		assert.Equal(t, uint16(1+maxPacketStatusCount), fbs[2].BaseSequenceNumber)
+		assert.Equal(t, uint16(1), fbs[2].PacketStatusCount)
+		assert.Equal(t, uint8(2), fbs[2].FbPktCount)
+	})
+}
+
+func TestEncodeChunks(t *testing.T) {
+	const (
+		notReceived = rtcp.TypeTCCPacketNotReceived
+		small       = rtcp.TypeTCCPacketReceivedSmallDelta
+		large       = rtcp.TypeTCCPacketReceivedLargeDelta
+	)
+
+	symbols := []uint16{}
+	for i := 0; i < 20; i++ {
+		symbols = append(symbols, small)
+	}
+	symbols = append(symbols, notReceived, small, large, small)
+
+	assert.Equal(t, []rtcp.PacketStatusChunk{
+		&rtcp.RunLengthChunk{Type: rtcp.TypeTCCRunLengthChunk, PacketStatusSymbol: small, RunLength: 20},
+		&rtcp.StatusVectorChunk{Type: rtcp.TypeTCCStatusVectorChunk, SymbolSize: rtcp.TypeTCCSymbolSizeTwoBit, SymbolList: []uint16{notReceived, small, large, small}},
+	}, encodeChunks(symbols))
+
+	assert.Equal(t, []rtcp.PacketStatusChunk{
+		&rtcp.StatusVectorChunk{Type: rtcp.TypeTCCStatusVectorChunk, SymbolSize: rtcp.TypeTCCSymbolSizeOneBit, SymbolList: []uint16{small, notReceived, small}},
+	}, encodeChunks([]uint16{small, notReceived, small}))
+}
@@ -0,0 +1,180 @@
+package twcc
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// SenderInterceptorFactory is a interceptor.Factory for a SenderInterceptor
+type SenderInterceptorFactory struct {
+	opts []SenderOption
+}
+
+// NewInterceptor constructs a new SenderInterceptor
+func (s *SenderInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	i := &SenderInterceptor{
+		interval: 100 * time.Millisecond,
+		now:      time.Now,
+		log:      logging.NewDefaultLoggerFactory().NewLogger("twcc_sender_interceptor"),
+		recorder: NewRecorder(rand.Uint32()), // #nosec
+		close:    make(chan struct{}),
+	}
+
+	for _, opt := range s.opts {
+		if err := opt(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// NewSenderInterceptor returns a new SenderInterceptorFactory
+func NewSenderInterceptor(opts ...SenderOption) (*SenderInterceptorFactory, error) {
+	return &SenderInterceptorFactory{opts}, nil
+}
+
+// SenderInterceptor records the arrival of incoming RTP packets carrying a transport wide sequence number
+// and sends TransportLayerCC feedback for them.
+type SenderInterceptor struct {
+	interceptor.NoOp
+	interval time.Duration
+	now      func() time.Time
+	log      logging.LeveledLogger
+	m        sync.Mutex
+	wg       sync.WaitGroup
+	close    chan struct{}
+
+	rtcpWriter  interceptor.RTCPWriter
+	loopStarted bool
+	numStreams  int
+
+	recorder   *Recorder
+	recorderMu sync.Mutex
+}
+
+// BindRTCPWriter lets you modify any outgoing RTCP packets. It is called once per PeerConnection. The returned method
+// will be called once per packet batch.
+func (s *SenderInterceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.rtcpWriter = writer
+	s.startLoop()
+
+	return writer
+}
+
+// BindRemoteStream lets you modify any incoming RTP packets. It is called once for per RemoteStream. The returned method
+// will be called once per rtp packet.
+func (s *SenderInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	hdrExtID := transportCCExtensionID(info)
+	if hdrExtID == 0 {
+		return reader
+	}
+
+	s.m.Lock()
+	s.numStreams++
+	s.startLoop()
+	s.m.Unlock()
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		header := rtp.Header{}
+		if err = header.Unmarshal(b[:i]); err != nil {
+			return 0, nil, err
+		}
+
+		if ext := header.GetExtension(hdrExtID); ext != nil {
+			tcc := rtp.TransportCCExtension{}
+			if err = tcc.Unmarshal(ext); err != nil {
+				return 0, nil, err
+			}
+
+			arrivalTime := s.now().UnixNano() / int64(time.Microsecond)
+			s.recorderMu.Lock()
+			s.recorder.Record(header.SSRC, tcc.TransportSequence, arrivalTime)
+			s.recorderMu.Unlock()
+		}
+
+		return i, attr, nil
+	})
+}
+
+// UnbindRemoteStream is called when the Stream is removed. It can be used to clean up any data related to that track.
+func (s *SenderInterceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	if transportCCExtensionID(info) == 0 {
+		return
+	}
+
+	s.m.Lock()
+	s.numStreams--
+	s.m.Unlock()
+}
+
+// Close closes the interceptor.
+func (s *SenderInterceptor) Close() error {
+	defer s.wg.Wait()
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !s.isClosed() {
+		close(s.close)
+	}
+
+	return nil
+}
+
+// startLoop starts sending feedback once there is both a RTCPWriter and a stream to give feedback on. s.m must be held.
+func (s *SenderInterceptor) startLoop() {
+	if s.loopStarted || s.rtcpWriter == nil || s.numStreams == 0 || s.isClosed() {
+		return
+	}
+
+	s.loopStarted = true
+	s.wg.Add(1)
+	go s.loop(s.rtcpWriter)
+}
+
+func (s *SenderInterceptor) loop(rtcpWriter interceptor.RTCPWriter) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.recorderMu.Lock()
+			pkts := s.recorder.BuildFeedbackPacket()
+			s.recorderMu.Unlock()
+
+			for _, pkt := range pkts {
+				if _, err := rtcpWriter.Write([]rtcp.Packet{pkt}, interceptor.Attributes{}); err != nil {
+					s.log.Warnf("failed sending: %+v", err)
+				}
+			}
+
+		case <-s.close:
+			return
+		}
+	}
+}
+
+func (s *SenderInterceptor) isClosed() bool {
+	select {
+	case <-s.close:
+		return true
+	default:
+		return false
+	}
+}
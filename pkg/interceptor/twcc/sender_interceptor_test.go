@@ -0,0 +1,87 @@
+package twcc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSenderInterceptor(t *testing.T) {
+	now := time.Unix(1000, 0)
+	f, err := NewSenderInterceptor(
+		SenderInterval(time.Millisecond*10),
+		SenderNow(func() time.Time { return now }),
+	)
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	rtcpWritten := make(chan []rtcp.Packet, 10)
+	i.BindRTCPWriter(interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+		rtcpWritten <- pkts
+		return 0, nil
+	}))
+
+	rtpIn := make(chan []byte, 10)
+	reader := i.BindRemoteStream(&interceptor.StreamInfo{
+		SSRC:                123456,
+		RTPHeaderExtensions: []interceptor.RTPHeaderExtension{{URI: sdp.TransportCCURI, ID: 5}},
+	}, interceptor.RTPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, <-rtpIn), nil, nil
+	}))
+
+	for _, sequenceNumber := range []uint16{7, 8, 10} {
+		tcc, err := (&rtp.TransportCCExtension{TransportSequence: sequenceNumber}).Marshal()
+		assert.NoError(t, err)
+
+		header := rtp.Header{SSRC: 123456}
+		assert.NoError(t, header.SetExtension(5, tcc))
+		raw, err := (&rtp.Packet{Header: header}).Marshal()
+		assert.NoError(t, err)
+
+		rtpIn <- raw
+		_, _, err = reader.Read(make([]byte, 1500), nil)
+		assert.NoError(t, err)
+	}
+
+	pkts := <-rtcpWritten
+	assert.Len(t, pkts, 1)
+	fb, ok := pkts[0].(*rtcp.TransportLayerCC)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(123456), fb.MediaSSRC)
+	assert.Equal(t, uint16(7), fb.BaseSequenceNumber)
+	assert.Equal(t, uint16(4), fb.PacketStatusCount)
+	assert.Len(t, fb.RecvDeltas, 3)
+
+	assert.NoError(t, i.Close())
+}
+
+func TestSenderInterceptor_NotNegotiated(t *testing.T) {
+	f, err := NewSenderInterceptor(SenderInterval(time.Millisecond))
+	assert.NoError(t, err)
+
+	i, err := f.NewInterceptor("")
+	assert.NoError(t, err)
+
+	i.BindRTCPWriter(interceptor.RTCPWriterFunc(func(_ []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+		assert.Fail(t, "no feedback should be sent")
+		return 0, nil
+	}))
+
+	raw, err := (&rtp.Packet{Header: rtp.Header{SSRC: 1}}).Marshal()
+	assert.NoError(t, err)
+	reader := i.BindRemoteStream(&interceptor.StreamInfo{SSRC: 1}, interceptor.RTPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, raw), nil, nil
+	}))
+	_, _, err = reader.Read(make([]byte, 1500), nil)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 10)
+	assert.NoError(t, i.Close())
+}
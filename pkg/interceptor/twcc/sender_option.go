@@ -0,0 +1,34 @@
+package twcc
+
+import (
+	"time"
+
+	"github.com/pion/logging"
+)
+
+// SenderOption can be used to configure SenderInterceptor.
+type SenderOption func(s *SenderInterceptor) error
+
+// SenderLog sets a logger for the interceptor.
+func SenderLog(log logging.LeveledLogger) SenderOption {
+	return func(s *SenderInterceptor) error {
+		s.log = log
+		return nil
+	}
+}
+
+// SenderInterval sets the interval at which feedback is sent.
+func SenderInterval(interval time.Duration) SenderOption {
+	return func(s *SenderInterceptor) error {
+		s.interval = interval
+		return nil
+	}
+}
+
+// SenderNow sets an alternative for the time.Now function.
+func SenderNow(f func() time.Time) SenderOption {
+	return func(s *SenderInterceptor) error {
+		s.now = f
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+// Package twcc provides interceptors to implement transport wide congestion control.
+package twcc
+
+import (
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+)
+
+// transportCCExtensionID returns the negotiated ID of the transport wide sequence number
+// header extension, or 0 if it wasn't negotiated for the stream.
+func transportCCExtensionID(info *interceptor.StreamInfo) uint8 {
+	for _, e := range info.RTPHeaderExtensions {
+		if e.URI == sdp.TransportCCURI {
+			return uint8(e.ID)
+		}
+	}
+
+	return 0
+}
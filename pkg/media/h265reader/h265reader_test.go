@@ -0,0 +1,101 @@
+package h265reader
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func CreateReader(h265 []byte, assert *assert.Assertions) *H265Reader {
+	reader, err := NewReader(bytes.NewReader(h265))
+
+	assert.Nil(err)
+	assert.NotNil(reader)
+
+	return reader
+}
+
+func TestDataDoesNotStartWithH265Header(t *testing.T) {
+	assert := assert.New(t)
+
+	testFunction := func(input []byte) {
+		reader := CreateReader(input, assert)
+		nal, err := reader.NextNAL()
+		assert.Equal(errDataIsNotH265Stream, err)
+		assert.Nil(nal)
+	}
+
+	h265Bytes1 := []byte{2}
+	testFunction(h265Bytes1)
+
+	h265Bytes2 := []byte{0, 2}
+	testFunction(h265Bytes2)
+
+	h265Bytes3 := []byte{0, 0, 2}
+	testFunction(h265Bytes3)
+
+	h265Bytes4 := []byte{0, 0, 2, 0}
+	testFunction(h265Bytes4)
+
+	h265Bytes5 := []byte{0, 0, 0, 2}
+	testFunction(h265Bytes5)
+}
+
+func TestParseHeader(t *testing.T) {
+	assert := assert.New(t)
+	h265Bytes := []byte{0x0, 0x0, 0x1, 0xCB, 0x0B}
+
+	reader := CreateReader(h265Bytes, assert)
+
+	nal, err := reader.NextNAL()
+	assert.Nil(err)
+
+	assert.Equal(2, len(nal.Data))
+	assert.True(nal.ForbiddenZeroBit)
+	assert.Equal(NalUnitTypeEndOfStream, nal.UnitType)
+	assert.Equal(uint8(33), nal.LayerID)
+	assert.Equal(uint8(3), nal.TemporalIDPlus1)
+}
+
+func TestEOF(t *testing.T) {
+	assert := assert.New(t)
+
+	testFunction := func(input []byte) {
+		reader := CreateReader(input, assert)
+
+		nal, err := reader.NextNAL()
+		assert.Equal(io.EOF, err)
+		assert.Nil(nal)
+	}
+
+	h265Bytes1 := []byte{0, 0, 0, 1}
+	testFunction(h265Bytes1)
+
+	h265Bytes2 := []byte{0, 0, 1}
+	testFunction(h265Bytes2)
+
+	h265Bytes3 := []byte{}
+	testFunction(h265Bytes3)
+}
+
+func TestSkipSEI(t *testing.T) {
+	assert := assert.New(t)
+	h265Bytes := []byte{
+		0x0, 0x0, 0x0, 0x1, 0x40, 0x01,
+		0x0, 0x0, 0x0, 0x1, 0x4E, 0x01, // Prefix SEI
+		0x0, 0x0, 0x0, 0x1, 0x50, 0x01, // Suffix SEI
+		0x0, 0x0, 0x0, 0x1, 0x42, 0x01,
+	}
+
+	reader := CreateReader(h265Bytes, assert)
+
+	nal, err := reader.NextNAL()
+	assert.Nil(err)
+	assert.Equal(NalUnitTypeVPS, nal.UnitType)
+
+	nal, err = reader.NextNAL()
+	assert.Nil(err)
+	assert.Equal(NalUnitTypeSPS, nal.UnitType)
+}
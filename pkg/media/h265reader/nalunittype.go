@@ -0,0 +1,99 @@
+package h265reader
+
+import "strconv"
+
+// NalUnitType is the type of a NAL
+type NalUnitType uint8
+
+// Enums for NalUnitTypes
+const (
+	NalUnitTypeTrailN        NalUnitType = 0  // Coded slice segment of a non-TSA, non-STSA trailing picture
+	NalUnitTypeTrailR        NalUnitType = 1  // Coded slice segment of a non-TSA, non-STSA trailing picture
+	NalUnitTypeTsaN          NalUnitType = 2  // Coded slice segment of a TSA picture
+	NalUnitTypeTsaR          NalUnitType = 3  // Coded slice segment of a TSA picture
+	NalUnitTypeStsaN         NalUnitType = 4  // Coded slice segment of a STSA picture
+	NalUnitTypeStsaR         NalUnitType = 5  // Coded slice segment of a STSA picture
+	NalUnitTypeRadlN         NalUnitType = 6  // Coded slice segment of a RADL picture
+	NalUnitTypeRadlR         NalUnitType = 7  // Coded slice segment of a RADL picture
+	NalUnitTypeRaslN         NalUnitType = 8  // Coded slice segment of a RASL picture
+	NalUnitTypeRaslR         NalUnitType = 9  // Coded slice segment of a RASL picture
+	NalUnitTypeBlaWLp        NalUnitType = 16 // Coded slice segment of a BLA picture
+	NalUnitTypeBlaWRadl      NalUnitType = 17 // Coded slice segment of a BLA picture
+	NalUnitTypeBlaNLp        NalUnitType = 18 // Coded slice segment of a BLA picture
+	NalUnitTypeIdrWRadl      NalUnitType = 19 // Coded slice segment of an IDR picture
+	NalUnitTypeIdrNLp        NalUnitType = 20 // Coded slice segment of an IDR picture
+	NalUnitTypeCraNut        NalUnitType = 21 // Coded slice segment of a CRA picture
+	NalUnitTypeVPS           NalUnitType = 32 // Video parameter set
+	NalUnitTypeSPS           NalUnitType = 33 // Sequence parameter set
+	NalUnitTypePPS           NalUnitType = 34 // Picture parameter set
+	NalUnitTypeAUD           NalUnitType = 35 // Access unit delimiter
+	NalUnitTypeEndOfSequence NalUnitType = 36 // End of sequence
+	NalUnitTypeEndOfStream   NalUnitType = 37 // End of bitstream
+	NalUnitTypeFiller        NalUnitType = 38 // Filler data
+	NalUnitTypePrefixSEI     NalUnitType = 39 // Supplemental enhancement information (SEI)
+	NalUnitTypeSuffixSEI     NalUnitType = 40 // Supplemental enhancement information (SEI)
+	// 10..15                                   // Reserved non-IRAP sub-layer
+	// 22..31                                   // Reserved IRAP and non-IRAP
+	// 41..47                                   // Reserved
+	// 48..63                                   // Unspecified
+)
+
+func (n *NalUnitType) String() string {
+	var str string
+	switch *n {
+	case NalUnitTypeTrailN:
+		str = "TrailN"
+	case NalUnitTypeTrailR:
+		str = "TrailR"
+	case NalUnitTypeTsaN:
+		str = "TsaN"
+	case NalUnitTypeTsaR:
+		str = "TsaR"
+	case NalUnitTypeStsaN:
+		str = "StsaN"
+	case NalUnitTypeStsaR:
+		str = "StsaR"
+	case NalUnitTypeRadlN:
+		str = "RadlN"
+	case NalUnitTypeRadlR:
+		str = "RadlR"
+	case NalUnitTypeRaslN:
+		str = "RaslN"
+	case NalUnitTypeRaslR:
+		str = "RaslR"
+	case NalUnitTypeBlaWLp:
+		str = "BlaWLp"
+	case NalUnitTypeBlaWRadl:
+		str = "BlaWRadl"
+	case NalUnitTypeBlaNLp:
+		str = "BlaNLp"
+	case NalUnitTypeIdrWRadl:
+		str = "IdrWRadl"
+	case NalUnitTypeIdrNLp:
+		str = "IdrNLp"
+	case NalUnitTypeCraNut:
+		str = "CraNut"
+	case NalUnitTypeVPS:
+		str = "VPS"
+	case NalUnitTypeSPS:
+		str = "SPS"
+	case NalUnitTypePPS:
+		str = "PPS"
+	case NalUnitTypeAUD:
+		str = "AUD"
+	case NalUnitTypeEndOfSequence:
+		str = "EndOfSequence"
+	case NalUnitTypeEndOfStream:
+		str = "EndOfStream"
+	case NalUnitTypeFiller:
+		str = "Filler"
+	case NalUnitTypePrefixSEI:
+		str = "PrefixSEI"
+	case NalUnitTypeSuffixSEI:
+		str = "SuffixSEI"
+	default:
+		str = "Unknown"
+	}
+	str = str + "(" + strconv.FormatInt(int64(*n), 10) + ")"
+	return str
+}
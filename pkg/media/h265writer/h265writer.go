@@ -0,0 +1,96 @@
+// Package h265writer implements H265 media container writer
+package h265writer
+
+import (
+	"io"
+	"os"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/codecs/h265"
+)
+
+type (
+	// H265Writer is used to take RTP packets, parse them and
+	// write the data to an io.Writer.
+	// Streams with DONL fields and PACI packets are not supported.
+	// https://tools.ietf.org/html/rfc7798#section-4.4
+	H265Writer struct {
+		writer      io.Writer
+		hasKeyFrame bool
+	}
+)
+
+// New builds a new H265 writer
+func New(filename string) (*H265Writer, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWith(f), nil
+}
+
+// NewWith initializes a new H265 writer with an io.Writer output
+func NewWith(w io.Writer) *H265Writer {
+	return &H265Writer{
+		writer: w,
+	}
+}
+
+// WriteRTP adds a new packet and writes the appropriate headers for it
+func (h *H265Writer) WriteRTP(packet *rtp.Packet) error {
+	if len(packet.Payload) == 0 {
+		return nil
+	}
+
+	if !h.hasKeyFrame {
+		if h.hasKeyFrame = isKeyFrame(packet.Payload); !h.hasKeyFrame {
+			// key frame not defined yet. discarding packet
+			return nil
+		}
+	}
+
+	data, err := (&h265.Packet{}).Unmarshal(packet.Payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.writer.Write(data)
+
+	return err
+}
+
+// Close closes the underlying writer
+func (h *H265Writer) Close() error {
+	if h.writer != nil {
+		if closer, ok := h.writer.(io.Closer); ok {
+			return closer.Close()
+		}
+	}
+
+	return nil
+}
+
+// isKeyFrame returns true if the payload starts with a VPS, on its own or in a Aggregation Packet
+func isKeyFrame(data []byte) bool {
+	const (
+		typeAP  = 48
+		typeVPS = 32
+		// The payload header and the size of the first aggregated NAL unit
+		apHeaderSize = 4
+	)
+
+	if len(data) < 2 {
+		return false
+	}
+
+	naluType := (data[0] & 0x7E) >> 1
+	if naluType == typeAP {
+		if len(data) <= apHeaderSize {
+			return false
+		}
+		naluType = (data[apHeaderSize] & 0x7E) >> 1
+	}
+
+	return naluType == typeVPS
+}
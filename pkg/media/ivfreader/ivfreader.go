@@ -80,6 +80,8 @@ func (i *IVFReader) ResetReader(reset func(bytesRead int64) io.Reader) {
 // ParseNextFrame reads from stream and returns IVF frame payload, header,
 // and an error if there is incomplete frame data.
 // Returns all nil values when no more frames are available.
+// For AV1 (FourCC AV01) the payload is a temporal unit in the low overhead bitstream format,
+// as expected by av1.Payloader.
 func (i *IVFReader) ParseNextFrame() ([]byte, *IVFFrameHeader, error) {
 	buffer := make([]byte, ivfFrameHeaderSize)
 	var header *IVFFrameHeader
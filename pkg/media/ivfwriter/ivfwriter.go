@@ -6,31 +6,70 @@ import (
 	"errors"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v3/pkg/codecs/av1"
+)
+
+const (
+	mimeTypeVP8 = "video/vp8"
+	mimeTypeAV1 = "video/av1"
+
+	fourccVP8 = "VP80"
+	fourccAV1 = "AV01"
 )
 
 var (
 	errFileNotOpened    = errors.New("file not opened")
 	errInvalidNilPacket = errors.New("invalid nil packet")
+	errCodecUnsupported = errors.New("codec is not supported by IVFWriter")
 )
 
+// temporalDelimiter is the OBU starting each AV1 temporal unit, it is not sent over RTP
+var temporalDelimiter = []byte{0x12, 0x00}
+
 // IVFWriter is used to take RTP packets and write them to an IVF on disk
 type IVFWriter struct {
 	ioWriter     io.Writer
 	count        uint64
 	seenKeyFrame bool
 	currentFrame []byte
+
+	fourcc    string
+	av1Packet *av1.Packet
+}
+
+// Option configures an IVFWriter
+type Option func(i *IVFWriter) error
+
+// WithCodec sets the codec of the RTP packets written, by its mime type.
+// VP8 and AV1 are supported, VP8 is the default.
+func WithCodec(mimeType string) Option {
+	return func(i *IVFWriter) error {
+		switch strings.ToLower(mimeType) {
+		case mimeTypeVP8:
+			i.fourcc = fourccVP8
+			i.av1Packet = nil
+		case mimeTypeAV1:
+			i.fourcc = fourccAV1
+			i.av1Packet = &av1.Packet{}
+		default:
+			return errCodecUnsupported
+		}
+
+		return nil
+	}
 }
 
 // New builds a new IVF writer
-func New(fileName string) (*IVFWriter, error) {
+func New(fileName string, opts ...Option) (*IVFWriter, error) {
 	f, err := os.Create(fileName)
 	if err != nil {
 		return nil, err
 	}
-	writer, err := NewWith(f)
+	writer, err := NewWith(f, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +78,7 @@ func New(fileName string) (*IVFWriter, error) {
 }
 
 // NewWith initialize a new IVF writer with an io.Writer output
-func NewWith(out io.Writer) (*IVFWriter, error) {
+func NewWith(out io.Writer, opts ...Option) (*IVFWriter, error) {
 	if out == nil {
 		return nil, errFileNotOpened
 	}
@@ -47,6 +86,12 @@ func NewWith(out io.Writer) (*IVFWriter, error) {
 	writer := &IVFWriter{
 		ioWriter:     out,
 		seenKeyFrame: false,
+		fourcc:       fourccVP8,
+	}
+	for _, opt := range opts {
+		if err := opt(writer); err != nil {
+			return nil, err
+		}
 	}
 	if err := writer.writeHeader(); err != nil {
 		return nil, err
@@ -59,7 +104,7 @@ func (i *IVFWriter) writeHeader() error {
 	copy(header[0:], "DKIF")                        // DKIF
 	binary.LittleEndian.PutUint16(header[4:], 0)    // Version
 	binary.LittleEndian.PutUint16(header[6:], 32)   // Header size
-	copy(header[8:], "VP80")                        // FOURCC
+	copy(header[8:], i.fourcc)                      // FOURCC
 	binary.LittleEndian.PutUint16(header[12:], 640) // Width in pixels
 	binary.LittleEndian.PutUint16(header[14:], 480) // Height in pixels
 	binary.LittleEndian.PutUint32(header[16:], 30)  // Framerate denominator
@@ -77,6 +122,10 @@ func (i *IVFWriter) WriteRTP(packet *rtp.Packet) error {
 		return errFileNotOpened
 	}
 
+	if i.av1Packet != nil {
+		return i.writeAV1(packet)
+	}
+
 	vp8Packet := codecs.VP8Packet{}
 	if _, err := vp8Packet.Unmarshal(packet.Payload); err != nil {
 		return err
@@ -99,6 +148,37 @@ func (i *IVFWriter) WriteRTP(packet *rtp.Packet) error {
 		return nil
 	}
 
+	return i.writeFrame()
+}
+
+// writeAV1 adds the OBUs of a AV1 packet. Temporal units are written from the start
+// of the first coded video sequence, as the previous ones can't be decoded.
+func (i *IVFWriter) writeAV1(packet *rtp.Packet) error {
+	obus, err := i.av1Packet.UnmarshalRTP(packet)
+	if err != nil {
+		return err
+	}
+
+	if !i.seenKeyFrame {
+		if !i.av1Packet.N {
+			return nil
+		}
+		i.seenKeyFrame = true
+	}
+
+	if i.currentFrame == nil {
+		i.currentFrame = append(i.currentFrame, temporalDelimiter...)
+	}
+	i.currentFrame = append(i.currentFrame, obus...)
+
+	if !packet.Marker {
+		return nil
+	}
+
+	return i.writeFrame()
+}
+
+func (i *IVFWriter) writeFrame() error {
 	frameHeader := make([]byte, 12)
 	binary.LittleEndian.PutUint32(frameHeader[0:], uint32(len(i.currentFrame))) // Frame length
 	binary.LittleEndian.PutUint64(frameHeader[4:], i.count)                     // PTS
@@ -7,6 +7,8 @@ import (
 
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v3/pkg/codecs/av1"
+	"github.com/pion/webrtc/v3/pkg/media/ivfreader"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -230,3 +232,53 @@ func TestIVFWriter_AddPacketAndClose(t *testing.T) {
 		}
 	}
 }
+
+func TestIVFWriter_AV1(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithCodec("video/H264"))
+	assert.Equal(t, errCodecUnsupported, err)
+
+	sequenceHeader := []byte{0x0A, 0x03, 0x01, 0x02, 0x03}
+	frame := append([]byte{0x32, 0x80, 0x10}, bytes.Repeat([]byte{0xAB}, 2048)...)
+
+	buffer := &bytes.Buffer{}
+	writer, err := NewWith(buffer, WithCodec("video/AV1"))
+	assert.NoError(t, err)
+
+	payloader := &av1.Payloader{}
+	sequenceNumber := uint16(0)
+	writeTemporalUnit := func(tu []byte) {
+		payloads := payloader.Payload(1200, tu)
+		for i, payload := range payloads {
+			sequenceNumber++
+			assert.NoError(t, writer.WriteRTP(&rtp.Packet{
+				Header: rtp.Header{
+					Marker:         i == len(payloads)-1,
+					SequenceNumber: sequenceNumber,
+				},
+				Payload: payload,
+			}))
+		}
+	}
+
+	// Dropped, as it isn't preceded by a sequence header
+	writeTemporalUnit(frame)
+	assert.False(t, writer.seenKeyFrame)
+
+	writeTemporalUnit(append(append([]byte{}, sequenceHeader...), frame...))
+	writeTemporalUnit(frame)
+	assert.Equal(t, uint64(2), writer.count)
+	assert.NoError(t, writer.Close())
+
+	reader, header, err := ivfreader.NewWith(buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "AV01", header.FourCC)
+
+	for _, expected := range [][]byte{
+		append(append(append([]byte{}, temporalDelimiter...), sequenceHeader...), frame...),
+		append(append([]byte{}, temporalDelimiter...), frame...),
+	} {
+		tu, _, err := reader.ParseNextFrame()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, tu)
+	}
+}
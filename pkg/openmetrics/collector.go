@@ -0,0 +1,196 @@
+// +build !js
+
+// Package openmetrics exposes the stats of PeerConnections in the OpenMetrics text format
+package openmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// ContentType is the content type of the OpenMetrics text format served by the Collector
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+const peerConnectionLabel = "peer_connection"
+
+var (
+	iceConnectionStates = []fmt.Stringer{
+		webrtc.ICEConnectionStateNew,
+		webrtc.ICEConnectionStateChecking,
+		webrtc.ICEConnectionStateConnected,
+		webrtc.ICEConnectionStateCompleted,
+		webrtc.ICEConnectionStateDisconnected,
+		webrtc.ICEConnectionStateFailed,
+		webrtc.ICEConnectionStateClosed,
+	}
+	peerConnectionStates = []fmt.Stringer{
+		webrtc.PeerConnectionStateNew,
+		webrtc.PeerConnectionStateConnecting,
+		webrtc.PeerConnectionStateConnected,
+		webrtc.PeerConnectionStateDisconnected,
+		webrtc.PeerConnectionStateFailed,
+		webrtc.PeerConnectionStateClosed,
+	}
+)
+
+// Collector collects the stats of the PeerConnections registered to it,
+// and serves them in the OpenMetrics text format as an http.Handler.
+type Collector struct {
+	mu              sync.Mutex
+	peerConnections map[string]*webrtc.PeerConnection
+}
+
+// NewCollector creates a Collector without any PeerConnection
+func NewCollector() *Collector {
+	return &Collector{peerConnections: map[string]*webrtc.PeerConnection{}}
+}
+
+// Register adds a PeerConnection to the Collector, its metrics are labeled
+// with the given ID as "peer_connection"
+func (c *Collector) Register(id string, pc *webrtc.PeerConnection) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.peerConnections[id]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, id)
+	}
+	c.peerConnections[id] = pc
+	return nil
+}
+
+// Unregister removes the PeerConnection registered with the given ID from
+// the Collector. PeerConnections are not removed when they are closed, they
+// must be unregistered once their metrics are no longer needed.
+func (c *Collector) Unregister(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.peerConnections, id)
+}
+
+type peerConnectionStats struct {
+	id                  string
+	iceConnectionState  webrtc.ICEConnectionState
+	peerConnectionState webrtc.PeerConnectionState
+	report              webrtc.StatsReport
+}
+
+// ServeHTTP writes the metrics of the registered PeerConnections
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = c.collect().writeTo(w)
+}
+
+func (c *Collector) collect() *families {
+	c.mu.Lock()
+	stats := make([]peerConnectionStats, 0, len(c.peerConnections))
+	pcs := make([]*webrtc.PeerConnection, 0, len(c.peerConnections))
+	for id, pc := range c.peerConnections {
+		stats = append(stats, peerConnectionStats{id: id})
+		pcs = append(pcs, pc)
+	}
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range stats {
+		wg.Add(1)
+		go func(s *peerConnectionStats, pc *webrtc.PeerConnection) {
+			defer wg.Done()
+			s.iceConnectionState = pc.ICEConnectionState()
+			s.peerConnectionState = pc.ConnectionState()
+			s.report = pc.GetStats()
+		}(&stats[i], pcs[i])
+	}
+	wg.Wait()
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].id < stats[j].id })
+
+	f := newFamilies()
+	for i := range stats {
+		addPeerConnectionMetrics(f, &stats[i])
+	}
+	return f
+}
+
+func addPeerConnectionMetrics(f *families, s *peerConnectionStats) { //nolint:gocognit
+	pcLabel := label{peerConnectionLabel, s.id}
+
+	f.addStateSet("webrtc_ice_connection_state", "ICE connection state of the PeerConnection",
+		iceConnectionStates, s.iceConnectionState, pcLabel)
+	f.addStateSet("webrtc_peer_connection_state", "Connection state of the PeerConnection",
+		peerConnectionStates, s.peerConnectionState, pcLabel)
+
+	ids := make([]string, 0, len(s.report))
+	for id := range s.report {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		switch stats := s.report[id].(type) {
+		case webrtc.PeerConnectionStats:
+			f.add("webrtc_data_channels_opened", metricTypeCounter, "Number of data channels that reached the open state",
+				float64(stats.DataChannelsOpened), pcLabel)
+			f.add("webrtc_data_channels_closed", metricTypeCounter, "Number of data channels that left the open state",
+				float64(stats.DataChannelsClosed), pcLabel)
+			f.add("webrtc_data_channels_requested", metricTypeCounter, "Number of data channels created by the PeerConnection",
+				float64(stats.DataChannelsRequested), pcLabel)
+			f.add("webrtc_data_channels_accepted", metricTypeCounter, "Number of data channels created by the remote peer",
+				float64(stats.DataChannelsAccepted), pcLabel)
+		case webrtc.TransportStats:
+			labels := []label{pcLabel, {"transport", stats.ID}}
+			f.add("webrtc_transport_sent_bytes", metricTypeCounter, "Bytes sent over the transport",
+				float64(stats.BytesSent), labels...)
+			f.add("webrtc_transport_received_bytes", metricTypeCounter, "Bytes received over the transport",
+				float64(stats.BytesReceived), labels...)
+		case webrtc.OutboundRTPStreamStats:
+			labels := []label{pcLabel, {"ssrc", strconv.FormatUint(uint64(stats.SSRC), 10)}, {"kind", stats.Kind}}
+			f.add("webrtc_outbound_rtp_sent_packets", metricTypeCounter, "RTP packets sent on the stream",
+				float64(stats.PacketsSent), labels...)
+			f.add("webrtc_outbound_rtp_sent_bytes", metricTypeCounter, "RTP payload bytes sent on the stream",
+				float64(stats.BytesSent), labels...)
+			f.add("webrtc_outbound_rtp_sent_frames", metricTypeCounter, "Frames sent on the stream",
+				float64(stats.FramesSent), labels...)
+			f.add("webrtc_outbound_rtp_received_nacks", metricTypeCounter, "NACK packets received for the stream",
+				float64(stats.NACKCount), labels...)
+			f.add("webrtc_outbound_rtp_received_plis", metricTypeCounter, "PLI packets received for the stream",
+				float64(stats.PLICount), labels...)
+			f.add("webrtc_outbound_rtp_received_firs", metricTypeCounter, "FIR packets received for the stream",
+				float64(stats.FIRCount), labels...)
+
+			if remote, ok := s.report[stats.RemoteID].(webrtc.RemoteInboundRTPStreamStats); ok {
+				f.add("webrtc_outbound_rtp_remote_lost_packets", metricTypeGauge, "RTP packets of the stream lost, as reported by the remote peer",
+					float64(remote.PacketsLost), labels...)
+				f.add("webrtc_outbound_rtp_remote_fraction_lost", metricTypeGauge, "Fraction of the RTP packets of the stream lost since the previous report of the remote peer",
+					remote.FractionLost, labels...)
+				f.add("webrtc_outbound_rtp_remote_jitter_seconds", metricTypeGauge, "Jitter of the stream, as reported by the remote peer",
+					remote.Jitter, labels...)
+				f.add("webrtc_outbound_rtp_round_trip_time_seconds", metricTypeGauge, "Round trip time computed from the reports of the remote peer",
+					remote.RoundTripTime, labels...)
+			}
+		case webrtc.InboundRTPStreamStats:
+			labels := []label{pcLabel, {"ssrc", strconv.FormatUint(uint64(stats.SSRC), 10)}, {"kind", stats.Kind}}
+			f.add("webrtc_inbound_rtp_received_packets", metricTypeCounter, "RTP packets received on the stream",
+				float64(stats.PacketsReceived), labels...)
+			f.add("webrtc_inbound_rtp_received_bytes", metricTypeCounter, "RTP payload bytes received on the stream",
+				float64(stats.BytesReceived), labels...)
+			f.add("webrtc_inbound_rtp_received_frames", metricTypeCounter, "Frames received on the stream",
+				float64(stats.FramesReceived), labels...)
+			f.add("webrtc_inbound_rtp_lost_packets", metricTypeGauge, "RTP packets of the stream lost",
+				float64(stats.PacketsLost), labels...)
+			f.add("webrtc_inbound_rtp_jitter_seconds", metricTypeGauge, "Interarrival jitter of the stream",
+				stats.Jitter, labels...)
+			f.add("webrtc_inbound_rtp_sent_nacks", metricTypeCounter, "NACK packets sent for the stream",
+				float64(stats.NACKCount), labels...)
+			f.add("webrtc_inbound_rtp_sent_plis", metricTypeCounter, "PLI packets sent for the stream",
+				float64(stats.PLICount), labels...)
+			f.add("webrtc_inbound_rtp_sent_firs", metricTypeCounter, "FIR packets sent for the stream",
+				float64(stats.FIRCount), labels...)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// +build !js
+
+package openmetrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/transport/test"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/stretchr/testify/assert"
+)
+
+func signalPair(t *testing.T, offerer, answerer *webrtc.PeerConnection) {
+	offer, err := offerer.CreateOffer(nil)
+	assert.NoError(t, err)
+	offerGatheringComplete := webrtc.GatheringCompletePromise(offerer)
+	assert.NoError(t, offerer.SetLocalDescription(offer))
+	<-offerGatheringComplete
+	assert.NoError(t, answerer.SetRemoteDescription(*offerer.LocalDescription()))
+
+	answer, err := answerer.CreateAnswer(nil)
+	assert.NoError(t, err)
+	answerGatheringComplete := webrtc.GatheringCompletePromise(answerer)
+	assert.NoError(t, answerer.SetLocalDescription(answer))
+	<-answerGatheringComplete
+	assert.NoError(t, offerer.SetRemoteDescription(*answerer.LocalDescription()))
+}
+
+func scrape(t *testing.T, url string) string {
+	resp, err := http.Get(url) //nolint:gosec
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, resp.Body.Close())
+	}()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestCollector(t *testing.T) {
+	lim := test.TimeOut(time.Second * 20)
+	defer lim.Stop()
+
+	offerPC, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	assert.NoError(t, err)
+	answerPC, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	assert.NoError(t, err)
+
+	collector := NewCollector()
+	assert.NoError(t, collector.Register("offer", offerPC))
+	assert.NoError(t, collector.Register("answer", answerPC))
+	assert.True(t, errors.Is(collector.Register("offer", answerPC), ErrAlreadyRegistered))
+
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	metrics := scrape(t, server.URL)
+	assert.Contains(t, metrics, "# TYPE webrtc_ice_connection_state stateset\n")
+	assert.Contains(t, metrics, `webrtc_ice_connection_state{peer_connection="offer",webrtc_ice_connection_state="new"} 1`+"\n")
+	assert.Contains(t, metrics, `webrtc_ice_connection_state{peer_connection="offer",webrtc_ice_connection_state="connected"} 0`+"\n")
+	assert.Contains(t, metrics, `webrtc_data_channels_opened_total{peer_connection="answer"} 0`+"\n")
+	assert.True(t, strings.HasSuffix(metrics, "# EOF\n"))
+
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+	assert.NoError(t, err)
+	_, err = offerPC.AddTrack(track)
+	assert.NoError(t, err)
+	answerPC.OnTrack(func(track *webrtc.TrackRemote, r *webrtc.RTPReceiver) {
+		for {
+			if _, readErr := track.ReadRTP(); readErr != nil {
+				return
+			}
+		}
+	})
+
+	dc, err := offerPC.CreateDataChannel("data", nil)
+	assert.NoError(t, err)
+	opened := make(chan struct{})
+	dc.OnOpen(func() {
+		close(opened)
+	})
+
+	signalPair(t, offerPC, answerPC)
+	<-opened
+
+	for !strings.Contains(metrics, `webrtc_inbound_rtp_received_packets_total{peer_connection="answer"`) {
+		assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0x00}, Duration: 20 * time.Millisecond}))
+		time.Sleep(20 * time.Millisecond)
+		metrics = scrape(t, server.URL)
+	}
+	assert.Contains(t, metrics, `webrtc_outbound_rtp_sent_packets_total{peer_connection="offer",ssrc="`)
+	assert.Contains(t, metrics, `",kind="video"} `)
+	assert.Contains(t, metrics, `webrtc_peer_connection_state{peer_connection="offer",webrtc_peer_connection_state="connected"} 1`+"\n")
+	assert.Contains(t, metrics, `webrtc_data_channels_opened_total{peer_connection="offer"} 1`+"\n")
+	assert.Contains(t, metrics, `webrtc_data_channels_requested_total{peer_connection="offer"} 1`+"\n")
+	assert.Contains(t, metrics, "# TYPE webrtc_transport_sent_bytes counter\n")
+	assert.Contains(t, metrics, `webrtc_transport_sent_bytes_total{peer_connection="answer",transport="iceTransport"} `)
+	assert.NotContains(t, metrics, `webrtc_transport_sent_bytes_total{peer_connection="answer",transport="iceTransport"} 0`+"\n")
+
+	// The ICE agent doesn't measure the round trip time of candidate pairs
+	assert.NotContains(t, metrics, "webrtc_transport_round_trip_time_seconds")
+
+	// Samples of a family are written together
+	assert.Equal(t, 1, strings.Count(metrics, "# TYPE webrtc_data_channels_opened counter\n"))
+	familyStart := strings.Index(metrics, "# TYPE webrtc_data_channels_opened counter\n")
+	family := metrics[familyStart:]
+	family = family[:strings.Index(family[1:], "# TYPE")+1]
+	assert.Contains(t, family, `peer_connection="answer"`)
+	assert.Contains(t, family, `peer_connection="offer"`)
+
+	collector.Unregister("answer")
+	metrics = scrape(t, server.URL)
+	assert.NotContains(t, metrics, `peer_connection="answer"`)
+
+	assert.NoError(t, offerPC.Close())
+	assert.NoError(t, answerPC.Close())
+}
+
+func TestFamilies_Escaping(t *testing.T) {
+	f := newFamilies()
+	f.add("metric", metricTypeGauge, "help with \\ and \n", 1.5, label{"label", "value with \" and \\ and \n"})
+
+	var b strings.Builder
+	assert.NoError(t, f.writeTo(&b))
+	assert.Equal(t, "# TYPE metric gauge\n"+
+		"# HELP metric help with \\\\ and \\n\n"+
+		"metric{label=\"value with \\\" and \\\\ and \\n\"} 1.5\n"+
+		"# EOF\n", b.String())
+}
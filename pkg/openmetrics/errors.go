@@ -0,0 +1,8 @@
+// +build !js
+
+package openmetrics
+
+import "errors"
+
+// ErrAlreadyRegistered is returned when registering a PeerConnection with an ID already in use
+var ErrAlreadyRegistered = errors.New("a PeerConnection is already registered with this ID")
@@ -0,0 +1,108 @@
+// +build !js
+
+package openmetrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type metricType string
+
+const (
+	metricTypeCounter  metricType = "counter"
+	metricTypeGauge    metricType = "gauge"
+	metricTypeStateSet metricType = "stateset"
+)
+
+type label struct {
+	name, value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+// family is a metric family, all of its samples are written together
+type family struct {
+	name    string
+	typ     metricType
+	help    string
+	samples []sample
+}
+
+// families holds the metric families in the order they were first added
+type families struct {
+	byName map[string]*family
+	order  []*family
+}
+
+func newFamilies() *families {
+	return &families{byName: map[string]*family{}}
+}
+
+func (f *families) add(name string, typ metricType, help string, value float64, labels ...label) {
+	fam, ok := f.byName[name]
+	if !ok {
+		fam = &family{name: name, typ: typ, help: help}
+		f.byName[name] = fam
+		f.order = append(f.order, fam)
+	}
+	fam.samples = append(fam.samples, sample{labels: labels, value: value})
+}
+
+// addStateSet adds a sample for each of states, the one matching current is set to 1
+func (f *families) addStateSet(name, help string, states []fmt.Stringer, current fmt.Stringer, labels ...label) {
+	for _, state := range states {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+		f.add(name, metricTypeStateSet, help, value, append(labels[:len(labels):len(labels)], label{name, state.String()})...)
+	}
+}
+
+// writeTo writes the families in the OpenMetrics text format
+func (f *families) writeTo(w io.Writer) error {
+	var b strings.Builder
+	for _, fam := range f.order {
+		fmt.Fprintf(&b, "# TYPE %s %s\n", fam.name, fam.typ)
+		fmt.Fprintf(&b, "# HELP %s %s\n", fam.name, escapeHelp(fam.help))
+
+		name := fam.name
+		if fam.typ == metricTypeCounter {
+			name += "_total"
+		}
+		for _, s := range fam.samples {
+			b.WriteString(name)
+			if len(s.labels) != 0 {
+				b.WriteByte('{')
+				for i, l := range s.labels {
+					if i != 0 {
+						b.WriteByte(',')
+					}
+					fmt.Fprintf(&b, `%s="%s"`, l.name, escapeLabelValue(l.value))
+				}
+				b.WriteByte('}')
+			}
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+			b.WriteByte('\n')
+		}
+	}
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
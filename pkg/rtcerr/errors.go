@@ -158,3 +158,18 @@ func (e *RangeError) Error() string {
 func (e *RangeError) Unwrap() error {
 	return e.Err
 }
+
+// InvalidCharacterError indicates a string contains characters that are not allowed.
+type InvalidCharacterError struct {
+	Err error
+}
+
+func (e *InvalidCharacterError) Error() string {
+	return fmt.Sprintf("InvalidCharacterError: %v", e.Err)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, if err's type contains
+// an Unwrap method returning error. Otherwise, Unwrap returns nil.
+func (e *InvalidCharacterError) Unwrap() error {
+	return e.Err
+}
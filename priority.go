@@ -0,0 +1,76 @@
+package webrtc
+
+import (
+	"encoding/json"
+)
+
+// PriorityType indicates the priority of an encoding relative to others.
+type PriorityType int
+
+const (
+	// PriorityTypeVeryLow indicates the lowest priority.
+	PriorityTypeVeryLow PriorityType = iota + 1
+
+	// PriorityTypeLow indicates a low priority.
+	PriorityTypeLow
+
+	// PriorityTypeMedium indicates a medium priority.
+	PriorityTypeMedium
+
+	// PriorityTypeHigh indicates the highest priority.
+	PriorityTypeHigh
+)
+
+// This is done this way because of a linter.
+const (
+	priorityTypeVeryLowStr = "very-low"
+	priorityTypeLowStr     = "low"
+	priorityTypeMediumStr  = "medium"
+	priorityTypeHighStr    = "high"
+)
+
+func newPriorityType(raw string) PriorityType {
+	switch raw {
+	case priorityTypeVeryLowStr:
+		return PriorityTypeVeryLow
+	case priorityTypeLowStr:
+		return PriorityTypeLow
+	case priorityTypeMediumStr:
+		return PriorityTypeMedium
+	case priorityTypeHighStr:
+		return PriorityTypeHigh
+	default:
+		return PriorityType(Unknown)
+	}
+}
+
+func (p PriorityType) String() string {
+	switch p {
+	case PriorityTypeVeryLow:
+		return priorityTypeVeryLowStr
+	case PriorityTypeLow:
+		return priorityTypeLowStr
+	case PriorityTypeMedium:
+		return priorityTypeMediumStr
+	case PriorityTypeHigh:
+		return priorityTypeHighStr
+	default:
+		return ErrUnknownType.Error()
+	}
+}
+
+// MarshalJSON enables JSON marshaling of a PriorityType
+func (p PriorityType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON enables JSON unmarshaling of a PriorityType
+func (p *PriorityType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*p = newPriorityType(s)
+	return nil
+}
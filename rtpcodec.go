@@ -1,7 +1,31 @@
 package webrtc
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/headerextension"
+)
+
+const (
+	mimeTypeH264 = "video/h264"
+	mimeTypeOpus = "audio/opus"
+	mimeTypeVP8  = "video/vp8"
+	mimeTypeVP9  = "video/vp9"
+	mimeTypeAV1  = "video/av1"
+	mimeTypeH265 = "video/h265"
+	mimeTypeG722 = "audio/G722"
+	mimeTypePCMU = "audio/PCMU"
+	mimeTypePCMA = "audio/PCMA"
+	mimeTypeRED  = "audio/red"
+
+	mimeTypeTelephoneEvent = "audio/telephone-event"
+
+	mimeTypeULPFEC    = "video/ulpfec"
+	mimeTypeFlexFEC03 = "video/flexfec-03"
+	mimeTypeVideoRED  = "video/red"
 )
 
 // RTPCodecType determines the type of a codec
@@ -80,14 +104,21 @@ type RTCRtpCapabilities struct {
 // Do a fuzzy find for a codec in the list of codecs
 // Used for lookup up a codec in an existing list to find a match
 func codecParametersFuzzySearch(needle RTPCodecParameters, haystack []RTPCodecParameters) (RTPCodecParameters, error) {
+	needleFmtp := parseFmtp(needle.RTPCodecCapability.MimeType, needle.RTPCodecCapability.SDPFmtpLine)
+
 	// First attempt to match on MimeType + SDPFmtpLine
 	for _, c := range haystack {
-		if strings.EqualFold(c.RTPCodecCapability.MimeType, needle.RTPCodecCapability.MimeType) &&
-			c.RTPCodecCapability.SDPFmtpLine == needle.RTPCodecCapability.SDPFmtpLine {
+		if needleFmtp.match(parseFmtp(c.RTPCodecCapability.MimeType, c.RTPCodecCapability.SDPFmtpLine)) {
 			return c, nil
 		}
 	}
 
+	// Codecs with codec specific parameters only match if they are compatible. An empty
+	// SDPFmtpLine stands for their default parameters, it doesn't match any of them.
+	if _, ok := needleFmtp.(*genericFmtp); !ok {
+		return RTPCodecParameters{}, ErrCodecNotFound
+	}
+
 	// Fallback to just MimeType
 	for _, c := range haystack {
 		if strings.EqualFold(c.RTPCodecCapability.MimeType, needle.RTPCodecCapability.MimeType) {
@@ -97,3 +128,144 @@ func codecParametersFuzzySearch(needle RTPCodecParameters, haystack []RTPCodecPa
 
 	return RTPCodecParameters{}, ErrCodecNotFound
 }
+
+// codecCapabilityFuzzySearch finds the codec of haystack a local capability, of a track or a codec
+// preference, is sent or received with. The default parameters of an empty SDPFmtpLine only apply to
+// remote codecs: a local H264 capability without one matches the first H264 codec with
+// packetization-mode=1, the mode the H264 payloader sends.
+func codecCapabilityFuzzySearch(needle RTPCodecCapability, haystack []RTPCodecParameters) (RTPCodecParameters, error) {
+	if !strings.EqualFold(needle.MimeType, mimeTypeH264) || needle.SDPFmtpLine != "" {
+		return codecParametersFuzzySearch(RTPCodecParameters{RTPCodecCapability: needle}, haystack)
+	}
+
+	for _, c := range haystack {
+		if !strings.EqualFold(c.MimeType, mimeTypeH264) {
+			continue
+		}
+
+		if mode, ok := parseFmtp(c.MimeType, c.SDPFmtpLine).parameter("packetization-mode"); ok && mode == "1" {
+			return c, nil
+		}
+	}
+
+	return RTPCodecParameters{}, ErrCodecNotFound
+}
+
+// Given a CodecParameters find the RTX CodecParameters if one exists
+func findRTXPayloadType(needle PayloadType, haystack []RTPCodecParameters) PayloadType {
+	aptStr := fmt.Sprintf("apt=%d", needle)
+	for _, c := range haystack {
+		if aptStr == c.SDPFmtpLine {
+			return c.PayloadType
+		}
+	}
+
+	return PayloadType(0)
+}
+
+// redPrimaryPayloadType returns the payload type of the primary encoding of a RED codec,
+// the first one of its fmtp line "primary/redundant..."
+func redPrimaryPayloadType(codec RTPCodecCapability) (PayloadType, bool) {
+	if !strings.EqualFold(codec.MimeType, mimeTypeRED) {
+		return 0, false
+	}
+
+	payloadType, err := strconv.ParseUint(strings.TrimSpace(strings.Split(codec.SDPFmtpLine, "/")[0]), 10, 8)
+	if err != nil {
+		return 0, false
+	}
+
+	return PayloadType(payloadType), true
+}
+
+// findFECCodec returns the FlexFEC codec of codecs. ULPFEC is only sent inside RED, not as a separate stream
+func findFECCodec(codecs []RTPCodecParameters) (RTPCodecParameters, bool) {
+	for _, c := range codecs {
+		if strings.EqualFold(c.MimeType, mimeTypeFlexFEC03) {
+			return c, true
+		}
+	}
+
+	return RTPCodecParameters{}, false
+}
+
+// findULPFECCodecs returns the video RED and ULPFEC codecs of codecs, if there are both
+func findULPFECCodecs(codecs []RTPCodecParameters) (red, ulpfec RTPCodecParameters, ok bool) {
+	hasRED, hasULPFEC := false, false
+	for _, c := range codecs {
+		switch {
+		case strings.EqualFold(c.MimeType, mimeTypeVideoRED) && !hasRED:
+			red, hasRED = c, true
+		case strings.EqualFold(c.MimeType, mimeTypeULPFEC) && !hasULPFEC:
+			ulpfec, hasULPFEC = c, true
+		}
+	}
+
+	return red, ulpfec, hasRED && hasULPFEC
+}
+
+// RTPHeaderExtensionParameter represents a negotiated RFC5285 RTP header extension.
+//
+// https://w3c.github.io/webrtc-pc/#dictionary-rtcrtpheaderextensionparameters-members
+type RTPHeaderExtensionParameter struct {
+	URI string
+	ID  int
+}
+
+// Limits of the one-byte header extensions, and the profiles of both kinds (RFC 8285)
+const (
+	rtpOneByteHeaderMaxID      = 14
+	rtpOneByteHeaderMaxSize    = 16
+	rtpExtensionProfileOneByte = 0xBEDE
+	rtpExtensionProfileTwoByte = 0x1000
+)
+
+// headerExtensionID returns the ID negotiated for the header extension with the given URI
+func headerExtensionID(headerExtensions []RTPHeaderExtensionParameter, uri string) (uint8, bool) {
+	for _, h := range headerExtensions {
+		if h.URI == uri {
+			return uint8(h.ID), true
+		}
+	}
+
+	return 0, false
+}
+
+// setHeaderExtension sets extension on header, with the ID negotiated for its URI
+func setHeaderExtension(header *rtp.Header, headerExtensions []RTPHeaderExtensionParameter, extension headerextension.Extension) error {
+	id, ok := headerExtensionID(headerExtensions, extension.URI())
+	if !ok {
+		return ErrHeaderExtensionNotNegotiated
+	}
+
+	payload, err := extension.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// IDs above 14 and payloads longer than 16 bytes need the two-byte header (RFC 8285), which
+	// can hold the extensions of the one-byte header too
+	if id > rtpOneByteHeaderMaxID || len(payload) > rtpOneByteHeaderMaxSize {
+		if !header.Extension || header.ExtensionProfile == rtpExtensionProfileOneByte {
+			header.Extension, header.ExtensionProfile = true, rtpExtensionProfileTwoByte
+		}
+	}
+
+	return header.SetExtension(id, payload)
+}
+
+// getHeaderExtension parses extension from header, with the ID negotiated for its URI.
+// It returns false if the header doesn't carry the extension
+func getHeaderExtension(header *rtp.Header, headerExtensions []RTPHeaderExtensionParameter, extension headerextension.Extension) (bool, error) {
+	id, ok := headerExtensionID(headerExtensions, extension.URI())
+	if !ok {
+		return false, ErrHeaderExtensionNotNegotiated
+	}
+
+	payload := header.GetExtension(id)
+	if payload == nil {
+		return false, nil
+	}
+
+	return true, extension.Unmarshal(payload)
+}
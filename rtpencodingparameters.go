@@ -5,4 +5,26 @@ package webrtc
 // http://draft.ortc.org/#dom-rtcrtpencodingparameters
 type RTPEncodingParameters struct {
 	RTPCodingParameters
+
+	// Active controls if the encoding is sent. Encodings always start active,
+	// they are paused and resumed with RTPSender.SetParameters
+	Active bool `json:"active"`
+
+	// The following don't change what is sent, as Pion WebRTC doesn't encode media.
+	// They are for the application feeding the TrackLocal of the encoding.
+
+	// MaxBitrate is the maximum bitrate in bits per second, or 0 for no limit
+	MaxBitrate uint64 `json:"maxBitrate"`
+
+	// MaxFramerate is the maximum number of frames per second, or 0 for no limit
+	MaxFramerate float64 `json:"maxFramerate"`
+
+	// ScaleResolutionDownBy is the factor the resolution of the video is scaled down by, or 0 if unset
+	ScaleResolutionDownBy float64 `json:"scaleResolutionDownBy"`
+
+	// Priority is the priority of the encoding relative to the other encodings of the PeerConnection
+	Priority PriorityType `json:"priority"`
+
+	// NetworkPriority is the priority of the encoding for the network, used to set DSCP markings
+	NetworkPriority PriorityType `json:"networkPriority"`
 }
@@ -3,20 +3,44 @@
 package webrtc
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
 	"github.com/pion/srtp"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/interceptor/fec"
 )
 
 // trackStreams maintains a mapping of RTP/RTCP streams to a specific track
 // a RTPReceiver may contain multiple streams if we are dealing with Multicast
 type trackStreams struct {
-	track          *TrackRemote
+	track *TrackRemote
+
+	streamInfo interceptor.StreamInfo
+
 	rtpReadStream  *srtp.ReadStreamSRTP
-	rtcpReadStream *srtp.ReadStreamSRTCP
+	rtpInterceptor interceptor.RTPReader
+
+	// Set once the Interceptors are bound to the RTP stream, which waits for its PayloadType to be known
+	rtpInterceptorBound bool
+
+	rtcpReadStream  *srtp.ReadStreamSRTCP
+	rtcpInterceptor interceptor.RTCPReader
+
+	// Packets from the RTX stream, already unwrapped into the original packet
+	repairReadStream    *srtp.ReadStreamSRTP
+	repairStreamChannel chan []byte
+
+	// Packets from the FEC stream, the packets they recover are sent to repairStreamChannel
+	fecReadStream *srtp.ReadStreamSRTP
+	fecDecoder    *fec.Decoder
 }
 
 // RTPReceiver allows an application to inspect the receipt of a TrackRemote
@@ -31,6 +55,10 @@ type RTPReceiver struct {
 
 	// A reference to the associated api object
 	api *API
+
+	// rtpTransceiver is the *RTPTransceiver the RTPReceiver belongs to, its codec
+	// preferences restrict the codecs that can be received
+	rtpTransceiver atomic.Value
 }
 
 // NewRTPReceiver constructs a new RTPReceiver
@@ -49,6 +77,20 @@ func (api *API) NewRTPReceiver(kind RTPCodecType, transport *DTLSTransport) (*RT
 	}, nil
 }
 
+func (r *RTPReceiver) setRTPTransceiver(t *RTPTransceiver) {
+	r.rtpTransceiver.Store(t)
+}
+
+// getCodecs returns the codecs negotiated for the RTPTransceiver of the RTPReceiver,
+// or those of the MediaEngine if it doesn't belong to one
+func (r *RTPReceiver) getCodecs() []RTPCodecParameters {
+	if t, ok := r.rtpTransceiver.Load().(*RTPTransceiver); ok {
+		return t.getCodecs(r.api.mediaEngine)
+	}
+
+	return r.api.mediaEngine.getCodecsByKind(r.kind)
+}
+
 // Transport returns the currently-configured *DTLSTransport or nil
 // if one has not yet been configured
 func (r *RTPReceiver) Transport() *DTLSTransport {
@@ -99,14 +141,35 @@ func (r *RTPReceiver) Receive(parameters RTPReceiveParameters) error {
 				ssrc:     parameters.Encodings[0].SSRC,
 				receiver: r,
 			},
+			repairStreamChannel: make(chan []byte, repairStreamChannelSize),
+		}
+
+		fecSsrc := parameters.Encodings[0].FEC.SSRC
+		if fecSsrc != 0 {
+			t.fecDecoder = fec.NewDecoder(uint32(parameters.Encodings[0].SSRC))
+		} else {
+			t.fecDecoder = r.ulpfecDecoder(parameters.Encodings[0].SSRC)
 		}
 
+		// The PayloadType isn't known until the first packet arrives, the Interceptors are bound
+		// by setStreamCodec once it is. Until then the stream is read without them
 		var err error
-		t.rtpReadStream, t.rtcpReadStream, err = r.streamsForSSRC(parameters.Encodings[0].SSRC)
-		if err != nil {
+		if t.rtpReadStream, t.rtpInterceptor, t.rtcpReadStream, t.rtcpInterceptor, err = r.streamsForSSRC(parameters.Encodings[0].SSRC, t.repairStreamChannel, t.fecDecoder); err != nil {
 			return err
 		}
 
+		if rtxSsrc := parameters.Encodings[0].RTX.SSRC; rtxSsrc != 0 {
+			if t.repairReadStream, err = r.repairStreamForSSRC(rtxSsrc, t.track, t.repairStreamChannel); err != nil {
+				return err
+			}
+		}
+
+		if fecSsrc != 0 {
+			if t.fecReadStream, err = r.fecStreamForSSRC(fecSsrc, t.fecDecoder, t.repairStreamChannel); err != nil {
+				return err
+			}
+		}
+
 		r.tracks = append(r.tracks, t)
 	} else {
 		for _, encoding := range parameters.Encodings {
@@ -116,6 +179,7 @@ func (r *RTPReceiver) Receive(parameters RTPReceiveParameters) error {
 					rid:      encoding.RID,
 					receiver: r,
 				},
+				repairStreamChannel: make(chan []byte, repairStreamChannelSize),
 			})
 		}
 	}
@@ -127,7 +191,8 @@ func (r *RTPReceiver) Receive(parameters RTPReceiveParameters) error {
 func (r *RTPReceiver) Read(b []byte) (n int, err error) {
 	select {
 	case <-r.received:
-		return r.tracks[0].rtcpReadStream.Read(b)
+		n, _, err = r.tracks[0].rtcpInterceptor.Read(b, interceptor.Attributes{})
+		return n, err
 	case <-r.closed:
 		return 0, io.ErrClosedPipe
 	}
@@ -138,8 +203,9 @@ func (r *RTPReceiver) ReadSimulcast(b []byte, rid string) (n int, err error) {
 	select {
 	case <-r.received:
 		for _, t := range r.tracks {
-			if t.track != nil && t.track.rid == rid {
-				return t.rtcpReadStream.Read(b)
+			if t.track != nil && t.track.rid == rid && t.rtcpInterceptor != nil {
+				n, _, err = t.rtcpInterceptor.Read(b, interceptor.Attributes{})
+				return n, err
 			}
 		}
 		return 0, fmt.Errorf("%w: %s", errRTPReceiverForRIDTrackStreamNotFound, rid)
@@ -179,6 +245,100 @@ func (r *RTPReceiver) haveReceived() bool {
 	}
 }
 
+// GetStats returns the stats of the streams received by the RTPReceiver, along
+// with the stats of their codecs and of the transport they are received over
+func (r *RTPReceiver) GetStats() StatsReport {
+	collector := newStatsReportCollector()
+	if !r.haveReceived() {
+		return collector.Ready()
+	}
+
+	r.collectStats(collector)
+
+	r.mu.RLock()
+	for i := range r.tracks {
+		collectCodecStats(collector, r.tracks[i].track.Codec())
+	}
+	transport := r.transport
+	r.mu.RUnlock()
+
+	if transport != nil {
+		transport.collectStats(collector)
+		if iceTransport := transport.ICETransport(); iceTransport != nil {
+			iceTransport.collectSelectedCandidatePairStats(collector)
+		}
+	}
+
+	return collector.Ready()
+}
+
+// collectStats collects the statistics of the streams received by the RTPReceiver, and the
+// statistics of the remote about them
+func (r *RTPReceiver) collectStats(collector *statsReportCollector) {
+	if r.api.statsInterceptor == nil || !r.haveReceived() {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.tracks {
+		track := r.tracks[i].track
+		ssrc := track.SSRC()
+		s, ok := r.api.statsInterceptor.GetInboundStats(uint32(ssrc))
+		if !ok {
+			continue
+		}
+
+		kind := track.Kind().String()
+		codecID := track.Codec().statsID
+
+		collector.Collecting()
+		inbound := InboundRTPStreamStats{
+			Timestamp:           statsTimestampNow(),
+			Type:                StatsTypeInboundRTP,
+			ID:                  inboundRTPStreamStatsID(ssrc),
+			SSRC:                ssrc,
+			Kind:                kind,
+			TransportID:         "iceTransport",
+			CodecID:             codecID,
+			FIRCount:            s.FIRCount,
+			PLICount:            s.PLICount,
+			NACKCount:           s.NACKCount,
+			PacketsReceived:     s.PacketsReceived,
+			PacketsLost:         s.PacketsLost,
+			Jitter:              s.Jitter,
+			BytesReceived:       s.BytesReceived,
+			HeaderBytesReceived: s.HeaderBytesReceived,
+			FramesReceived:      s.FramesReceived,
+		}
+		if !s.LastPacketReceived.IsZero() {
+			inbound.LastPacketReceivedTimestamp = statsTimestampFrom(s.LastPacketReceived)
+		}
+
+		if s.RemoteOutbound.ReportsReceived != 0 {
+			collector.Collecting()
+			remote := RemoteOutboundRTPStreamStats{
+				Timestamp:       statsTimestampFrom(s.RemoteOutbound.Timestamp),
+				Type:            StatsTypeRemoteOutboundRTP,
+				ID:              remoteOutboundRTPStreamStatsID(ssrc),
+				SSRC:            ssrc,
+				Kind:            kind,
+				TransportID:     "iceTransport",
+				CodecID:         codecID,
+				PacketsSent:     s.RemoteOutbound.PacketsSent,
+				BytesSent:       s.RemoteOutbound.BytesSent,
+				LocalID:         inbound.ID,
+				RemoteTimestamp: statsTimestampFrom(s.RemoteOutbound.RemoteTimestamp),
+			}
+			inbound.RemoteID = remote.ID
+			collector.Collect(remote.ID, remote)
+		}
+
+		collector.Collect(inbound.ID, inbound)
+	}
+}
+
 // Stop irreversibly stops the RTPReceiver
 func (r *RTPReceiver) Stop() error {
 	r.mu.Lock()
@@ -202,6 +362,20 @@ func (r *RTPReceiver) Stop() error {
 				if err := r.tracks[i].rtpReadStream.Close(); err != nil {
 					return err
 				}
+
+				if r.tracks[i].rtpInterceptorBound {
+					r.api.interceptor.UnbindRemoteStream(&r.tracks[i].streamInfo)
+				}
+			}
+			if r.tracks[i].repairReadStream != nil {
+				if err := r.tracks[i].repairReadStream.Close(); err != nil {
+					return err
+				}
+			}
+			if r.tracks[i].fecReadStream != nil {
+				if err := r.tracks[i].fecReadStream.Close(); err != nil {
+					return err
+				}
 			}
 		}
 	default:
@@ -220,11 +394,70 @@ func (r *RTPReceiver) streamsForTrack(t *TrackRemote) *trackStreams {
 	return nil
 }
 
-// readRTP should only be called by a track, this only exists so we can keep state in one place
+// setStreamCodec binds the Interceptors to the stream of track, which was received before its
+// PayloadType was known, with a StreamInfo describing codec. The packet peeked to determine the
+// PayloadType is handed to the Interceptors first, so they see every packet of the stream.
+func (r *RTPReceiver) setStreamCodec(track *TrackRemote, codec RTPCodecParameters) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.closed:
+		return
+	default:
+	}
+
+	t := r.streamsForTrack(track)
+	if t == nil || t.rtpReadStream == nil || t.rtpInterceptorBound {
+		return
+	}
+
+	track.mu.Lock()
+	peeked := track.peeked
+	track.peeked = nil
+	track.mu.Unlock()
+
+	headerExtensions := headerExtensionParameters(r.api.mediaEngine.negotiatedHeaderExtensionsForType(r.kind))
+	t.streamInfo = createStreamInfo("", track.SSRC(), codec.PayloadType, codec.RTPCodecCapability, headerExtensions)
+
+	reader := t.rtpInterceptor
+	t.rtpInterceptorBound = true
+	t.rtpInterceptor = r.api.interceptor.BindRemoteStream(&t.streamInfo, interceptor.RTPReaderFunc(func(in []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		if peeked != nil {
+			if len(peeked) > len(in) {
+				return 0, a, io.ErrShortBuffer
+			}
+			n := copy(in, peeked)
+			peeked = nil
+			return n, a, nil
+		}
+
+		return reader.Read(in, a)
+	}))
+}
+
+// readRTP should only be called by a track, this only exists so we can keep state in one place.
+// ULPFEC packets are read through the Interceptors like the media packets, but they aren't returned
 func (r *RTPReceiver) readRTP(b []byte, reader *TrackRemote) (n int, err error) {
 	<-r.received
-	if t := r.streamsForTrack(reader); t != nil {
-		return t.rtpReadStream.Read(b)
+
+	r.mu.RLock()
+	t := r.streamsForTrack(reader)
+	r.mu.RUnlock()
+
+	if t != nil {
+		for {
+			// setStreamCodec replaces the reader once the Interceptors are bound
+			r.mu.RLock()
+			rtpInterceptor := t.rtpInterceptor
+			fecDecoder := t.fecDecoder
+			r.mu.RUnlock()
+
+			n, _, err = rtpInterceptor.Read(b, interceptor.Attributes{})
+			if err != nil || fecDecoder == nil || !fecDecoder.IsFEC(b[:n]) {
+				return n, err
+			}
+		}
 	}
 
 	return 0, fmt.Errorf("%w: %d", errRTPReceiverWithSSRCTrackStreamNotFound, reader.SSRC())
@@ -240,16 +473,24 @@ func (r *RTPReceiver) receiveForRid(rid string, codec RTPCodecParameters, ssrc S
 		if r.tracks[i].track.RID() == rid {
 			r.tracks[i].track.mu.Lock()
 			r.tracks[i].track.kind = r.kind
-			r.tracks[i].track.codec = codec
+			r.tracks[i].track.payloadType = codec.PayloadType
 			r.tracks[i].track.ssrc = ssrc
 			r.tracks[i].track.mu.Unlock()
+			r.tracks[i].track.setCodec(codec, r.api.mediaEngine)
+
+			headerExtensions := headerExtensionParameters(r.api.mediaEngine.negotiatedHeaderExtensionsForType(r.kind))
+			r.tracks[i].streamInfo = createStreamInfo("", ssrc, codec.PayloadType, codec.RTPCodecCapability, headerExtensions)
+
+			r.tracks[i].fecDecoder = r.ulpfecDecoder(ssrc)
 
 			var err error
-			r.tracks[i].rtpReadStream, r.tracks[i].rtcpReadStream, err = r.streamsForSSRC(ssrc)
-			if err != nil {
+			if r.tracks[i].rtpReadStream, r.tracks[i].rtpInterceptor, r.tracks[i].rtcpReadStream, r.tracks[i].rtcpInterceptor, err = r.streamsForSSRC(ssrc, r.tracks[i].repairStreamChannel, r.tracks[i].fecDecoder); err != nil {
 				return nil, err
 			}
 
+			r.tracks[i].rtpInterceptorBound = true
+			r.tracks[i].rtpInterceptor = r.api.interceptor.BindRemoteStream(&r.tracks[i].streamInfo, r.tracks[i].rtpInterceptor)
+
 			return r.tracks[i].track, nil
 		}
 	}
@@ -257,26 +498,247 @@ func (r *RTPReceiver) receiveForRid(rid string, codec RTPCodecParameters, ssrc S
 	return nil, fmt.Errorf("%w: %d", errRTPReceiverForSSRCTrackStreamNotFound, ssrc)
 }
 
-func (r *RTPReceiver) streamsForSSRC(ssrc SSRC) (*srtp.ReadStreamSRTP, *srtp.ReadStreamSRTCP, error) {
+// receiveForRtx is the sibling of receiveForRid for RTX streams that are only identified by the
+// repaired-rtp-stream-id header extension. It attaches the repair stream to the track with the given RID
+func (r *RTPReceiver) receiveForRtx(rsid string, ssrc SSRC) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.tracks {
+		if r.tracks[i].track.RID() != rsid {
+			continue
+		}
+
+		if r.tracks[i].repairReadStream != nil {
+			return fmt.Errorf("%w: %s", errRTPReceiverRepairStreamAlreadyExists, rsid)
+		}
+
+		var err error
+		r.tracks[i].repairReadStream, err = r.repairStreamForSSRC(ssrc, r.tracks[i].track, r.tracks[i].repairStreamChannel)
+		return err
+	}
+
+	return fmt.Errorf("%w: %d", errRTPReceiverForSSRCTrackStreamNotFound, ssrc)
+}
+
+// repairStreamForSSRC opens the RTX stream ssrc and starts unwrapping its packets into repairStreamChannel,
+// where they are picked up by the reader of track
+func (r *RTPReceiver) repairStreamForSSRC(ssrc SSRC, track *TrackRemote, repairStreamChannel chan []byte) (*srtp.ReadStreamSRTP, error) {
 	srtpSession, err := r.transport.getSRTPSession()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	repairReadStream, err := srtpSession.OpenReadStream(uint32(ssrc))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		b := make([]byte, receiveMTU)
+		for {
+			i, err := repairReadStream.Read(b)
+			if err != nil {
+				return
+			}
+
+			pkt, ok := r.unwrapRTX(b[:i], track)
+			if !ok {
+				continue
+			}
+
+			// A full channel means the track isn't being read, drop the retransmission
+			select {
+			case repairStreamChannel <- pkt:
+			default:
+			}
+		}
+	}()
+
+	return repairReadStream, nil
+}
+
+// fecStreamForSSRC opens the FEC stream ssrc and starts recovering lost packets from it into repairStreamChannel,
+// where they are picked up like the packets of the RTX stream. The decoder only recovers the packets the track
+// was read past, the others are recovered once the track is read further
+func (r *RTPReceiver) fecStreamForSSRC(ssrc SSRC, decoder *fec.Decoder, repairStreamChannel chan []byte) (*srtp.ReadStreamSRTP, error) {
+	srtpSession, err := r.transport.getSRTPSession()
+	if err != nil {
+		return nil, err
+	}
+
+	fecReadStream, err := srtpSession.OpenReadStream(uint32(ssrc))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		b := make([]byte, receiveMTU)
+		header := &rtp.Header{}
+		for {
+			i, err := fecReadStream.Read(b)
+			if err != nil {
+				return
+			}
+
+			if err = header.Unmarshal(b[:i]); err != nil {
+				continue
+			}
+
+			codec, err := r.api.mediaEngine.getCodecByPayload(PayloadType(header.PayloadType))
+			if err != nil {
+				continue
+			}
+
+			pkt, err := decoder.Recover(codec.MimeType, b[header.PayloadOffset:i])
+			if err != nil || pkt == nil {
+				continue
+			}
+
+			// A full channel means the track isn't being read, drop the recovered packet
+			select {
+			case repairStreamChannel <- pkt:
+			default:
+			}
+		}
+	}()
+
+	return fecReadStream, nil
+}
+
+// unwrapRTX restores the original packet from a packet in the RTX payload format
+// https://tools.ietf.org/html/rfc4588#section-4
+func (r *RTPReceiver) unwrapRTX(b []byte, track *TrackRemote) ([]byte, bool) {
+	header := &rtp.Header{}
+	if err := header.Unmarshal(b); err != nil {
+		return nil, false
+	}
+
+	payload := b[header.PayloadOffset:]
+	if len(payload) == 0 {
+		return nil, false
+	}
+	if header.Padding {
+		paddingLen := int(payload[len(payload)-1])
+		if paddingLen > len(payload) {
+			return nil, false
+		}
+		payload = payload[:len(payload)-paddingLen]
+		header.Padding = false
+	}
+
+	// Packets without an original sequence number are only padding, used for probing
+	if len(payload) <= 2 {
+		return nil, false
+	}
+
+	codec, err := r.api.mediaEngine.getCodecByPayload(PayloadType(header.PayloadType))
+	if err != nil || !isRTXMimeType(codec.MimeType) {
+		return nil, false
+	}
+	apt, err := strconv.ParseUint(strings.TrimPrefix(codec.SDPFmtpLine, "apt="), 10, 8)
+	if err != nil {
+		return nil, false
+	}
+
+	ssrc := track.SSRC()
+	if ssrc == 0 {
+		return nil, false
+	}
+
+	header.SSRC = uint32(ssrc)
+	header.PayloadType = uint8(apt)
+	header.SequenceNumber = binary.BigEndian.Uint16(payload)
+
+	raw, err := header.Marshal()
+	if err != nil {
+		return nil, false
+	}
+
+	return append(raw, payload[2:]...), true
+}
+
+// ulpfecDecoder returns a Decoder recovering the packets of the stream ssrc with ULPFEC, if it was negotiated
+func (r *RTPReceiver) ulpfecDecoder(ssrc SSRC) *fec.Decoder {
+	redCodec, ulpfecCodec, ok := findULPFECCodecs(r.getCodecs())
+	if !ok {
+		return nil
+	}
+
+	return fec.NewULPFECDecoder(uint32(ssrc), uint8(redCodec.PayloadType), uint8(ulpfecCodec.PayloadType))
+}
+
+// streamsForSSRC opens the RTP and RTCP streams of ssrc. Every packet read from the RTP stream is also given to
+// fecDecoder, if FEC was negotiated, and the packets it recovers are sent to repairStreamChannel. The RTP
+// reader returned isn't bound to the Interceptors yet, it is left to the caller
+func (r *RTPReceiver) streamsForSSRC(ssrc SSRC, repairStreamChannel chan []byte, fecDecoder *fec.Decoder) (*srtp.ReadStreamSRTP, interceptor.RTPReader, *srtp.ReadStreamSRTCP, interceptor.RTCPReader, error) {
+	srtpSession, err := r.transport.getSRTPSession()
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	rtpReadStream, err := srtpSession.OpenReadStream(uint32(ssrc))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
+	rtpReader := rtpReaderForStream(rtpReadStream, repairStreamChannel, fecDecoder)
+
 	srtcpSession, err := r.transport.getSRTCPSession()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	rtcpReadStream, err := srtcpSession.OpenReadStream(uint32(ssrc))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
+	}
+
+	rtcpInterceptor := r.api.interceptor.BindRTCPReader(interceptor.RTCPReaderFunc(func(in []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, err := rtcpReadStream.Read(in)
+		return n, a, err
+	}))
+
+	return rtpReadStream, rtpReader, rtcpReadStream, rtcpInterceptor, nil
+}
+
+// rtpReaderForStream reads the packets of rtpReadStream and the packets of repairStreamChannel. Every packet
+// read is also given to fecDecoder, if FEC was negotiated, and the packets it recovers are sent to repairStreamChannel.
+// The media packets sent inside RED with ULPFEC are unwrapped by fecDecoder
+func rtpReaderForStream(rtpReadStream *srtp.ReadStreamSRTP, repairStreamChannel chan []byte, fecDecoder *fec.Decoder) interceptor.RTPReader {
+	pushToFECDecoder := func(in []byte, n int) int {
+		if fecDecoder == nil {
+			return n
+		}
+
+		pkt, recovered := fecDecoder.Push(in[:n])
+		for _, r := range recovered {
+			// A full channel means the track isn't being read, drop the recovered packet
+			select {
+			case repairStreamChannel <- r:
+			default:
+			}
+		}
+
+		return copy(in, pkt)
 	}
 
-	return rtpReadStream, rtcpReadStream, nil
+	return interceptor.RTPReaderFunc(func(in []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		// Repaired packets are handed out before the next packet of the stream,
+		// so they pass the Interceptors like any other packet
+		select {
+		case pkt := <-repairStreamChannel:
+			if len(pkt) > len(in) {
+				return 0, a, io.ErrShortBuffer
+			}
+			return pushToFECDecoder(in, copy(in, pkt)), a, nil
+		default:
+		}
+
+		n, err := rtpReadStream.Read(in)
+		if err == nil {
+			n = pushToFECDecoder(in, n)
+		}
+		return n, a, err
+	})
 }
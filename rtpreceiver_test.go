@@ -0,0 +1,48 @@
+// +build !js
+
+package webrtc
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTPReceiver_UnwrapRTX(t *testing.T) {
+	m := &MediaEngine{}
+	m.negotiatedVideoCodecs = []RTPCodecParameters{
+		{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeVP8, ClockRate: 90000}, PayloadType: 96},
+		{RTPCodecCapability: RTPCodecCapability{MimeType: "video/rtx", ClockRate: 90000, SDPFmtpLine: "apt=96"}, PayloadType: 97},
+	}
+	r := &RTPReceiver{api: NewAPI(WithMediaEngine(m))}
+	track := &TrackRemote{ssrc: 5000}
+
+	marshal := func(header rtp.Header, payload []byte) []byte {
+		b, err := header.Marshal()
+		assert.NoError(t, err)
+		return append(b, payload...)
+	}
+
+	// Padding bit set, without any payload or padding bytes
+	_, ok := r.unwrapRTX(marshal(rtp.Header{Version: 2, Padding: true, PayloadType: 97, SSRC: 6000}, nil), track)
+	assert.False(t, ok)
+
+	// Too short to hold the original sequence number
+	_, ok = r.unwrapRTX(marshal(rtp.Header{Version: 2, PayloadType: 97, SSRC: 6000}, []byte{0x01}), track)
+	assert.False(t, ok)
+
+	// Only padding
+	_, ok = r.unwrapRTX(marshal(rtp.Header{Version: 2, Padding: true, PayloadType: 97, SSRC: 6000}, []byte{0x00, 0x00, 0x03}), track)
+	assert.False(t, ok)
+
+	raw, ok := r.unwrapRTX(marshal(rtp.Header{Version: 2, PayloadType: 97, SSRC: 6000, SequenceNumber: 1}, []byte{0x12, 0x34, 0xAA, 0xBB}), track)
+	assert.True(t, ok)
+
+	p := &rtp.Packet{}
+	assert.NoError(t, p.Unmarshal(raw))
+	assert.Equal(t, uint32(5000), p.SSRC)
+	assert.Equal(t, uint8(96), p.PayloadType)
+	assert.Equal(t, uint16(0x1234), p.SequenceNumber)
+	assert.Equal(t, []byte{0xAA, 0xBB}, p.Payload)
+}
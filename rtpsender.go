@@ -3,26 +3,51 @@
 package webrtc
 
 import (
+	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pion/randutil"
 	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
 	"github.com/pion/srtp"
+	"github.com/pion/webrtc/v3/internal/util"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
 )
 
+// trackEncoding is a single encoding of a RTPSender. A RTPSender sending Simulcast
+// has an encoding per RID, each of them bound to its own TrackLocal
+type trackEncoding struct {
+	track TrackLocal
+
+	// parameters holds the SSRCs and RID of the encoding, and the controls
+	// that can be changed with SetParameters
+	parameters RTPEncodingParameters
+	codec      RTPCodecParameters
+
+	writeStream *interceptorToTrackLocalWriter
+	streamInfo  interceptor.StreamInfo
+
+	rtcpReadStream  *srtp.ReadStreamSRTCP
+	rtcpInterceptor interceptor.RTCPReader
+}
+
 // RTPSender allows an application to control how a given Track is encoded and transmitted to a remote peer
 type RTPSender struct {
-	track TrackLocal
+	kind           RTPCodecType
+	trackEncodings []*trackEncoding
 
-	rtcpReadStream *srtp.ReadStreamSRTCP
 	rtpWriteStream *srtp.WriteStreamSRTP
 
 	transport *DTLSTransport
 
-	payloadType PayloadType
-	ssrc        SSRC
-	codec       RTPCodecParameters
+	mid string
+
+	// dtmf sends DTMF tones on the track of an audio RTPSender
+	dtmf *DTMFSender
 
 	// nolint:godox
 	// TODO(sgotti) remove this when in future we'll avoid replacing
@@ -34,6 +59,10 @@ type RTPSender struct {
 	api *API
 	id  string
 
+	// rtpTransceiver is the *RTPTransceiver the RTPSender belongs to, its codec preferences
+	// restrict the codecs the Track is bound with
+	rtpTransceiver atomic.Value
+
 	mu                     sync.RWMutex
 	sendCalled, stopCalled chan interface{}
 }
@@ -51,15 +80,163 @@ func (api *API) NewRTPSender(track TrackLocal, transport *DTLSTransport) (*RTPSe
 		return nil, err
 	}
 
-	return &RTPSender{
-		track:      track,
+	r := &RTPSender{
+		kind:       track.Kind(),
 		transport:  transport,
 		api:        api,
 		sendCalled: make(chan interface{}),
 		stopCalled: make(chan interface{}),
-		ssrc:       SSRC(randutil.NewMathRandomGenerator().Uint32()),
 		id:         id,
-	}, nil
+	}
+	r.addEncoding(RTPEncodingParameters{}, track)
+	if r.kind == RTPCodecTypeAudio {
+		r.dtmf = newDTMFSender(r)
+	}
+
+	return r, nil
+}
+
+// newSimulcastRTPSender constructs a RTPSender with an encoding for each of the given RIDs.
+// track is bound to the first encoding, the others don't send until a track is set with ReplaceSimulcastTrack
+func (api *API) newSimulcastRTPSender(track TrackLocal, transport *DTLSTransport, encodings []RTPEncodingParameters) (*RTPSender, error) {
+	rids := map[string]bool{}
+	for _, encoding := range encodings {
+		if encoding.RID == "" {
+			return nil, errRTPSenderRIDNil
+		} else if rids[encoding.RID] {
+			return nil, fmt.Errorf("%w: %s", errRTPSenderRIDCollision, encoding.RID)
+		}
+		rids[encoding.RID] = true
+	}
+
+	r, err := api.NewRTPSender(track, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	r.trackEncodings = nil
+	for i, encoding := range encodings {
+		if i == 0 {
+			r.addEncoding(encoding, track)
+		} else {
+			r.addEncoding(encoding, nil)
+		}
+	}
+
+	return r, nil
+}
+
+// addEncoding adds an encoding sending track. Missing SSRCs are generated, the FEC
+// SSRC only if FEC packets are sent
+func (r *RTPSender) addEncoding(encoding RTPEncodingParameters, track TrackLocal) {
+	randomGenerator := randutil.NewMathRandomGenerator()
+	if encoding.SSRC == 0 {
+		encoding.SSRC = SSRC(randomGenerator.Uint32())
+	}
+	if encoding.RTX.SSRC == 0 {
+		encoding.RTX.SSRC = SSRC(randomGenerator.Uint32())
+	}
+	switch {
+	case !r.api.sendsFEC || encoding.RID != "":
+		encoding.FEC.SSRC = 0
+	case encoding.FEC.SSRC == 0:
+		encoding.FEC.SSRC = SSRC(randomGenerator.Uint32())
+	}
+
+	encoding.Active = true
+
+	r.trackEncodings = append(r.trackEncodings, &trackEncoding{
+		track:      track,
+		parameters: encoding,
+	})
+}
+
+// getEncodingParameters returns the parameters the encodings of this RTPSender are sent with
+func (r *RTPSender) getEncodingParameters() []RTPEncodingParameters {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encodings := make([]RTPEncodingParameters, 0, len(r.trackEncodings))
+	for _, e := range r.trackEncodings {
+		encoding := e.parameters
+		encoding.PayloadType = e.codec.PayloadType
+		encodings = append(encodings, encoding)
+	}
+
+	return encodings
+}
+
+// DTMF returns the DTMFSender sending DTMF tones on the track of the RTPSender,
+// or nil if the RTPSender isn't sending audio
+func (r *RTPSender) DTMF() *DTMFSender {
+	return r.dtmf
+}
+
+// GetParameters describes the current configuration for the encoding and
+// transmission of media on the sender's track.
+func (r *RTPSender) GetParameters() RTPSendParameters {
+	return newRTPSendParameters(r.getEncodingParameters())
+}
+
+// SetParameters updates how the sender's tracks are sent. Only the controls of the encodings,
+// like Active and MaxBitrate, can be changed. This doesn't require renegotiation.
+// The encodings must be in the order, and have the RIDs and SSRCs, returned by GetParameters.
+func (r *RTPSender) SetParameters(parameters RTPSendParameters) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.stopCalled:
+		return &rtcerr.InvalidStateError{Err: errRTPSenderStopped}
+	default:
+	}
+
+	encodings := parameters.encodings()
+	if len(encodings) != len(r.trackEncodings) {
+		return &rtcerr.InvalidModificationError{Err: ErrModifyingSendEncodings}
+	}
+
+	for i, encoding := range encodings {
+		current := r.trackEncodings[i].parameters
+		switch {
+		case encoding.RID != current.RID || encoding.SSRC != current.SSRC || encoding.RTX.SSRC != current.RTX.SSRC || encoding.FEC.SSRC != current.FEC.SSRC:
+			return &rtcerr.InvalidModificationError{Err: ErrModifyingSendEncodings}
+		case encoding.ScaleResolutionDownBy != 0 && encoding.ScaleResolutionDownBy < 1:
+			return &rtcerr.RangeError{Err: ErrInvalidScaleResolutionDownBy}
+		case encoding.MaxFramerate < 0:
+			return &rtcerr.RangeError{Err: ErrInvalidMaxFramerate}
+		}
+	}
+
+	for i, encoding := range encodings {
+		e := r.trackEncodings[i]
+		e.parameters = encoding
+		if e.writeStream != nil {
+			e.writeStream.setPaused(!encoding.Active)
+		}
+	}
+
+	return nil
+}
+
+func (r *RTPSender) setRTPTransceiver(t *RTPTransceiver) {
+	r.rtpTransceiver.Store(t)
+}
+
+// getCodecs returns the codecs negotiated for the RTPTransceiver of the RTPSender,
+// or those of the MediaEngine if it doesn't belong to one
+func (r *RTPSender) getCodecs() []RTPCodecParameters {
+	if t, ok := r.rtpTransceiver.Load().(*RTPTransceiver); ok {
+		return t.getCodecs(r.api.mediaEngine)
+	}
+
+	return r.api.mediaEngine.getCodecsByKind(r.kind)
+}
+
+func (r *RTPSender) setMid(mid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mid = mid
 }
 
 func (r *RTPSender) isNegotiated() bool {
@@ -83,47 +260,80 @@ func (r *RTPSender) Transport() *DTLSTransport {
 }
 
 // Track returns the RTCRtpTransceiver track, or nil
+// When sending Simulcast this is the track of the first encoding
 func (r *RTPSender) Track() TrackLocal {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.track
+
+	if len(r.trackEncodings) == 0 {
+		return nil
+	}
+	return r.trackEncodings[0].track
 }
 
 // ReplaceTrack replaces the track currently being used as the sender's source with a new TrackLocal.
 // The new track must be of the same media kind (audio, video, etc) and switching the track should not
 // require negotiation.
+// When sending Simulcast this replaces the track of the first encoding
 func (r *RTPSender) ReplaceTrack(track TrackLocal) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.hasSent() {
-		if err := r.track.Unbind(TrackLocalContext{
-			id:          r.id,
-			ssrc:        r.ssrc,
-			writeStream: r.rtpWriteStream,
-		}); err != nil {
+	return r.replaceTrack(r.trackEncodings[0], track)
+}
+
+// ReplaceSimulcastTrack replaces the track used as the source of the encoding with the given RID.
+// A nil track stops sending the encoding.
+func (r *RTPSender) ReplaceSimulcastTrack(rid string, track TrackLocal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.trackEncodings {
+		if e.parameters.RID == rid {
+			return r.replaceTrack(e, track)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", errRTPSenderEncodingForRIDNotFound, rid)
+}
+
+// replaceTrack binds track to the encoding, if Send was already called. r.mu must be held
+func (r *RTPSender) replaceTrack(e *trackEncoding, track TrackLocal) error {
+	if r.hasSent() && e.track != nil {
+		if err := e.track.Unbind(r.trackLocalContext(e)); err != nil {
 			return err
 		}
 	}
 
 	if !r.hasSent() || track == nil {
-		r.track = track
+		e.track = track
 		return nil
 	}
 
-	if _, err := track.Bind(TrackLocalContext{
-		id:          r.id,
-		codecs:      []RTPCodecParameters{r.codec},
-		ssrc:        r.ssrc,
-		writeStream: r.rtpWriteStream,
-	}); err != nil {
+	context := r.trackLocalContext(e)
+	context.codecs = []RTPCodecParameters{e.codec}
+	if _, err := track.Bind(context); err != nil {
 		return err
 	}
 
-	r.track = track
+	e.track = track
 	return nil
 }
 
+func (r *RTPSender) trackLocalContext(e *trackEncoding) TrackLocalContext {
+	id := r.id
+	if e.parameters.RID != "" {
+		id += "-" + e.parameters.RID
+	}
+
+	return TrackLocalContext{
+		id:               id,
+		ssrc:             e.parameters.SSRC,
+		headerExtensions: headerExtensionParameters(r.api.mediaEngine.negotiatedHeaderExtensionsForType(r.kind)),
+		writeStream:      e.writeStream,
+	}
+}
+
 // Send Attempts to set the parameters controlling the sending of media.
 func (r *RTPSender) Send(parameters RTPSendParameters) error {
 	r.mu.Lock()
@@ -133,12 +343,19 @@ func (r *RTPSender) Send(parameters RTPSendParameters) error {
 		return errRTPSenderSendAlreadyCalled
 	}
 
-	srtcpSession, err := r.transport.getSRTCPSession()
-	if err != nil {
-		return err
+	sendEncodings := parameters.encodings()
+
+	previous := map[string]*trackEncoding{}
+	for _, e := range r.trackEncodings {
+		previous[e.parameters.RID] = e
+	}
+	for _, encoding := range sendEncodings {
+		if _, ok := previous[encoding.RID]; len(previous) != 0 && !ok {
+			return fmt.Errorf("%w: %s", errRTPSenderEncodingForRIDNotFound, encoding.RID)
+		}
 	}
 
-	r.rtcpReadStream, err = srtcpSession.OpenReadStream(uint32(parameters.Encodings.SSRC))
+	srtcpSession, err := r.transport.getSRTCPSession()
 	if err != nil {
 		return err
 	}
@@ -152,19 +369,154 @@ func (r *RTPSender) Send(parameters RTPSendParameters) error {
 		return err
 	}
 
-	if r.codec, err = r.track.Bind(TrackLocalContext{
-		id:          r.id,
-		codecs:      r.api.mediaEngine.getCodecsByKind(r.track.Kind()),
-		ssrc:        parameters.Encodings.SSRC,
-		writeStream: r.rtpWriteStream,
-	}); err != nil {
-		return err
+	codecs := r.getCodecs()
+	headerExtensions := headerExtensionParameters(r.api.mediaEngine.negotiatedHeaderExtensionsForType(r.kind))
+
+	// On failure the encodings set up so far are torn down, so Send can be called again
+	encodings := []*trackEncoding{}
+	rollback := func(failed *trackEncoding) {
+		for _, e := range encodings {
+			r.api.interceptor.UnbindLocalStream(&e.streamInfo)
+			_ = e.rtcpReadStream.Close()
+			if e.track != nil {
+				_ = e.track.Unbind(r.trackLocalContext(e))
+			}
+		}
+		if failed.rtcpReadStream != nil {
+			_ = failed.rtcpReadStream.Close()
+		}
 	}
 
+	for _, encoding := range sendEncodings {
+		e := &trackEncoding{
+			parameters:  encoding,
+			writeStream: &interceptorToTrackLocalWriter{},
+		}
+
+		// Encodings are only paused with SetParameters
+		e.parameters.Active = true
+		if p, ok := previous[encoding.RID]; ok {
+			e.track = p.track
+			e.parameters.Active = p.parameters.Active
+		}
+		e.writeStream.setPaused(!e.parameters.Active)
+
+		if e.rtcpReadStream, err = srtcpSession.OpenReadStream(uint32(e.parameters.SSRC)); err != nil {
+			rollback(e)
+			return err
+		}
+
+		codec := RTPCodecParameters{}
+		if len(codecs) != 0 {
+			codec = codecs[0]
+		}
+		if e.track != nil {
+			context := r.trackLocalContext(e)
+			context.codecs = codecs
+			if codec, err = e.track.Bind(context); err != nil {
+				rollback(e)
+				return err
+			}
+		}
+		e.codec = codec
+
+		e.streamInfo = createStreamInfo(r.id, e.parameters.SSRC, codec.PayloadType, codec.RTPCodecCapability, headerExtensions)
+
+		// Retransmissions are only wrapped in RTX if the remote accepted a RTX codec for the codec we are sending
+		if e.parameters.RTX.SSRC != 0 {
+			if rtxPayloadType := findRTXPayloadType(codec.PayloadType, codecs); rtxPayloadType != 0 {
+				e.streamInfo.SSRCRetransmission = uint32(e.parameters.RTX.SSRC)
+				e.streamInfo.PayloadTypeRetransmission = uint8(rtxPayloadType)
+
+				// The track may switch to another negotiated codec, which has its own RTX payload type
+				e.streamInfo.PayloadTypesRetransmission = map[uint8]uint8{}
+				for _, c := range codecs {
+					if rtxPayloadType := findRTXPayloadType(c.PayloadType, codecs); rtxPayloadType != 0 {
+						e.streamInfo.PayloadTypesRetransmission[uint8(c.PayloadType)] = uint8(rtxPayloadType)
+					}
+				}
+			}
+		}
+
+		// FlexFEC packets are sent as a stream of their own, which can only be signaled for encodings without a RID.
+		// Otherwise ULPFEC packets are sent inside RED in the stream itself
+		if fecCodec, ok := findFECCodec(codecs); ok && e.parameters.FEC.SSRC != 0 {
+			e.streamInfo.SSRCForwardErrorCorrection = uint32(e.parameters.FEC.SSRC)
+			e.streamInfo.PayloadTypeForwardErrorCorrection = uint8(fecCodec.PayloadType)
+			e.streamInfo.MimeTypeForwardErrorCorrection = fecCodec.MimeType
+		} else if redCodec, ulpfecCodec, ok := findULPFECCodecs(codecs); ok && r.api.sendsFEC {
+			e.streamInfo.PayloadTypeForwardErrorCorrection = uint8(ulpfecCodec.PayloadType)
+			e.streamInfo.MimeTypeForwardErrorCorrection = ulpfecCodec.MimeType
+			e.streamInfo.PayloadTypeRED = uint8(redCodec.PayloadType)
+		}
+
+		e.writeStream.interceptor.Store(r.api.interceptor.BindLocalStream(&e.streamInfo, r.rtpWriterForEncoding(e, headerExtensions)))
+
+		rtcpReadStream := e.rtcpReadStream
+		e.rtcpInterceptor = r.api.interceptor.BindRTCPReader(interceptor.RTCPReaderFunc(func(in []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			n, err := rtcpReadStream.Read(in)
+			return n, a, err
+		}))
+
+		encodings = append(encodings, e)
+	}
+
+	r.trackEncodings = encodings
+
 	close(r.sendCalled)
 	return nil
 }
 
+// rtpWriterForEncoding returns the writer at the end of the interceptor chain of an encoding.
+// Packets of a Simulcast encoding are stamped with the mid and their RID, so the remote can tell
+// them apart without SSRCs being signaled. This is done after the interceptors, as retransmissions
+// carry the RID in the repaired-rtp-stream-id instead.
+func (r *RTPSender) rtpWriterForEncoding(e *trackEncoding, headerExtensions []RTPHeaderExtensionParameter) interceptor.RTPWriter {
+	rtpWriteStream := r.rtpWriteStream
+	if e.parameters.RID == "" {
+		return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+			return rtpWriteStream.WriteRTP(header, payload)
+		})
+	}
+
+	var midID, ridID, repairedRIDID uint8
+	for _, h := range headerExtensions {
+		switch h.URI {
+		case sdp.SDESMidURI:
+			midID = uint8(h.ID)
+		case sdp.SDESRTPStreamIDURI:
+			ridID = uint8(h.ID)
+		case sdesRepairRTPStreamIDURI:
+			repairedRIDID = uint8(h.ID)
+		}
+	}
+
+	mid, rid, rtxSsrc := r.mid, e.parameters.RID, uint32(e.parameters.RTX.SSRC)
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		// The header is owned by the caller
+		stamped := *header
+		stamped.Extensions = append([]rtp.Extension{}, header.Extensions...)
+
+		if midID != 0 && mid != "" {
+			if err := stamped.SetExtension(midID, []byte(mid)); err != nil {
+				return 0, err
+			}
+		}
+
+		id := ridID
+		if header.SSRC == rtxSsrc {
+			id = repairedRIDID
+		}
+		if id != 0 {
+			if err := stamped.SetExtension(id, []byte(rid)); err != nil {
+				return 0, err
+			}
+		}
+
+		return rtpWriteStream.WriteRTP(&stamped, payload)
+	})
+}
+
 // Stop irreversibly stops the RTPSender
 func (r *RTPSender) Stop() error {
 	r.mu.Lock()
@@ -181,14 +533,39 @@ func (r *RTPSender) Stop() error {
 		return nil
 	}
 
-	return r.rtcpReadStream.Close()
+	errs := []error{}
+	for _, e := range r.trackEncodings {
+		r.api.interceptor.UnbindLocalStream(&e.streamInfo)
+		errs = append(errs, e.rtcpReadStream.Close())
+	}
+
+	return util.FlattenErrs(errs)
 }
 
-// Read reads incoming RTCP for this RTPReceiver
+// Read reads incoming RTCP for this RTPSender. Packets are processed by the
+// Interceptors before being returned, things like NACK responses depend on Read being called.
+// When sending Simulcast this reads the RTCP of the first encoding
 func (r *RTPSender) Read(b []byte) (n int, err error) {
 	select {
 	case <-r.sendCalled:
-		return r.rtcpReadStream.Read(b)
+		n, _, err = r.trackEncodings[0].rtcpInterceptor.Read(b, interceptor.Attributes{})
+		return n, err
+	case <-r.stopCalled:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+// ReadSimulcast reads incoming RTCP for the encoding of this RTPSender with the given RID
+func (r *RTPSender) ReadSimulcast(b []byte, rid string) (n int, err error) {
+	select {
+	case <-r.sendCalled:
+		for _, e := range r.trackEncodings {
+			if e.parameters.RID == rid {
+				n, _, err = e.rtcpInterceptor.Read(b, interceptor.Attributes{})
+				return n, err
+			}
+		}
+		return 0, fmt.Errorf("%w: %s", errRTPSenderEncodingForRIDNotFound, rid)
 	case <-r.stopCalled:
 		return 0, io.ErrClosedPipe
 	}
@@ -205,6 +582,17 @@ func (r *RTPSender) ReadRTCP() ([]rtcp.Packet, error) {
 	return rtcp.Unmarshal(b[:i])
 }
 
+// ReadSimulcastRTCP is a convenience method that wraps ReadSimulcast and unmarshals for you
+func (r *RTPSender) ReadSimulcastRTCP(rid string) ([]rtcp.Packet, error) {
+	b := make([]byte, receiveMTU)
+	i, err := r.ReadSimulcast(b, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	return rtcp.Unmarshal(b[:i])
+}
+
 // hasSent tells if data has been ever sent for this instance
 func (r *RTPSender) hasSent() bool {
 	select {
@@ -214,3 +602,92 @@ func (r *RTPSender) hasSent() bool {
 		return false
 	}
 }
+
+// GetStats returns the stats of the streams sent by the RTPSender, along with
+// the stats of their codecs and of the transport they are sent over
+func (r *RTPSender) GetStats() StatsReport {
+	collector := newStatsReportCollector()
+	if !r.hasSent() {
+		return collector.Ready()
+	}
+
+	r.collectStats(collector)
+
+	r.mu.RLock()
+	for _, e := range r.trackEncodings {
+		collectCodecStats(collector, e.codec)
+	}
+	transport := r.transport
+	r.mu.RUnlock()
+
+	if transport != nil {
+		transport.collectStats(collector)
+		if iceTransport := transport.ICETransport(); iceTransport != nil {
+			iceTransport.collectSelectedCandidatePairStats(collector)
+		}
+	}
+
+	return collector.Ready()
+}
+
+// collectStats collects the statistics of the streams sent by the RTPSender, and the
+// statistics of the remote about them
+func (r *RTPSender) collectStats(collector *statsReportCollector) {
+	if r.api.statsInterceptor == nil || !r.hasSent() {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, e := range r.trackEncodings {
+		s, ok := r.api.statsInterceptor.GetOutboundStats(uint32(e.parameters.SSRC))
+		if !ok {
+			continue
+		}
+
+		collector.Collecting()
+		outbound := OutboundRTPStreamStats{
+			Timestamp:       statsTimestampNow(),
+			Type:            StatsTypeOutboundRTP,
+			ID:              outboundRTPStreamStatsID(e.parameters.SSRC),
+			SSRC:            e.parameters.SSRC,
+			Kind:            r.kind.String(),
+			TransportID:     "iceTransport",
+			CodecID:         e.codec.statsID,
+			FIRCount:        s.FIRCount,
+			PLICount:        s.PLICount,
+			NACKCount:       s.NACKCount,
+			PacketsSent:     s.PacketsSent,
+			FECPacketsSent:  s.FECPacketsSent,
+			BytesSent:       s.BytesSent,
+			HeaderBytesSent: s.HeaderBytesSent,
+			FramesSent:      s.FramesSent,
+		}
+		if !s.LastPacketSent.IsZero() {
+			outbound.LastPacketSentTimestamp = statsTimestampFrom(s.LastPacketSent)
+		}
+
+		if s.RemoteInbound.ReportsReceived != 0 {
+			collector.Collecting()
+			remote := RemoteInboundRTPStreamStats{
+				Timestamp:     statsTimestampFrom(s.RemoteInbound.Timestamp),
+				Type:          StatsTypeRemoteInboundRTP,
+				ID:            remoteInboundRTPStreamStatsID(e.parameters.SSRC),
+				SSRC:          e.parameters.SSRC,
+				Kind:          r.kind.String(),
+				TransportID:   "iceTransport",
+				CodecID:       e.codec.statsID,
+				PacketsLost:   s.RemoteInbound.PacketsLost,
+				Jitter:        s.RemoteInbound.Jitter,
+				LocalID:       outbound.ID,
+				RoundTripTime: s.RemoteInbound.RoundTripTime.Seconds(),
+				FractionLost:  s.RemoteInbound.FractionLost,
+			}
+			outbound.RemoteID = remote.ID
+			collector.Collect(remote.ID, remote)
+		}
+
+		collector.Collect(outbound.ID, outbound)
+	}
+}
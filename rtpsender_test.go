@@ -7,12 +7,14 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/pion/transport/test"
 	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -134,3 +136,287 @@ func Test_RTPSender_ReplaceTrack(t *testing.T) {
 		assert.NoError(t, receiver.Close())
 	})
 }
+
+func Test_RTPSender_Simulcast(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	t.Run("Invalid Encodings", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+
+		track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+		assert.NoError(t, err)
+
+		_, err = pc.AddTransceiverFromTrack(track, RTPTransceiverInit{
+			Direction:     RTPTransceiverDirectionSendonly,
+			SendEncodings: []RTPEncodingParameters{{RTPCodingParameters: RTPCodingParameters{RID: "a"}}, {}},
+		})
+		assert.True(t, errors.Is(err, errRTPSenderRIDNil))
+
+		_, err = pc.AddTransceiverFromTrack(track, RTPTransceiverInit{
+			Direction:     RTPTransceiverDirectionSendonly,
+			SendEncodings: []RTPEncodingParameters{{RTPCodingParameters: RTPCodingParameters{RID: "a"}}, {RTPCodingParameters: RTPCodingParameters{RID: "a"}}},
+		})
+		assert.True(t, errors.Is(err, errRTPSenderRIDCollision))
+
+		assert.NoError(t, pc.Close())
+	})
+
+	t.Run("Send", func(t *testing.T) {
+		offerer, answerer, err := newPair()
+		assert.NoError(t, err)
+
+		rids := []string{"q", "h", "f"}
+		tracks := map[string]*TrackLocalStaticSample{}
+		for _, rid := range rids {
+			tracks[rid], err = NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+			assert.NoError(t, err)
+		}
+
+		transceiver, err := offerer.AddTransceiverFromTrack(tracks["q"], RTPTransceiverInit{
+			Direction: RTPTransceiverDirectionSendonly,
+			SendEncodings: []RTPEncodingParameters{
+				{RTPCodingParameters: RTPCodingParameters{RID: "q"}},
+				{RTPCodingParameters: RTPCodingParameters{RID: "h"}},
+				{RTPCodingParameters: RTPCodingParameters{RID: "f"}},
+			},
+		})
+		assert.NoError(t, err)
+
+		sender := transceiver.Sender()
+		assert.NoError(t, sender.ReplaceSimulcastTrack("h", tracks["h"]))
+		assert.NoError(t, sender.ReplaceSimulcastTrack("f", tracks["f"]))
+		assert.True(t, errors.Is(sender.ReplaceSimulcastTrack("x", tracks["f"]), errRTPSenderEncodingForRIDNotFound))
+
+		parameters := sender.GetParameters()
+		assert.Equal(t, "q", parameters.Encodings.RID)
+		if assert.Equal(t, len(rids), len(parameters.SimulcastEncodings)) {
+			for i, rid := range rids {
+				assert.Equal(t, rid, parameters.SimulcastEncodings[i].RID)
+			}
+		}
+
+		offer, err := offerer.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.Contains(t, offer.SDP, "a=rid:q send")
+		assert.Contains(t, offer.SDP, "a=simulcast:send q;h;f")
+		assert.NotContains(t, offer.SDP, "a=ssrc:")
+
+		var seenRidsMu sync.Mutex
+		seenRids := map[string]bool{}
+		seenAllRids, seenAllRidsCancel := context.WithCancel(context.Background())
+		answerer.OnTrack(func(track *TrackRemote, _ *RTPReceiver) {
+			assert.Equal(t, PayloadType(96), track.PayloadType())
+			assert.Equal(t, mimeTypeVP8, track.Codec().MimeType)
+			assert.NotEmpty(t, track.HeaderExtensions())
+
+			seenRidsMu.Lock()
+			seenRids[track.RID()] = true
+			if len(seenRids) == len(rids) {
+				seenAllRidsCancel()
+			}
+			seenRidsMu.Unlock()
+
+			for {
+				if _, readErr := track.ReadRTP(); readErr != nil {
+					return
+				}
+			}
+		})
+
+		assert.NoError(t, signalPair(offerer, answerer))
+
+		func() {
+			ticker := time.NewTicker(time.Millisecond * 20)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-seenAllRids.Done():
+					return
+				case <-ticker.C:
+					for _, rid := range rids {
+						assert.NoError(t, tracks[rid].WriteSample(media.Sample{Data: []byte{0x00}, Duration: time.Second}))
+					}
+				}
+			}
+		}()
+
+		for _, rid := range rids {
+			assert.True(t, seenRids[rid])
+		}
+
+		assert.NoError(t, offerer.Close())
+		assert.NoError(t, answerer.Close())
+	})
+}
+
+func Test_RTPSender_SetParameters(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+
+		track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+		assert.NoError(t, err)
+
+		rtpSender, err := pc.AddTrack(track)
+		assert.NoError(t, err)
+
+		parameters := rtpSender.GetParameters()
+		assert.Empty(t, parameters.SimulcastEncodings)
+		assert.True(t, parameters.Encodings.Active)
+
+		var modificationErr *rtcerr.InvalidModificationError
+		assert.True(t, errors.As(rtpSender.SetParameters(RTPSendParameters{}), &modificationErr))
+
+		parameters = rtpSender.GetParameters()
+		parameters.Encodings.RID = "a"
+		assert.True(t, errors.As(rtpSender.SetParameters(parameters), &modificationErr))
+
+		var rangeErr *rtcerr.RangeError
+		parameters = rtpSender.GetParameters()
+		parameters.Encodings.ScaleResolutionDownBy = 0.5
+		assert.True(t, errors.As(rtpSender.SetParameters(parameters), &rangeErr))
+
+		parameters = rtpSender.GetParameters()
+		parameters.Encodings.MaxFramerate = -1
+		assert.True(t, errors.As(rtpSender.SetParameters(parameters), &rangeErr))
+
+		parameters = rtpSender.GetParameters()
+		parameters.Encodings.MaxBitrate = 500000
+		parameters.Encodings.MaxFramerate = 15
+		parameters.Encodings.ScaleResolutionDownBy = 2
+		parameters.Encodings.Priority = PriorityTypeHigh
+		assert.NoError(t, rtpSender.SetParameters(parameters))
+		assert.Equal(t, parameters, rtpSender.GetParameters())
+
+		assert.NoError(t, pc.Close())
+
+		var stateErr *rtcerr.InvalidStateError
+		assert.True(t, errors.As(rtpSender.SetParameters(parameters), &stateErr))
+	})
+
+	t.Run("Pause", func(t *testing.T) {
+		sender, receiver, err := newPair()
+		assert.NoError(t, err)
+
+		track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+		assert.NoError(t, err)
+
+		rtpSender, err := sender.AddTrack(track)
+		assert.NoError(t, err)
+
+		seenPacketA, seenPacketACancel := context.WithCancel(context.Background())
+		seenPacketC, seenPacketCCancel := context.WithCancel(context.Background())
+		var seenPausedPacket atomicBool
+		receiver.OnTrack(func(track *TrackRemote, _ *RTPReceiver) {
+			for {
+				pkt, readErr := track.ReadRTP()
+				if readErr != nil {
+					return
+				}
+
+				switch {
+				case bytes.Equal(pkt.Payload, []byte{0x10, 0xAA}):
+					seenPacketACancel()
+				case bytes.Equal(pkt.Payload, []byte{0x10, 0xBB}):
+					seenPausedPacket.set(true)
+				case bytes.Equal(pkt.Payload, []byte{0x10, 0xCC}):
+					seenPacketCCancel()
+				}
+			}
+		})
+
+		assert.NoError(t, signalPair(sender, receiver))
+
+		writeSamplesUntil(seenPacketA, t, track, media.Sample{Data: []byte{0xAA}, Duration: time.Second})
+
+		parameters := rtpSender.GetParameters()
+		assert.Equal(t, PayloadType(96), parameters.Encodings.PayloadType)
+		parameters.Encodings.Active = false
+		assert.NoError(t, rtpSender.SetParameters(parameters))
+
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0xBB}, Duration: time.Second}))
+		}
+
+		parameters.Encodings.Active = true
+		assert.NoError(t, rtpSender.SetParameters(parameters))
+		writeSamplesUntil(seenPacketC, t, track, media.Sample{Data: []byte{0xCC}, Duration: time.Second})
+
+		assert.False(t, seenPausedPacket.get())
+
+		assert.NoError(t, sender.Close())
+		assert.NoError(t, receiver.Close())
+	})
+}
+
+// failingBindTrack fails to bind until failBind is cleared
+type failingBindTrack struct {
+	*TrackLocalStaticRTP
+	failBind atomicBool
+}
+
+func (f *failingBindTrack) Bind(t TrackLocalContext) (RTPCodecParameters, error) {
+	if f.failBind.get() {
+		return RTPCodecParameters{}, errRTPSenderTrackNil
+	}
+	return f.TrackLocalStaticRTP.Bind(t)
+}
+
+func Test_RTPSender_Send(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerer, answerer, err := newPair()
+	assert.NoError(t, err)
+
+	dc, err := offerer.CreateDataChannel("data", nil)
+	assert.NoError(t, err)
+
+	connected := make(chan struct{})
+	dc.OnOpen(func() {
+		close(connected)
+	})
+	assert.NoError(t, signalPair(offerer, answerer))
+	<-connected
+
+	staticTrack, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: "video/vp8"}, "video", "pion")
+	assert.NoError(t, err)
+	track := &failingBindTrack{TrackLocalStaticRTP: staticTrack}
+
+	rtpSender, err := offerer.api.NewRTPSender(track, offerer.dtlsTransport)
+	assert.NoError(t, err)
+
+	parameters := RTPSendParameters{Encodings: RTPEncodingParameters{RTPCodingParameters: RTPCodingParameters{SSRC: 1234}}}
+
+	// Invalid parameters are rejected without changing the sender
+	assert.True(t, errors.Is(rtpSender.Send(RTPSendParameters{SimulcastEncodings: []RTPEncodingParameters{{RTPCodingParameters: RTPCodingParameters{RID: "a", SSRC: 1234}}}}), errRTPSenderEncodingForRIDNotFound))
+	assert.Equal(t, track, rtpSender.Track())
+
+	// A failed Send is rolled back, and can be retried
+	track.failBind.set(true)
+	assert.True(t, errors.Is(rtpSender.Send(parameters), errRTPSenderTrackNil))
+	assert.False(t, rtpSender.hasSent())
+	assert.Equal(t, track, rtpSender.Track())
+
+	track.failBind.set(false)
+	assert.NoError(t, rtpSender.Send(parameters))
+	assert.True(t, rtpSender.hasSent())
+	assert.Equal(t, track, rtpSender.Track())
+
+	assert.NoError(t, rtpSender.Stop())
+	closePairNow(t, offerer, answerer)
+}
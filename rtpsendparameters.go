@@ -2,5 +2,33 @@ package webrtc
 
 // RTPSendParameters contains the RTP stack settings used by receivers
 type RTPSendParameters struct {
+	// Encodings is the encoding of a RTPSender that isn't sending Simulcast.
+	// When SimulcastEncodings isn't empty it is ignored, and GetParameters sets it to the first encoding
 	Encodings RTPEncodingParameters
+
+	// SimulcastEncodings has an entry for each Simulcast layer, in the order of the RIDs
+	// of the RTPSender. It is empty when the RTPSender isn't sending Simulcast
+	SimulcastEncodings []RTPEncodingParameters
+}
+
+// newRTPSendParameters returns the RTPSendParameters describing encodings
+func newRTPSendParameters(encodings []RTPEncodingParameters) RTPSendParameters {
+	parameters := RTPSendParameters{}
+	if len(encodings) != 0 {
+		parameters.Encodings = encodings[0]
+	}
+	if len(encodings) > 1 || (len(encodings) == 1 && encodings[0].RID != "") {
+		parameters.SimulcastEncodings = encodings
+	}
+
+	return parameters
+}
+
+// encodings returns the encodings described by the RTPSendParameters
+func (p RTPSendParameters) encodings() []RTPEncodingParameters {
+	if len(p.SimulcastEncodings) != 0 {
+		return p.SimulcastEncodings
+	}
+
+	return []RTPEncodingParameters{p.Encodings}
 }
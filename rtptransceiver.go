@@ -4,9 +4,12 @@ package webrtc
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
 
 	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
 )
 
 // RTPTransceiver represents a combination of an RTPSender and an RTPReceiver that share a common mid.
@@ -16,8 +19,105 @@ type RTPTransceiver struct {
 	receiver  atomic.Value // *RTPReceiver
 	direction atomic.Value // RTPTransceiverDirection
 
+	codecPreferences []RTPCodecCapability
+	mu               sync.RWMutex
+
 	stopped bool
 	kind    RTPCodecType
+
+	api *API
+}
+
+// SetCodecPreferences sets the codecs negotiated for this RTPTransceiver, in order of preference.
+// The codecs must be registered in the MediaEngine, MediaEngine.GetCapabilities returns them.
+// RTX is only negotiated if it is part of the preferences. Passing no codecs restores
+// the codecs of the MediaEngine.
+func (t *RTPTransceiver) SetCodecPreferences(codecs []RTPCodecCapability) error {
+	registeredCodecs := t.api.mediaEngine.getRegisteredCodecsByKind(t.kind)
+	hasMediaCodec := false
+	for _, codec := range codecs {
+		if isRTXMimeType(codec.MimeType) {
+			continue
+		}
+
+		hasMediaCodec = true
+		if _, err := codecCapabilityFuzzySearch(codec, registeredCodecs); err != nil {
+			return &rtcerr.InvalidModificationError{Err: fmt.Errorf("%w: %s", ErrRTPTransceiverCodecUnsupported, codec.MimeType)}
+		}
+	}
+	if len(codecs) != 0 && !hasMediaCodec {
+		return &rtcerr.InvalidModificationError{Err: ErrRTPTransceiverCodecPreferencesRTXOnly}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.codecPreferences = append([]RTPCodecCapability{}, codecs...)
+	return nil
+}
+
+// getCodecs returns the codecs of the MediaEngine to negotiate for this RTPTransceiver,
+// filtered and ordered by the codec preferences.
+func (t *RTPTransceiver) getCodecs(mediaEngine *MediaEngine) []RTPCodecParameters {
+	mediaEngineCodecs := mediaEngine.getCodecsByKind(t.kind)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if len(t.codecPreferences) == 0 {
+		return mediaEngineCodecs
+	}
+
+	codecs, rtxCodecs := []RTPCodecParameters{}, []RTPCodecParameters{}
+	for _, codec := range mediaEngineCodecs {
+		if isRTXMimeType(codec.MimeType) {
+			rtxCodecs = append(rtxCodecs, codec)
+		} else {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	filteredCodecs := []RTPCodecParameters{}
+	includeRTX := false
+	for _, preference := range t.codecPreferences {
+		if isRTXMimeType(preference.MimeType) {
+			includeRTX = true
+			continue
+		}
+
+		codec, err := codecCapabilityFuzzySearch(preference, codecs)
+		if err != nil || containsPayloadType(filteredCodecs, codec.PayloadType) {
+			continue
+		}
+		filteredCodecs = append(filteredCodecs, codec)
+	}
+
+	if includeRTX {
+		for _, codec := range filteredCodecs {
+			apt := fmt.Sprintf("apt=%d", codec.PayloadType)
+			for _, rtxCodec := range rtxCodecs {
+				if rtxCodec.SDPFmtpLine == apt {
+					filteredCodecs = append(filteredCodecs, rtxCodec)
+				}
+			}
+		}
+	}
+
+	return filteredCodecs
+}
+
+func isRTXMimeType(mimeType string) bool {
+	return strings.HasSuffix(strings.ToLower(mimeType), "/rtx")
+}
+
+func containsPayloadType(codecs []RTPCodecParameters, payloadType PayloadType) bool {
+	for _, codec := range codecs {
+		if codec.PayloadType == payloadType {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Sender returns the RTPTransceiver's RTPSender if it has one
@@ -36,6 +136,9 @@ func (t *RTPTransceiver) SetSender(s *RTPSender, track TrackLocal) error {
 }
 
 func (t *RTPTransceiver) setSender(s *RTPSender) {
+	if s != nil {
+		s.setRTPTransceiver(t)
+	}
 	t.sender.Store(s)
 }
 
@@ -93,6 +196,9 @@ func (t *RTPTransceiver) Stop() error {
 }
 
 func (t *RTPTransceiver) setReceiver(r *RTPReceiver) {
+	if r != nil {
+		r.setRTPTransceiver(t)
+	}
 	t.receiver.Store(r)
 }
 
@@ -164,7 +270,7 @@ func satisfyTypeAndDirection(remoteKind RTPCodecType, remoteDirection RTPTransce
 
 // handleUnknownRTPPacket consumes a single RTP Packet and returns information that is helpful
 // for demuxing and handling an unknown SSRC (usually for Simulcast)
-func handleUnknownRTPPacket(buf []byte, midExtensionID, streamIDExtensionID uint8) (mid, rid string, payloadType PayloadType, err error) {
+func handleUnknownRTPPacket(buf []byte, midExtensionID, streamIDExtensionID, repairStreamIDExtensionID uint8) (mid, rid, rsid string, payloadType PayloadType, err error) {
 	rp := &rtp.Packet{}
 	if err = rp.Unmarshal(buf); err != nil {
 		return
@@ -183,5 +289,11 @@ func handleUnknownRTPPacket(buf []byte, midExtensionID, streamIDExtensionID uint
 		rid = string(payload)
 	}
 
+	if repairStreamIDExtensionID != 0 {
+		if payload := rp.GetExtension(repairStreamIDExtensionID); payload != nil {
+			rsid = string(payload)
+		}
+	}
+
 	return
 }
@@ -0,0 +1,98 @@
+// +build !js
+
+package webrtc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTPTransceiver_SetCodecPreferences(t *testing.T) {
+	videoFormats := func(desc *SessionDescription) []string {
+		parsed := &sdp.SessionDescription{}
+		assert.NoError(t, parsed.Unmarshal([]byte(desc.SDP)))
+
+		for _, media := range parsed.MediaDescriptions {
+			if media.MediaName.Media == "video" {
+				return media.MediaName.Formats
+			}
+		}
+		return nil
+	}
+
+	pcOffer, pcAnswer, err := newPair()
+	assert.NoError(t, err)
+
+	transceiver, err := pcOffer.AddTransceiverFromKind(RTPCodecTypeVideo)
+	assert.NoError(t, err)
+
+	t.Run("Unsupported Codec", func(t *testing.T) {
+		err := transceiver.SetCodecPreferences([]RTPCodecCapability{{MimeType: "video/VP8"}, {MimeType: "audio/opus"}})
+
+		var modificationErr *rtcerr.InvalidModificationError
+		assert.True(t, errors.As(err, &modificationErr))
+		assert.True(t, errors.Is(err, ErrRTPTransceiverCodecUnsupported))
+	})
+
+	t.Run("RTX Only", func(t *testing.T) {
+		err := transceiver.SetCodecPreferences([]RTPCodecCapability{{MimeType: "video/rtx"}})
+
+		var modificationErr *rtcerr.InvalidModificationError
+		assert.True(t, errors.As(err, &modificationErr))
+		assert.True(t, errors.Is(err, ErrRTPTransceiverCodecPreferencesRTXOnly))
+	})
+
+	t.Run("Sender and Receiver Codecs", func(t *testing.T) {
+		assert.NoError(t, transceiver.SetCodecPreferences([]RTPCodecCapability{{MimeType: "video/VP9", SDPFmtpLine: "profile-id=0"}}))
+
+		for _, codecs := range [][]RTPCodecParameters{transceiver.Sender().getCodecs(), transceiver.Receiver().getCodecs()} {
+			assert.Equal(t, 1, len(codecs))
+			assert.Equal(t, mimeTypeVP9, codecs[0].MimeType)
+		}
+
+		assert.NoError(t, transceiver.SetCodecPreferences(nil))
+	})
+
+	t.Run("Offer", func(t *testing.T) {
+		assert.NoError(t, transceiver.SetCodecPreferences([]RTPCodecCapability{
+			{MimeType: "video/VP9", SDPFmtpLine: "profile-id=0"},
+			{MimeType: "video/VP8"},
+			{MimeType: "video/rtx"},
+		}))
+
+		offer, err := pcOffer.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"98", "96", "99", "97"}, videoFormats(&offer))
+
+		assert.NoError(t, transceiver.SetCodecPreferences([]RTPCodecCapability{{MimeType: "video/VP8"}}))
+		offer, err = pcOffer.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"96"}, videoFormats(&offer))
+
+		assert.NoError(t, transceiver.SetCodecPreferences(nil))
+		offer, err = pcOffer.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.Greater(t, len(videoFormats(&offer)), 2)
+	})
+
+	t.Run("Answer", func(t *testing.T) {
+		offer, err := pcOffer.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.NoError(t, pcOffer.SetLocalDescription(offer))
+		assert.NoError(t, pcAnswer.SetRemoteDescription(offer))
+
+		transceivers := pcAnswer.GetTransceivers()
+		assert.Equal(t, 1, len(transceivers))
+		assert.NoError(t, transceivers[0].SetCodecPreferences([]RTPCodecCapability{{MimeType: "video/VP8"}, {MimeType: "video/rtx"}}))
+
+		answer, err := pcAnswer.CreateAnswer(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"96", "97"}, videoFormats(&answer))
+	})
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
@@ -17,12 +17,14 @@ import (
 // trackDetails represents any media source that can be represented in a SDP
 // This isn't keyed by SSRC because it also needs to support rid based sources
 type trackDetails struct {
-	mid      string
-	kind     RTPCodecType
-	streamID string
-	id       string
-	ssrc     SSRC
-	rids     []string
+	mid        string
+	kind       RTPCodecType
+	streamID   string
+	id         string
+	ssrc       SSRC
+	repairSsrc SSRC
+	fecSsrc    SSRC
+	rids       []string
 }
 
 func trackDetailsForSSRC(trackDetails []trackDetails, ssrc SSRC) *trackDetails {
@@ -47,7 +49,8 @@ func filterTrackWithSSRC(incomingTracks []trackDetails, ssrc SSRC) []trackDetail
 // extract all trackDetails from an SDP.
 func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) []trackDetails { // nolint:gocognit
 	incomingTracks := []trackDetails{}
-	rtxRepairFlows := map[uint32]bool{}
+	rtxRepairFlows := map[uint32]uint32{} // repair flow SSRC to the SSRC it repairs
+	fecRepairFlows := map[uint32]uint32{} // FEC flow SSRC to the SSRC it protects
 
 	for _, media := range s.MediaDescriptions {
 		// Plan B can have multiple tracks in a signle media section
@@ -81,7 +84,7 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) [
 					// as this declares that the second SSRC (632943048) is a rtx repair flow (RFC4588) for the first
 					// (2231627014) as specified in RFC5576
 					if len(split) == 3 {
-						_, err := strconv.ParseUint(split[1], 10, 32)
+						baseSsrc, err := strconv.ParseUint(split[1], 10, 32)
 						if err != nil {
 							log.Warnf("Failed to parse SSRC: %v", err)
 							continue
@@ -91,9 +94,26 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) [
 							log.Warnf("Failed to parse SSRC: %v", err)
 							continue
 						}
-						rtxRepairFlows[uint32(rtxRepairFlow)] = true
+						rtxRepairFlows[uint32(rtxRepairFlow)] = uint32(baseSsrc)
 						incomingTracks = filterTrackWithSSRC(incomingTracks, SSRC(rtxRepairFlow)) // Remove if rtx was added as track before
 					}
+				} else if split[0] == sdpSemanticTokenFECFramework || split[0] == sdp.SemanticTokenForwardErrorCorrection {
+					// Lines like `a=ssrc-group:FEC-FR 2231627014 1729457311` declare that the second SSRC
+					// is a FEC flow protecting the first one (RFC5956). It isn't a track either
+					if len(split) == 3 {
+						baseSsrc, err := strconv.ParseUint(split[1], 10, 32)
+						if err != nil {
+							log.Warnf("Failed to parse SSRC: %v", err)
+							continue
+						}
+						fecFlow, err := strconv.ParseUint(split[2], 10, 32)
+						if err != nil {
+							log.Warnf("Failed to parse SSRC: %v", err)
+							continue
+						}
+						fecRepairFlows[uint32(fecFlow)] = uint32(baseSsrc)
+						incomingTracks = filterTrackWithSSRC(incomingTracks, SSRC(fecFlow))
+					}
 				}
 
 			// Handle `a=msid:<stream_id> <track_label>` for Unified plan. The first value is the same as MediaStream.id
@@ -114,8 +134,11 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) [
 					continue
 				}
 
-				if rtxRepairFlow := rtxRepairFlows[uint32(ssrc)]; rtxRepairFlow {
-					continue // This ssrc is a RTX repair flow, ignore
+				if _, isRepairFlow := rtxRepairFlows[uint32(ssrc)]; isRepairFlow {
+					continue // This ssrc is a RTX repair flow, it is attached to the track it repairs below
+				}
+				if _, isFECFlow := fecRepairFlows[uint32(ssrc)]; isFECFlow {
+					continue // This ssrc is a FEC flow, it is attached to the track it protects below
 				}
 
 				if len(split) == 3 && strings.HasPrefix(split[1], "msid:") {
@@ -159,14 +182,31 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) [
 			incomingTracks = append(incomingTracks, newTrack)
 		}
 	}
+
+	for repairSsrc, baseSsrc := range rtxRepairFlows {
+		if track := trackDetailsForSSRC(incomingTracks, SSRC(baseSsrc)); track != nil {
+			track.repairSsrc = SSRC(repairSsrc)
+		}
+	}
+	for fecSsrc, baseSsrc := range fecRepairFlows {
+		if track := trackDetailsForSSRC(incomingTracks, SSRC(baseSsrc)); track != nil {
+			track.fecSsrc = SSRC(fecSsrc)
+		}
+	}
+
 	return incomingTracks
 }
 
+// getRids returns the RIDs the remote sends in a media section. RIDs the remote
+// wants to receive are ignored
 func getRids(media *sdp.MediaDescription) map[string]string {
 	rids := map[string]string{}
 	for _, attr := range media.Attributes {
 		if attr.Key == "rid" {
 			split := strings.Split(attr.Value, " ")
+			if len(split) > 1 && split[1] == "recv" {
+				continue
+			}
 			rids[split[0]] = attr.Value
 		}
 	}
@@ -290,16 +330,30 @@ func addTransceiverSDP(d *sdp.SessionDescription, isPlanB, shouldAddCandidates b
 		WithPropertyAttribute(sdp.AttrKeyRTCPMux).
 		WithPropertyAttribute(sdp.AttrKeyRTCPRsize)
 
-	codecs := mediaEngine.getCodecsByKind(t.kind)
+	codecs := t.getCodecs(mediaEngine)
+	var opusAttributes map[string]string
 	for _, codec := range codecs {
 		name := strings.TrimPrefix(codec.MimeType, "audio/")
 		name = strings.TrimPrefix(name, "video/")
-		media.WithCodec(uint8(codec.PayloadType), name, codec.ClockRate, codec.Channels, codec.SDPFmtpLine)
+
+		fmtpLine := codec.SDPFmtpLine
+		if strings.EqualFold(codec.MimeType, mimeTypeOpus) {
+			var attributes map[string]string
+			if fmtpLine, attributes = opusFmtpLine(mediaEngine, codec); opusAttributes == nil {
+				opusAttributes = attributes
+			}
+		}
+		media.WithCodec(uint8(codec.PayloadType), name, codec.ClockRate, codec.Channels, fmtpLine)
 
 		for _, feedback := range codec.RTPCodecCapability.RTCPFeedback {
 			media.WithValueAttribute("rtcp-fb", fmt.Sprintf("%d %s %s", codec.PayloadType, feedback.Type, feedback.Parameter))
 		}
 	}
+	for _, key := range opusMediaAttributes {
+		if value, ok := opusAttributes[key]; ok {
+			media.WithValueAttribute(key, value)
+		}
+	}
 	if len(codecs) == 0 {
 		// Explicitly reject track if we don't have the codec
 		d.WithMedia(&sdp.MediaDescription{
@@ -313,7 +367,7 @@ func addTransceiverSDP(d *sdp.SessionDescription, isPlanB, shouldAddCandidates b
 		return false, nil
 	}
 
-	for id, rtpExtension := range mediaEngine.negotiatedHeaderExtensionsForType(t.kind) {
+	for id, rtpExtension := range mediaEngine.getHeaderExtensionsByKind(t.kind, t.Direction()) {
 		extURL, err := url.Parse(rtpExtension.uri)
 		if err != nil {
 			return false, err
@@ -321,21 +375,49 @@ func addTransceiverSDP(d *sdp.SessionDescription, isPlanB, shouldAddCandidates b
 		media.WithExtMap(sdp.ExtMap{Value: id, URI: extURL})
 	}
 
-	if len(mediaSection.ridMap) > 0 {
-		recvRids := make([]string, 0, len(mediaSection.ridMap))
+	recvRids := make([]string, 0, len(mediaSection.ridMap))
+	for rid := range mediaSection.ridMap {
+		media.WithValueAttribute("rid", rid+" recv")
+		recvRids = append(recvRids, rid)
+	}
 
-		for rid := range mediaSection.ridMap {
-			media.WithValueAttribute("rid", rid+" recv")
-			recvRids = append(recvRids, rid)
+	hasRTX := false
+	for _, codec := range codecs {
+		if isRTXMimeType(codec.MimeType) {
+			hasRTX = true
+			break
 		}
-		// Simulcast
-		media.WithValueAttribute("simulcast", "recv "+strings.Join(recvRids, ";"))
 	}
+	_, hasFEC := findFECCodec(codecs)
 
+	sendRids := []string{}
 	for _, mt := range transceivers {
 		if mt.Sender() != nil && mt.Sender().Track() != nil {
 			track := mt.Sender().Track()
-			media = media.WithMediaSource(uint32(mt.Sender().ssrc), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+			for _, encoding := range mt.Sender().getEncodingParameters() {
+				// Simulcast encodings are identified by their RID. Declaring their SSRCs
+				// would make the remote handle each of them as a separate track
+				if encoding.RID != "" {
+					if isPlanB {
+						return false, errSDPPlanBSimulcast
+					}
+					sendRids = append(sendRids, encoding.RID)
+					continue
+				}
+
+				if hasRTX {
+					media = media.WithValueAttribute(sdp.AttrKeySSRCGroup, fmt.Sprintf("%s %d %d", sdp.SemanticTokenFlowIdentification, encoding.SSRC, encoding.RTX.SSRC))
+				}
+				media = media.WithMediaSource(uint32(encoding.SSRC), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+				if hasRTX {
+					media = media.WithMediaSource(uint32(encoding.RTX.SSRC), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+				}
+				if hasFEC && encoding.FEC.SSRC != 0 {
+					media = media.WithValueAttribute(sdp.AttrKeySSRCGroup, fmt.Sprintf("%s %d %d", sdpSemanticTokenFECFramework, encoding.SSRC, encoding.FEC.SSRC))
+					media = media.WithMediaSource(uint32(encoding.FEC.SSRC), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+				}
+			}
+
 			if !isPlanB {
 				media = media.WithPropertyAttribute("msid:" + track.StreamID() + " " + track.ID())
 				break
@@ -343,6 +425,23 @@ func addTransceiverSDP(d *sdp.SessionDescription, isPlanB, shouldAddCandidates b
 		}
 	}
 
+	for _, rid := range sendRids {
+		media.WithValueAttribute("rid", rid+" send")
+	}
+
+	// A section both sending and receiving Simulcast describes the two directions in a single attribute
+	// https://tools.ietf.org/html/rfc8853#section-5.1
+	simulcast := []string{}
+	if len(sendRids) > 0 {
+		simulcast = append(simulcast, "send "+strings.Join(sendRids, ";"))
+	}
+	if len(recvRids) > 0 {
+		simulcast = append(simulcast, "recv "+strings.Join(recvRids, ";"))
+	}
+	if len(simulcast) > 0 {
+		media.WithValueAttribute("simulcast", strings.Join(simulcast, " "))
+	}
+
 	media = media.WithPropertyAttribute(t.Direction().String())
 
 	for _, fingerprint := range dtlsFingerprints {
@@ -576,6 +675,13 @@ func codecsFromMediaDescription(m *sdp.MediaDescription) (out []RTPCodecParamete
 		MediaDescriptions: []*sdp.MediaDescription{m},
 	}
 
+	opusAttributes := map[string]string{}
+	for _, key := range opusMediaAttributes {
+		if value, ok := m.Attribute(key); ok {
+			opusAttributes[key] = value
+		}
+	}
+
 	for _, payloadStr := range m.MediaName.Formats {
 		payloadType, err := strconv.Atoi(payloadStr)
 		if err != nil {
@@ -607,8 +713,13 @@ func codecsFromMediaDescription(m *sdp.MediaDescription) (out []RTPCodecParamete
 			feedback = append(feedback, entry)
 		}
 
+		mimeType := m.MediaName.Media + "/" + codec.Name
+		if strings.EqualFold(mimeType, mimeTypeOpus) {
+			codec.Fmtp = opusFmtpLineFromSDP(codec.Fmtp, opusAttributes)
+		}
+
 		out = append(out, RTPCodecParameters{
-			RTPCodecCapability: RTPCodecCapability{m.MediaName.Media + "/" + codec.Name, codec.ClockRate, channels, codec.Fmtp, feedback},
+			RTPCodecCapability: RTPCodecCapability{mimeType, codec.ClockRate, channels, codec.Fmtp, feedback},
 			PayloadType:        PayloadType(payloadType),
 		})
 	}
@@ -616,6 +727,46 @@ func codecsFromMediaDescription(m *sdp.MediaDescription) (out []RTPCodecParamete
 	return out, nil
 }
 
+// opusFmtpLine returns the a=fmtp line, and the attributes of the media section, of an Opus codec.
+// The parameters of Opus describe how to receive, so the ones registered in the MediaEngine are
+// used instead of the ones of the remote
+func opusFmtpLine(mediaEngine *MediaEngine, codec RTPCodecParameters) (string, map[string]string) {
+	fmtpLine := codec.SDPFmtpLine
+	if local, err := codecParametersFuzzySearch(codec, mediaEngine.getRegisteredCodecsByKind(RTPCodecTypeAudio)); err == nil {
+		fmtpLine = local.SDPFmtpLine
+	}
+
+	return opusFmtpLineToSDP(fmtpLine)
+}
+
+// enableOpusDTX signals in the a=fmtp line of every Opus codec of d that discontinuous transmission is preferred
+func enableOpusDTX(d *sdp.SessionDescription) {
+	for _, m := range d.MediaDescriptions {
+		codecs, err := codecsFromMediaDescription(m)
+		if err != nil {
+			continue
+		}
+
+		for _, codec := range codecs {
+			if !strings.EqualFold(codec.MimeType, mimeTypeOpus) {
+				continue
+			}
+
+			prefix := fmt.Sprintf("%d ", codec.PayloadType)
+			hasFmtp := false
+			for i, a := range m.Attributes {
+				if a.Key == "fmtp" && strings.HasPrefix(a.Value, prefix) {
+					m.Attributes[i].Value = prefix + setFmtpParameter(strings.TrimPrefix(a.Value, prefix), opusParameterUseDTX, "1")
+					hasFmtp = true
+				}
+			}
+			if !hasFmtp {
+				m.WithValueAttribute("fmtp", prefix+opusParameterUseDTX+"=1")
+			}
+		}
+	}
+}
+
 func rtpExtensionsFromMediaDescription(m *sdp.MediaDescription) (map[string]int, error) {
 	out := map[string]int{}
 
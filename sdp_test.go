@@ -6,6 +6,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"errors"
 	"strings"
 	"testing"
 
@@ -152,7 +153,7 @@ func TestExtractICEDetails(t *testing.T) {
 }
 
 func TestTrackDetailsFromSDP(t *testing.T) {
-	t.Run("Tracks unknown, audio and video with RTX", func(t *testing.T) {
+	t.Run("Tracks unknown, audio and video with RTX and FEC", func(t *testing.T) {
 		s := &sdp.SessionDescription{
 			MediaDescriptions: []*sdp.MediaDescription{
 				{
@@ -185,6 +186,8 @@ func TestTrackDetailsFromSDP(t *testing.T) {
 						{Key: "ssrc-group", Value: "FID 3000 4000"},
 						{Key: "ssrc", Value: "3000 msid:video_trk_label video_trk_guid"},
 						{Key: "ssrc", Value: "4000 msid:rtx_trk_label rtx_trck_guid"},
+						{Key: "ssrc", Value: "4500 msid:video_trk_label video_trk_guid"},
+						{Key: "ssrc-group", Value: "FEC-FR 3000 4500"},
 					},
 				},
 				{
@@ -227,11 +230,16 @@ func TestTrackDetailsFromSDP(t *testing.T) {
 		} else {
 			assert.Equal(t, RTPCodecTypeVideo, track.kind)
 			assert.Equal(t, SSRC(3000), track.ssrc)
+			assert.Equal(t, SSRC(4000), track.repairSsrc)
+			assert.Equal(t, SSRC(4500), track.fecSsrc)
 			assert.Equal(t, "video_trk_label", track.streamID)
 		}
 		if track := trackDetailsForSSRC(tracks, 4000); track != nil {
 			assert.Fail(t, "got the rtx track ssrc:3000 which should have been skipped")
 		}
+		if track := trackDetailsForSSRC(tracks, 4500); track != nil {
+			assert.Fail(t, "got the fec track ssrc:4500 which should have been skipped")
+		}
 		if track := trackDetailsForSSRC(tracks, 5000); track == nil {
 			assert.Fail(t, "missing video track with ssrc:5000")
 		} else {
@@ -397,6 +405,41 @@ func TestPopulateSDP(t *testing.T) {
 		}
 		assert.Equal(t, true, found, "Rid key should be present")
 	})
+
+	t.Run("Simulcast send and recv", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+
+		track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+		assert.NoError(t, err)
+
+		tr, err := pc.AddTransceiverFromTrack(track, RTPTransceiverInit{
+			Direction: RTPTransceiverDirectionSendrecv,
+			SendEncodings: []RTPEncodingParameters{
+				{RTPCodingParameters: RTPCodingParameters{RID: "a"}},
+				{RTPCodingParameters: RTPCodingParameters{RID: "b"}},
+			},
+		})
+		assert.NoError(t, err)
+
+		mediaSections := []mediaSection{{id: "video", transceivers: []*RTPTransceiver{tr}, ridMap: map[string]string{"c": ""}}}
+
+		d, err := populateSDP(&sdp.SessionDescription{}, false, []DTLSFingerprint{}, false, false, pc.api.mediaEngine, connectionRoleFromDtlsRole(defaultDtlsRoleOffer), []ICECandidate{}, ICEParameters{}, mediaSections, ICEGatheringStateComplete)
+		assert.NoError(t, err)
+
+		simulcast := []string{}
+		for _, a := range d.MediaDescriptions[0].Attributes {
+			if a.Key == "simulcast" {
+				simulcast = append(simulcast, a.Value)
+			}
+		}
+		assert.Equal(t, []string{"send a;b recv c"}, simulcast)
+
+		_, err = populateSDP(&sdp.SessionDescription{}, true, []DTLSFingerprint{}, false, false, pc.api.mediaEngine, connectionRoleFromDtlsRole(defaultDtlsRoleOffer), []ICECandidate{}, ICEParameters{}, mediaSections, ICEGatheringStateComplete)
+		assert.True(t, errors.Is(err, errSDPPlanBSimulcast))
+
+		assert.NoError(t, pc.Close())
+	})
 }
 
 func TestGetRIDs(t *testing.T) {
@@ -463,6 +506,84 @@ func TestCodecsFromMediaDescription(t *testing.T) {
 		})
 		assert.NoError(t, err)
 	})
+
+	t.Run("Opus ptime", func(t *testing.T) {
+		codecs, err := codecsFromMediaDescription(&sdp.MediaDescription{
+			MediaName: sdp.MediaName{
+				Media:   "audio",
+				Formats: []string{"111", "0"},
+			},
+			Attributes: []sdp.Attribute{
+				{Key: "rtpmap", Value: "111 opus/48000/2"},
+				{Key: "fmtp", Value: "111 minptime=10;useinbandfec=1"},
+				{Key: "rtpmap", Value: "0 PCMU/8000"},
+				{Key: "ptime", Value: "20"},
+				{Key: "maxptime", Value: "60"},
+			},
+		})
+
+		assert.Equal(t, codecs, []RTPCodecParameters{
+			{
+				RTPCodecCapability: RTPCodecCapability{mimeTypeOpus, 48000, 2, "minptime=10;useinbandfec=1;ptime=20;maxptime=60", []RTCPFeedback{}},
+				PayloadType:        111,
+			},
+			{
+				RTPCodecCapability: RTPCodecCapability{mimeTypePCMU, 8000, 0, "", []RTCPFeedback{}},
+				PayloadType:        0,
+			},
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestOpusNegotiation(t *testing.T) {
+	offerMediaEngine := &MediaEngine{}
+	assert.NoError(t, offerMediaEngine.RegisterCodec(RTPCodecParameters{
+		RTPCodecCapability: RTPCodecCapability{mimeTypeOpus, 48000, 2, OpusParameters{MinPTime: 10, PTime: 20, MaxPTime: 60, Stereo: true, SpropStereo: true}.SDPFmtpLine(), nil},
+		PayloadType:        111,
+	}, RTPCodecTypeAudio))
+
+	pcOffer, err := NewAPI(WithMediaEngine(offerMediaEngine)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	pcAnswer, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	_, err = pcOffer.AddTransceiverFromKind(RTPCodecTypeAudio)
+	assert.NoError(t, err)
+
+	offer, err := pcOffer.CreateOffer(&OfferOptions{OfferAnswerOptions: OfferAnswerOptions{VoiceActivityDetection: true}})
+	assert.NoError(t, err)
+	assert.Contains(t, offer.SDP, "a=fmtp:111 minptime=10;stereo=1;sprop-stereo=1;usedtx=1\r\n")
+	assert.Contains(t, offer.SDP, "a=ptime:20\r\n")
+	assert.Contains(t, offer.SDP, "a=maxptime:60\r\n")
+
+	assert.NoError(t, pcOffer.SetLocalDescription(offer))
+	assert.NoError(t, pcAnswer.SetRemoteDescription(offer))
+
+	// The answer signals how the answerer wants to receive
+	answer, err := pcAnswer.CreateAnswer(nil)
+	assert.NoError(t, err)
+	assert.Contains(t, answer.SDP, "a=fmtp:111 minptime=10;useinbandfec=1\r\n")
+	assert.NotContains(t, answer.SDP, "ptime:")
+
+	// The negotiated codec has the parameters of the remote
+	codec, err := pcAnswer.api.mediaEngine.getCodecByPayload(111)
+	assert.NoError(t, err)
+	parameters, ok := codec.OpusParameters()
+	assert.True(t, ok)
+	assert.Equal(t, OpusParameters{MinPTime: 10, PTime: 20, MaxPTime: 60, UseDTX: true, Stereo: true, SpropStereo: true}, parameters)
+
+	assert.NoError(t, pcAnswer.SetLocalDescription(answer))
+	assert.NoError(t, pcOffer.SetRemoteDescription(answer))
+
+	codec, err = pcOffer.api.mediaEngine.getCodecByPayload(111)
+	assert.NoError(t, err)
+	parameters, ok = codec.OpusParameters()
+	assert.True(t, ok)
+	assert.Equal(t, OpusParameters{MinPTime: 10, UseInbandFEC: true}, parameters)
+
+	closePairNow(t, pcOffer, pcAnswer)
 }
 
 func TestRtpExtensionsFromMediaDescription(t *testing.T) {
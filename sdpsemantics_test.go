@@ -43,12 +43,24 @@ func getMdNames(sdp *sdp.SessionDescription) []string {
 	return mdNames
 }
 
+// extractSsrcList returns the SSRCs of all media sources, RTX repair flows and FEC flows are skipped
 func extractSsrcList(md *sdp.MediaDescription) []string {
+	repairFlows := map[string]struct{}{}
+	for _, attr := range md.Attributes {
+		if attr.Key == sdp.AttrKeySSRCGroup {
+			if fields := strings.Fields(attr.Value); len(fields) == 3 && (fields[0] == sdp.SemanticTokenFlowIdentification || fields[0] == sdpSemanticTokenFECFramework) {
+				repairFlows[fields[2]] = struct{}{}
+			}
+		}
+	}
+
 	ssrcMap := map[string]struct{}{}
 	for _, attr := range md.Attributes {
 		if attr.Key == ssrcStr {
 			ssrc := strings.Fields(attr.Value)[0]
-			ssrcMap[ssrc] = struct{}{}
+			if _, isRepairFlow := repairFlows[ssrc]; !isRepairFlow {
+				ssrcMap[ssrc] = struct{}{}
+			}
 		}
 	}
 	ssrcList := make([]string, 0, len(ssrcMap))
@@ -308,20 +320,6 @@ func TestSDPSemantics_UnifiedPlanWithFallback(t *testing.T) {
 	mdNames = getMdNames(answer.parsed)
 	assert.ObjectsAreEqual(mdNames, []string{"video", "audio", "data"})
 
-	extractSsrcList := func(md *sdp.MediaDescription) []string {
-		ssrcMap := map[string]struct{}{}
-		for _, attr := range md.Attributes {
-			if attr.Key == ssrcStr {
-				ssrc := strings.Fields(attr.Value)[0]
-				ssrcMap[ssrc] = struct{}{}
-			}
-		}
-		ssrcList := make([]string, 0, len(ssrcMap))
-		for ssrc := range ssrcMap {
-			ssrcList = append(ssrcList, ssrc)
-		}
-		return ssrcList
-	}
 	// Verify that each section has 2 SSRCs (one for each sender)
 	for _, section := range []string{"video", "audio"} {
 		for _, media := range answer.parsed.MediaDescriptions {
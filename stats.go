@@ -88,6 +88,25 @@ func statsTimestampNow() StatsTimestamp {
 	return statsTimestampFrom(time.Now())
 }
 
+// The IDs of the stats of RTP streams are derived from their SSRC,
+// so the stats of both ends of a stream can refer to each other
+
+func inboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("RTPInboundStream-%d", ssrc)
+}
+
+func outboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("RTPOutboundStream-%d", ssrc)
+}
+
+func remoteInboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("RTPRemoteInboundStream-%d", ssrc)
+}
+
+func remoteOutboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("RTPRemoteOutboundStream-%d", ssrc)
+}
+
 // StatsReport collects Stats objects indexed by their ID.
 type StatsReport map[string]Stats
 
@@ -291,6 +310,10 @@ type InboundRTPStreamStats struct {
 	// i.e., frames that would be displayed if no frames are dropped. Only valid for video.
 	FramesDecoded uint32 `json:"framesDecoded"`
 
+	// FramesReceived represents the total number of complete frames received on this RTP stream.
+	// This metric is incremented when the complete frame is received. Only valid for video.
+	FramesReceived uint32 `json:"framesReceived"`
+
 	// LastPacketReceivedTimestamp represents the timestamp at which the last packet was
 	// received for this SSRC. This differs from Timestamp, which represents the time
 	// at which the statistics were generated by the local endpoint.
@@ -309,6 +332,10 @@ type InboundRTPStreamStats struct {
 	// BytesReceived is the total number of bytes received for this SSRC.
 	BytesReceived uint64 `json:"bytesReceived"`
 
+	// HeaderBytesReceived is the total number of RTP header and padding bytes received for this SSRC.
+	// This does not include the size of transport layer headers such as IP or UDP.
+	HeaderBytesReceived uint64 `json:"headerBytesReceived"`
+
 	// PacketsFailedDecryption is the cumulative number of RTP packets that failed
 	// to be decrypted. These packets are not counted by PacketsDiscarded.
 	PacketsFailedDecryption uint32 `json:"packetsFailedDecryption"`
@@ -413,6 +440,10 @@ type OutboundRTPStreamStats struct {
 	// BytesSent is the total number of bytes sent for this SSRC.
 	BytesSent uint64 `json:"bytesSent"`
 
+	// HeaderBytesSent is the total number of RTP header and padding bytes sent for this SSRC.
+	// This does not include the size of transport layer headers such as IP or UDP.
+	HeaderBytesSent uint64 `json:"headerBytesSent"`
+
 	// BytesDiscardedOnSend is the total number of bytes for this SSRC that have
 	// been discarded due to socket errors, i.e. a socket error occurred when handing
 	// the packets containing the bytes to the socket. This might happen due to various
@@ -448,6 +479,9 @@ type OutboundRTPStreamStats struct {
 	// Only valid for video.
 	FramesEncoded uint32 `json:"framesEncoded"`
 
+	// FramesSent represents the total number of frames sent on this RTP stream. Only valid for video.
+	FramesSent uint32 `json:"framesSent"`
+
 	// TotalEncodeTime is the total number of seconds that has been spent encoding the
 	// framesEncoded frames of this stream. The average encode time can be calculated by
 	// dividing this value with FramesEncoded. The time it takes to encode one frame is the
@@ -907,6 +941,9 @@ type VideoSenderStats struct {
 	// by inspecting the same underlying object.
 	ID string `json:"id"`
 
+	// Kind is either "audio" or "video". This reflects the "kind" attribute of the MediaStreamTrack.
+	Kind string `json:"kind"`
+
 	// FramesCaptured represents the total number of frames captured, before encoding,
 	// for this RTPSender (or for this MediaStreamTrack, if type is "track"). For example,
 	// if type is "sender" and this sender's track represents a camera, then this is the
@@ -964,6 +1001,9 @@ type AudioReceiverStats struct {
 	// by inspecting the same underlying object.
 	ID string `json:"id"`
 
+	// Kind is either "audio" or "video". This reflects the "kind" attribute of the MediaStreamTrack.
+	Kind string `json:"kind"`
+
 	// AudioLevel represents the output audio level of the track.
 	//
 	// The value is a value between 0..1 (linear), where 1.0 represents 0 dBov,
@@ -1048,6 +1088,9 @@ type VideoReceiverStats struct {
 	// by inspecting the same underlying object.
 	ID string `json:"id"`
 
+	// Kind is either "audio" or "video". This reflects the "kind" attribute of the MediaStreamTrack.
+	Kind string `json:"kind"`
+
 	// FrameWidth represents the width of the last processed frame for this track.
 	// Before the first frame is processed this attribute is missing.
 	FrameWidth uint32 `json:"frameWidth"`
@@ -1166,8 +1209,13 @@ type TransportStats struct {
 	// Present only if DTLS is negotiated.
 	RemoteCertificateID string `json:"remoteCertificateId"`
 
+	// TLSVersion is the version of DTLS negotiated, as the hexadecimal representation of
+	// the version field of the ServerHello. Present only if DTLS is negotiated.
+	TLSVersion string `json:"tlsVersion"`
+
 	// DTLSCipher is the descriptive name of the cipher suite used for the DTLS transport,
 	// as defined in the "Description" column of the IANA cipher suite registry.
+	// It isn't reported, the negotiated cipher suite isn't exposed by pion/dtls.
 	DTLSCipher string `json:"dtlsCipher"`
 
 	// SRTPCipher is the descriptive name of the protection profile used for the SRTP
@@ -1299,11 +1347,12 @@ type ICECandidatePairStats struct {
 	// check responses (ResponsesReceived), including those that reply to requests
 	// that are sent in order to verify consent. The average round trip time can
 	// be computed from TotalRoundTripTime by dividing it by ResponsesReceived.
+	// It isn't reported, round trip times aren't measured by pion/ice.
 	TotalRoundTripTime float64 `json:"totalRoundTripTime"`
 
 	// CurrentRoundTripTime represents the latest round trip time measured in seconds,
 	// computed from both STUN connectivity checks, including those that are sent
-	// for consent verification.
+	// for consent verification. It isn't reported, round trip times aren't measured by pion/ice.
 	CurrentRoundTripTime float64 `json:"currentRoundTripTime"`
 
 	// AvailableOutgoingBitrate is calculated by the underlying congestion control
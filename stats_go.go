@@ -91,3 +91,35 @@ func (r StatsReport) GetCodecStats(c *RTPCodecParameters) (CodecStats, bool) {
 	}
 	return codecStats, true
 }
+
+// GetInboundRTPStreamStats is a helper method to return the associated stats for a given
+// received stream, identified by its SSRC
+func (r StatsReport) GetInboundRTPStreamStats(ssrc SSRC) (InboundRTPStreamStats, bool) {
+	statsID := inboundRTPStreamStatsID(ssrc)
+	stats, ok := r[statsID]
+	if !ok {
+		return InboundRTPStreamStats{}, false
+	}
+
+	streamStats, ok := stats.(InboundRTPStreamStats)
+	if !ok {
+		return InboundRTPStreamStats{}, false
+	}
+	return streamStats, true
+}
+
+// GetOutboundRTPStreamStats is a helper method to return the associated stats for a given
+// sent stream, identified by its SSRC
+func (r StatsReport) GetOutboundRTPStreamStats(ssrc SSRC) (OutboundRTPStreamStats, bool) {
+	statsID := outboundRTPStreamStatsID(ssrc)
+	stats, ok := r[statsID]
+	if !ok {
+		return OutboundRTPStreamStats{}, false
+	}
+
+	streamStats, ok := stats.(OutboundRTPStreamStats)
+	if !ok {
+		return OutboundRTPStreamStats{}, false
+	}
+	return streamStats, true
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pion/transport/test"
+	"github.com/pion/webrtc/v3/pkg/media"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -332,6 +334,32 @@ func TestPeerConnection_GetStats(t *testing.T) {
 		assert.NotEmpty(t, getCertificateStats(t, reportPCOffer, &certificates[i]))
 	}
 
+	for _, transportStats := range []TransportStats{offerICETransportStats, answerICETransportStats} {
+		assert.Equal(t, DTLSTransportStateConnected, transportStats.DTLSState)
+		assert.Equal(t, "FEFD", transportStats.TLSVersion)
+		assert.NotEmpty(t, transportStats.SRTPCipher)
+	}
+	assert.Equal(t, offerICETransportStats.SRTPCipher, answerICETransportStats.SRTPCipher)
+	assert.Equal(t, ICERoleControlling, offerICETransportStats.ICERole)
+	assert.Equal(t, ICERoleControlled, answerICETransportStats.ICERole)
+
+	assert.Equal(t, certificates[0].statsID, offerICETransportStats.LocalCertificateID)
+	remoteCertificateStats, ok := reportPCOffer[offerICETransportStats.RemoteCertificateID].(CertificateStats)
+	assert.True(t, ok)
+	answerCertificateStats := getCertificateStats(t, reportPCAnswer, &answerPC.configuration.Certificates[0])
+	assert.Equal(t, answerCertificateStats.Fingerprint, remoteCertificateStats.Fingerprint)
+
+	selectedPairStats, ok := reportPCOffer[offerICETransportStats.SelectedCandidatePairID].(ICECandidatePairStats)
+	assert.True(t, ok)
+	assert.True(t, selectedPairStats.Nominated)
+	assert.Equal(t, offerICETransportStats.ID, selectedPairStats.TransportID)
+
+	reportJSON, err := json.Marshal(reportPCOffer)
+	assert.NoError(t, err)
+	var unmarshaledReport StatsReport
+	assert.NoError(t, json.Unmarshal(reportJSON, &unmarshaledReport))
+	assert.Equal(t, reportPCOffer, unmarshaledReport)
+
 	assert.NoError(t, offerPC.Close())
 	assert.NoError(t, answerPC.Close())
 }
@@ -344,3 +372,135 @@ func TestPeerConnection_GetStats_Closed(t *testing.T) {
 
 	pc.GetStats()
 }
+
+func TestPeerConnection_GetStats_RTPStreams(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, err := newPair()
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+	require.NoError(t, err)
+
+	sender, err := offerPC.AddTrack(track)
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			if _, rtcpErr := sender.ReadRTCP(); rtcpErr != nil {
+				return
+			}
+		}
+	}()
+
+	remoteTrack := make(chan *TrackRemote, 1)
+	remoteReceiver := make(chan *RTPReceiver, 1)
+	answerPC.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+		remoteTrack <- track
+		remoteReceiver <- r
+		go func() {
+			for {
+				if _, rtcpErr := r.ReadRTCP(); rtcpErr != nil {
+					return
+				}
+			}
+		}()
+		for {
+			if _, readErr := track.ReadRTP(); readErr != nil {
+				return
+			}
+		}
+	})
+
+	assert.NoError(t, signalPair(offerPC, answerPC))
+
+	var (
+		outbound       OutboundRTPStreamStats
+		remoteInbound  RemoteInboundRTPStreamStats
+		inbound        InboundRTPStreamStats
+		remoteOutbound RemoteOutboundRTPStreamStats
+	)
+	for {
+		assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0x00}, Duration: 20 * time.Millisecond}))
+		time.Sleep(20 * time.Millisecond)
+
+		var hasOutbound, hasRemoteInbound, hasInbound, hasRemoteOutbound bool
+		for _, s := range offerPC.GetStats() {
+			switch s := s.(type) {
+			case OutboundRTPStreamStats:
+				outbound, hasOutbound = s, true
+			case RemoteInboundRTPStreamStats:
+				remoteInbound, hasRemoteInbound = s, true
+			}
+		}
+		for _, s := range answerPC.GetStats() {
+			switch s := s.(type) {
+			case InboundRTPStreamStats:
+				inbound, hasInbound = s, true
+			case RemoteOutboundRTPStreamStats:
+				remoteOutbound, hasRemoteOutbound = s, true
+			}
+		}
+		if hasOutbound && hasRemoteInbound && hasInbound && hasRemoteOutbound {
+			break
+		}
+	}
+
+	ssrc := (<-remoteTrack).SSRC()
+
+	assert.Equal(t, StatsTypeOutboundRTP, outbound.Type)
+	assert.Equal(t, ssrc, outbound.SSRC)
+	assert.Equal(t, "video", outbound.Kind)
+	assert.Equal(t, remoteInbound.ID, outbound.RemoteID)
+	assert.NotZero(t, outbound.PacketsSent)
+	assert.NotZero(t, outbound.BytesSent)
+	assert.NotZero(t, outbound.FramesSent)
+	assert.NotZero(t, outbound.LastPacketSentTimestamp)
+
+	assert.Equal(t, StatsTypeRemoteInboundRTP, remoteInbound.Type)
+	assert.Equal(t, outbound.ID, remoteInbound.LocalID)
+	assert.Equal(t, ssrc, remoteInbound.SSRC)
+
+	assert.Equal(t, StatsTypeInboundRTP, inbound.Type)
+	assert.Equal(t, ssrc, inbound.SSRC)
+	assert.Equal(t, remoteOutbound.ID, inbound.RemoteID)
+	assert.NotZero(t, inbound.PacketsReceived)
+	assert.NotZero(t, inbound.BytesReceived)
+	assert.NotZero(t, inbound.FramesReceived)
+	assert.NotZero(t, inbound.LastPacketReceivedTimestamp)
+	assert.GreaterOrEqual(t, outbound.PacketsSent, inbound.PacketsReceived)
+
+	assert.Equal(t, StatsTypeRemoteOutboundRTP, remoteOutbound.Type)
+	assert.Equal(t, inbound.ID, remoteOutbound.LocalID)
+	assert.NotZero(t, remoteOutbound.PacketsSent)
+
+	senderReport := sender.GetStats()
+	senderOutbound, ok := senderReport.GetOutboundRTPStreamStats(ssrc)
+	assert.True(t, ok)
+	assert.Equal(t, outbound.ID, senderOutbound.ID)
+	_, ok = senderReport.GetInboundRTPStreamStats(ssrc)
+	assert.False(t, ok)
+	_, ok = senderReport.GetConnectionStats(offerPC)
+	assert.False(t, ok)
+	assert.Contains(t, senderReport, senderOutbound.RemoteID)
+	assert.Contains(t, senderReport, senderOutbound.CodecID)
+	assert.NotEmpty(t, getTransportStats(t, senderReport, senderOutbound.TransportID))
+	assert.Len(t, findCandidatePairStats(t, senderReport), 1)
+
+	receiverReport := (<-remoteReceiver).GetStats()
+	receiverInbound, ok := receiverReport.GetInboundRTPStreamStats(ssrc)
+	assert.True(t, ok)
+	assert.Equal(t, inbound.ID, receiverInbound.ID)
+	_, ok = receiverReport.GetOutboundRTPStreamStats(ssrc)
+	assert.False(t, ok)
+	assert.Contains(t, receiverReport, receiverInbound.RemoteID)
+	assert.Contains(t, receiverReport, receiverInbound.CodecID)
+	assert.NotEmpty(t, getTransportStats(t, receiverReport, receiverInbound.TransportID))
+	assert.Len(t, findCandidatePairStats(t, receiverReport), 1)
+
+	closePairNow(t, offerPC, answerPC)
+}
@@ -0,0 +1,242 @@
+package webrtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// UnmarshalJSON enables JSON unmarshaling of a StatsReport, each of its
+// entries is unmarshaled into the Stats object matching its type
+func (r *StatsReport) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	report := make(StatsReport, len(raw))
+	for id, rawStats := range raw {
+		stats, err := UnmarshalStatsJSON(rawStats)
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		report[id] = stats
+	}
+
+	*r = report
+	return nil
+}
+
+// UnmarshalStatsJSON unmarshals a Stats object from JSON, the concrete type
+// of the returned object is picked from its "type" (and "kind" for the types
+// shared by audio and video) attributes
+func UnmarshalStatsJSON(b []byte) (Stats, error) {
+	var header struct {
+		Type StatsType `json:"type"`
+		Kind string    `json:"kind"`
+	}
+	if err := json.Unmarshal(b, &header); err != nil {
+		return nil, err
+	}
+
+	var stats Stats
+	switch header.Type {
+	case StatsTypeCodec:
+		stats = &CodecStats{}
+	case StatsTypeInboundRTP:
+		stats = &InboundRTPStreamStats{}
+	case StatsTypeOutboundRTP:
+		stats = &OutboundRTPStreamStats{}
+	case StatsTypeRemoteInboundRTP:
+		stats = &RemoteInboundRTPStreamStats{}
+	case StatsTypeRemoteOutboundRTP:
+		stats = &RemoteOutboundRTPStreamStats{}
+	case StatsTypeCSRC:
+		stats = &RTPContributingSourceStats{}
+	case StatsTypePeerConnection:
+		stats = &PeerConnectionStats{}
+	case StatsTypeDataChannel:
+		stats = &DataChannelStats{}
+	case StatsTypeStream:
+		stats = &MediaStreamStats{}
+	case StatsTypeTrack:
+		switch header.Kind {
+		case RTPCodecTypeAudio.String():
+			stats = &SenderAudioTrackAttachmentStats{}
+		case RTPCodecTypeVideo.String():
+			stats = &SenderVideoTrackAttachmentStats{}
+		}
+	case StatsTypeSender:
+		switch header.Kind {
+		case RTPCodecTypeAudio.String():
+			stats = &AudioSenderStats{}
+		case RTPCodecTypeVideo.String():
+			stats = &VideoSenderStats{}
+		}
+	case StatsTypeReceiver:
+		switch header.Kind {
+		case RTPCodecTypeAudio.String():
+			stats = &AudioReceiverStats{}
+		case RTPCodecTypeVideo.String():
+			stats = &VideoReceiverStats{}
+		}
+	case StatsTypeTransport:
+		stats = &TransportStats{}
+	case StatsTypeCandidatePair:
+		stats = &ICECandidatePairStats{}
+	case StatsTypeLocalCandidate, StatsTypeRemoteCandidate:
+		stats = &ICECandidateStats{}
+	case StatsTypeCertificate:
+		stats = &CertificateStats{}
+	}
+	if stats == nil {
+		return nil, fmt.Errorf("%w: %s %s", errStatsTypeUnknown, header.Type, header.Kind)
+	}
+
+	if err := json.Unmarshal(b, stats); err != nil {
+		return nil, err
+	}
+
+	// Stats are held by value in a StatsReport
+	return reflect.ValueOf(stats).Elem().Interface(), nil
+}
+
+// StatsRates are the rates at which the counters of a Stats object changed
+// between two StatsReport snapshots
+type StatsRates struct {
+	// Interval is the time elapsed between the two snapshots of the Stats object.
+	Interval time.Duration
+
+	// BitrateSent and BitrateReceived are in bits per second.
+	BitrateSent     float64
+	BitrateReceived float64
+
+	// PacketRateSent and PacketRateReceived are in packets per second.
+	PacketRateSent     float64
+	PacketRateReceived float64
+
+	// LossRate is the fraction of the packets lost over the interval, between 0 and 1.
+	// For outbound RTP streams it is computed from the RemoteInboundRTPStreamStats
+	// they refer to.
+	LossRate float64
+}
+
+// RatesSince computes the rates of the RTP streams, transports, candidate pairs and
+// data channels of the report since the previous snapshot. Stats objects missing from
+// previous, or whose counters went backwards, are left out.
+func (r StatsReport) RatesSince(previous StatsReport) map[string]StatsRates {
+	rates := make(map[string]StatsRates)
+
+	for id, stats := range r {
+		var (
+			rate StatsRates
+			ok   bool
+		)
+
+		switch s := stats.(type) {
+		case InboundRTPStreamStats:
+			prev, isSame := previous[id].(InboundRTPStreamStats)
+			if !isSame {
+				continue
+			}
+			if rate, ok = newStatsRates(prev.Timestamp, s.Timestamp); !ok {
+				continue
+			}
+			if ok = rate.setReceived(prev.BytesReceived, s.BytesReceived, uint64(prev.PacketsReceived), uint64(s.PacketsReceived)); !ok {
+				continue
+			}
+			rate.LossRate = lossRate(int64(s.PacketsLost)-int64(prev.PacketsLost), int64(s.PacketsReceived)-int64(prev.PacketsReceived))
+		case OutboundRTPStreamStats:
+			prev, isSame := previous[id].(OutboundRTPStreamStats)
+			if !isSame {
+				continue
+			}
+			if rate, ok = newStatsRates(prev.Timestamp, s.Timestamp); !ok {
+				continue
+			}
+			if ok = rate.setSent(prev.BytesSent, s.BytesSent, uint64(prev.PacketsSent), uint64(s.PacketsSent)); !ok {
+				continue
+			}
+			remote, hasRemote := r[s.RemoteID].(RemoteInboundRTPStreamStats)
+			prevRemote, hasPrevRemote := previous[prev.RemoteID].(RemoteInboundRTPStreamStats)
+			if hasRemote && hasPrevRemote {
+				lost := int64(remote.PacketsLost) - int64(prevRemote.PacketsLost)
+				rate.LossRate = lossRate(lost, int64(s.PacketsSent)-int64(prev.PacketsSent)-lost)
+			}
+		case TransportStats:
+			prev, isSame := previous[id].(TransportStats)
+			if !isSame {
+				continue
+			}
+			if rate, ok = newStatsRates(prev.Timestamp, s.Timestamp); !ok {
+				continue
+			}
+			ok = rate.setSent(prev.BytesSent, s.BytesSent, uint64(prev.PacketsSent), uint64(s.PacketsSent)) &&
+				rate.setReceived(prev.BytesReceived, s.BytesReceived, uint64(prev.PacketsReceived), uint64(s.PacketsReceived))
+		case ICECandidatePairStats:
+			prev, isSame := previous[id].(ICECandidatePairStats)
+			if !isSame {
+				continue
+			}
+			if rate, ok = newStatsRates(prev.Timestamp, s.Timestamp); !ok {
+				continue
+			}
+			ok = rate.setSent(prev.BytesSent, s.BytesSent, uint64(prev.PacketsSent), uint64(s.PacketsSent)) &&
+				rate.setReceived(prev.BytesReceived, s.BytesReceived, uint64(prev.PacketsReceived), uint64(s.PacketsReceived))
+		case DataChannelStats:
+			prev, isSame := previous[id].(DataChannelStats)
+			if !isSame {
+				continue
+			}
+			if rate, ok = newStatsRates(prev.Timestamp, s.Timestamp); !ok {
+				continue
+			}
+			ok = rate.setSent(prev.BytesSent, s.BytesSent, uint64(prev.MessagesSent), uint64(s.MessagesSent)) &&
+				rate.setReceived(prev.BytesReceived, s.BytesReceived, uint64(prev.MessagesReceived), uint64(s.MessagesReceived))
+		}
+
+		if ok {
+			rates[id] = rate
+		}
+	}
+
+	return rates
+}
+
+func newStatsRates(previous, current StatsTimestamp) (StatsRates, bool) {
+	interval := current.Time().Sub(previous.Time())
+	if interval <= 0 {
+		return StatsRates{}, false
+	}
+	return StatsRates{Interval: interval}, true
+}
+
+func (s *StatsRates) setSent(prevBytes, bytes, prevPackets, packets uint64) bool {
+	if bytes < prevBytes || packets < prevPackets {
+		return false
+	}
+	s.BitrateSent = float64(bytes-prevBytes) * 8 / s.Interval.Seconds()
+	s.PacketRateSent = float64(packets-prevPackets) / s.Interval.Seconds()
+	return true
+}
+
+func (s *StatsRates) setReceived(prevBytes, bytes, prevPackets, packets uint64) bool {
+	if bytes < prevBytes || packets < prevPackets {
+		return false
+	}
+	s.BitrateReceived = float64(bytes-prevBytes) * 8 / s.Interval.Seconds()
+	s.PacketRateReceived = float64(packets-prevPackets) / s.Interval.Seconds()
+	return true
+}
+
+// lossRate returns the fraction of the expected packets that were lost
+func lossRate(lost, received int64) float64 {
+	if lost <= 0 || lost+received <= 0 {
+		return 0
+	}
+	if received < 0 {
+		return 1
+	}
+	return float64(lost) / float64(lost+received)
+}
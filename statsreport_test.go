@@ -0,0 +1,184 @@
+package webrtc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsReport_JSON(t *testing.T) {
+	report := StatsReport{
+		"transport": TransportStats{
+			Timestamp:               1000,
+			Type:                    StatsTypeTransport,
+			ID:                      "transport",
+			BytesSent:               100,
+			ICERole:                 ICERoleControlling,
+			DTLSState:               DTLSTransportStateConnected,
+			SelectedCandidatePairID: "pair",
+		},
+		"candidate": ICECandidateStats{
+			Timestamp:     1000,
+			Type:          StatsTypeLocalCandidate,
+			ID:            "candidate",
+			NetworkType:   NetworkTypeUDP4,
+			CandidateType: ICECandidateTypeHost,
+		},
+		"dataChannel": DataChannelStats{
+			Timestamp: 1000,
+			Type:      StatsTypeDataChannel,
+			ID:        "dataChannel",
+			State:     DataChannelStateOpen,
+		},
+		"outbound": OutboundRTPStreamStats{
+			Timestamp:   1000,
+			Type:        StatsTypeOutboundRTP,
+			ID:          "outbound",
+			SSRC:        1234,
+			Kind:        "video",
+			PacketsSent: 10,
+		},
+		"sender": VideoSenderStats{
+			Timestamp: 1000,
+			Type:      StatsTypeSender,
+			ID:        "sender",
+			Kind:      "video",
+		},
+		"receiver": AudioReceiverStats{
+			Timestamp: 1000,
+			Type:      StatsTypeReceiver,
+			ID:        "receiver",
+			Kind:      "audio",
+		},
+		"track": SenderAudioTrackAttachmentStats{
+			Timestamp: 1000,
+			Type:      StatsTypeTrack,
+			ID:        "track",
+			Kind:      "audio",
+		},
+	}
+
+	b, err := json.Marshal(report)
+	assert.NoError(t, err)
+
+	var raw map[string]map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &raw))
+	assert.Equal(t, "transport", raw["transport"]["type"])
+	assert.Equal(t, "transport", raw["transport"]["id"])
+	assert.Equal(t, float64(1000), raw["transport"]["timestamp"])
+	assert.Equal(t, "controlling", raw["transport"]["iceRole"])
+	assert.Equal(t, "connected", raw["transport"]["dtlsState"])
+	assert.Equal(t, "host", raw["candidate"]["candidateType"])
+	assert.Equal(t, "udp4", raw["candidate"]["networkType"])
+	assert.Equal(t, "open", raw["dataChannel"]["state"])
+
+	var unmarshaled StatsReport
+	assert.NoError(t, json.Unmarshal(b, &unmarshaled))
+	assert.Equal(t, report, unmarshaled)
+}
+
+func TestUnmarshalStatsJSON_UnknownType(t *testing.T) {
+	_, err := UnmarshalStatsJSON([]byte(`{"type":"unknown"}`))
+	assert.True(t, errors.Is(err, errStatsTypeUnknown))
+
+	_, err = UnmarshalStatsJSON([]byte(`{"type":"sender"}`))
+	assert.True(t, errors.Is(err, errStatsTypeUnknown))
+
+	var report StatsReport
+	assert.True(t, errors.Is(json.Unmarshal([]byte(`{"a":{"type":"unknown"}}`), &report), errStatsTypeUnknown))
+}
+
+func TestStatsReport_RatesSince(t *testing.T) {
+	previous := StatsReport{
+		"inbound": InboundRTPStreamStats{
+			Timestamp:       1000,
+			Type:            StatsTypeInboundRTP,
+			PacketsReceived: 100,
+			BytesReceived:   10000,
+			PacketsLost:     5,
+		},
+		"outbound": OutboundRTPStreamStats{
+			Timestamp:   1000,
+			Type:        StatsTypeOutboundRTP,
+			PacketsSent: 100,
+			BytesSent:   10000,
+			RemoteID:    "remote",
+		},
+		"remote": RemoteInboundRTPStreamStats{
+			Timestamp:   900,
+			Type:        StatsTypeRemoteInboundRTP,
+			PacketsLost: 2,
+		},
+		"transport": TransportStats{
+			Timestamp:     1000,
+			Type:          StatsTypeTransport,
+			BytesSent:     500,
+			BytesReceived: 1000,
+		},
+		"reset": DataChannelStats{
+			Timestamp: 1000,
+			Type:      StatsTypeDataChannel,
+			BytesSent: 100,
+		},
+	}
+	current := StatsReport{
+		"inbound": InboundRTPStreamStats{
+			Timestamp:       3000,
+			Type:            StatsTypeInboundRTP,
+			PacketsReceived: 190,
+			BytesReceived:   20000,
+			PacketsLost:     15,
+		},
+		"outbound": OutboundRTPStreamStats{
+			Timestamp:   3000,
+			Type:        StatsTypeOutboundRTP,
+			PacketsSent: 200,
+			BytesSent:   30000,
+			RemoteID:    "remote",
+		},
+		"remote": RemoteInboundRTPStreamStats{
+			Timestamp:   2900,
+			Type:        StatsTypeRemoteInboundRTP,
+			PacketsLost: 27,
+		},
+		"transport": TransportStats{
+			Timestamp:     1500,
+			Type:          StatsTypeTransport,
+			BytesSent:     1500,
+			BytesReceived: 1000,
+		},
+		"reset": DataChannelStats{
+			Timestamp: 3000,
+			Type:      StatsTypeDataChannel,
+			BytesSent: 50,
+		},
+		"new": DataChannelStats{
+			Timestamp: 3000,
+			Type:      StatsTypeDataChannel,
+			BytesSent: 50,
+		},
+	}
+
+	rates := current.RatesSince(previous)
+	assert.Equal(t, map[string]StatsRates{
+		"inbound": {
+			Interval:           2 * time.Second,
+			BitrateReceived:    40000,
+			PacketRateReceived: 45,
+			LossRate:           0.1,
+		},
+		"outbound": {
+			Interval:       2 * time.Second,
+			BitrateSent:    80000,
+			PacketRateSent: 50,
+			LossRate:       0.25,
+		},
+		"transport": {
+			Interval:    500 * time.Millisecond,
+			BitrateSent: 16000,
+		},
+	}, rates)
+}
@@ -0,0 +1,61 @@
+// +build !js
+
+package webrtc
+
+import (
+	"strings"
+	"time"
+)
+
+// dtmfTones are the DTMF tones, indexed by their telephone event code
+// https://tools.ietf.org/html/rfc4733#section-3.2
+const dtmfTones = "0123456789*#ABCD"
+
+// TelephoneEvent is a telephone event, like a DTMF tone, received on a TrackRemote
+// https://tools.ietf.org/html/rfc4733#section-2.3
+type TelephoneEvent struct {
+	// Event is the code of the event, 0-15 for the DTMF tones
+	Event uint8
+
+	// Tone is the DTMF tone of the event, or "" if it isn't a DTMF tone
+	Tone string
+
+	// EndOfEvent is false when the event starts, and true once it ended
+	EndOfEvent bool
+
+	// Volume is the power level of a tone, from 0 to -63 dBm0, as a positive number
+	Volume uint8
+
+	// Duration is the duration of the event so far. Once it ended it is the duration of the whole event
+	Duration time.Duration
+}
+
+// dtmfToneEvent returns the telephone event code of a DTMF tone
+func dtmfToneEvent(tone rune) (uint8, bool) {
+	i := strings.IndexRune(dtmfTones, tone)
+	if i == -1 {
+		return 0, false
+	}
+	return uint8(i), true
+}
+
+// dtmfEventTone returns the DTMF tone of a telephone event code, or "" if it isn't a DTMF tone
+func dtmfEventTone(event uint8) string {
+	if int(event) >= len(dtmfTones) {
+		return ""
+	}
+	return dtmfTones[event : event+1]
+}
+
+// telephoneEventUnits converts a duration to units of the clock rate of a telephone event
+func telephoneEventUnits(duration time.Duration, clockRate uint32) uint64 {
+	return uint64(duration) * uint64(clockRate) / uint64(time.Second)
+}
+
+// telephoneEventDuration converts units of the clock rate of a telephone event to a duration
+func telephoneEventDuration(units uint64, clockRate uint32) time.Duration {
+	if clockRate == 0 {
+		return 0
+	}
+	return time.Duration(units * uint64(time.Second) / uint64(clockRate))
+}
@@ -1,6 +1,9 @@
 package webrtc
 
-import "github.com/pion/rtp"
+import (
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/headerextension"
+)
 
 // TrackLocalWriter is the Writer for outbound RTP Packets
 type TrackLocalWriter interface {
@@ -13,10 +16,11 @@ type TrackLocalWriter interface {
 
 // TrackLocalContext is the Context passed when a TrackLocal has been Binded/Unbinded from a PeerConnection
 type TrackLocalContext struct {
-	id          string
-	codecs      []RTPCodecParameters
-	ssrc        SSRC
-	writeStream TrackLocalWriter
+	id               string
+	codecs           []RTPCodecParameters
+	headerExtensions []RTPHeaderExtensionParameter
+	ssrc             SSRC
+	writeStream      TrackLocalWriter
 }
 
 // CodecParameters returns the negotiated RTPCodecParameters. These are the codecs supported by both
@@ -25,8 +29,20 @@ func (t *TrackLocalContext) CodecParameters() []RTPCodecParameters {
 	return t.codecs
 }
 
+// HeaderExtensions returns the negotiated RTPHeaderExtensionParameters, the header extensions the
+// packets written to the WriteStream may carry
+func (t *TrackLocalContext) HeaderExtensions() []RTPHeaderExtensionParameter {
+	return t.headerExtensions
+}
+
+// SetHeaderExtension sets extension on header, with the ID negotiated for its URI. It returns
+// ErrHeaderExtensionNotNegotiated if the extension wasn't negotiated
+func (t *TrackLocalContext) SetHeaderExtension(header *rtp.Header, extension headerextension.Extension) error {
+	return setHeaderExtension(header, t.headerExtensions, extension)
+}
+
 // SSRC requires the negotiated SSRC of this track
-// This track may have multiple if RTX is enabled
+// Retransmissions (RTX) are sent by the RTPSender on their own SSRC and don't need to be handled by the track
 func (t *TrackLocalContext) SSRC() SSRC {
 	return t.ssrc
 }
@@ -3,22 +3,32 @@
 package webrtc
 
 import (
+	"errors"
 	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/pion/randutil"
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3/internal/util"
+	"github.com/pion/webrtc/v3/pkg/codecs/red"
+	"github.com/pion/webrtc/v3/pkg/headerextension"
 	"github.com/pion/webrtc/v3/pkg/media"
 )
 
+// redDistance is the number of previous packets sent as redundant blocks when sending RED
+const redDistance = 2
+
 // trackBinding is a single bind for a Track
 // Bind can be called multiple times, this stores the
 // result for a single bind call so that it can be used when writing
 type trackBinding struct {
-	id          string
-	ssrc        SSRC
-	payloadType PayloadType
-	writeStream TrackLocalWriter
+	id               string
+	ssrc             SSRC
+	codec            RTPCodecParameters
+	codecs           []RTPCodecParameters
+	headerExtensions []RTPHeaderExtensionParameter
+	writeStream      TrackLocalWriter
 }
 
 // TrackLocalStaticRTP  is a TrackLocal that has a pre-set codec and accepts RTP Packets.
@@ -27,13 +37,32 @@ type TrackLocalStaticRTP struct {
 	mu           sync.RWMutex
 	bindings     []trackBinding
 	codec        RTPCodecCapability
+	codecs       []RTPCodecCapability
 	id, streamID string
 }
 
 // NewTrackLocalStaticRTP returns a TrackLocalStaticRTP.
 func NewTrackLocalStaticRTP(c RTPCodecCapability, id, streamID string) (*TrackLocalStaticRTP, error) {
+	return NewTrackLocalStaticRTPWithCodecs([]RTPCodecCapability{c}, id, streamID)
+}
+
+// NewTrackLocalStaticRTPWithCodecs returns a TrackLocalStaticRTP that can be sent with any of the given codecs.
+// The codecs are in order of preference and must all be of the same kind. When the track is first bound
+// it is sent with the first codec accepted by the remote, Codec returns which one that is.
+func NewTrackLocalStaticRTPWithCodecs(codecs []RTPCodecCapability, id, streamID string) (*TrackLocalStaticRTP, error) {
+	if len(codecs) == 0 {
+		return nil, errTrackLocalStaticNoCodecs
+	}
+
+	for _, c := range codecs[1:] {
+		if codecKind(c) != codecKind(codecs[0]) {
+			return nil, errTrackLocalStaticCodecKindMismatch
+		}
+	}
+
 	return &TrackLocalStaticRTP{
-		codec:    c,
+		codec:    codecs[0],
+		codecs:   append([]RTPCodecCapability{}, codecs...),
 		bindings: []trackBinding{},
 		id:       id,
 		streamID: streamID,
@@ -47,13 +76,27 @@ func (s *TrackLocalStaticRTP) Bind(t TrackLocalContext) (RTPCodecParameters, err
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	parameters := RTPCodecParameters{RTPCodecCapability: s.codec}
-	if codec, err := codecParametersFuzzySearch(parameters, t.CodecParameters()); err == nil {
+	// Every binding is sent the same packets, so once bound the codec
+	// is only changed with SetCodec
+	candidates := s.codecs
+	if len(s.bindings) != 0 {
+		candidates = []RTPCodecCapability{s.codec}
+	}
+
+	for _, c := range candidates {
+		codec, err := codecCapabilityFuzzySearch(c, t.CodecParameters())
+		if err != nil {
+			continue
+		}
+
+		s.codec = c
 		s.bindings = append(s.bindings, trackBinding{
-			ssrc:        t.SSRC(),
-			payloadType: codec.PayloadType,
-			writeStream: t.WriteStream(),
-			id:          t.ID(),
+			ssrc:             t.SSRC(),
+			codec:            codec,
+			codecs:           t.CodecParameters(),
+			headerExtensions: t.HeaderExtensions(),
+			writeStream:      t.WriteStream(),
+			id:               t.ID(),
 		})
 		return codec, nil
 	}
@@ -78,6 +121,75 @@ func (s *TrackLocalStaticRTP) Unbind(t TrackLocalContext) error {
 	return ErrUnbindFailed
 }
 
+// Codec returns the codec the track is currently sent with. Written packets must be of this codec
+func (s *TrackLocalStaticRTP) Codec() RTPCodecCapability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.codec
+}
+
+// Codecs returns the codecs the track can be sent with, in order of preference
+func (s *TrackLocalStaticRTP) Codecs() []RTPCodecCapability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]RTPCodecCapability{}, s.codecs...)
+}
+
+// BoundCodec returns the codec, with the payload type negotiated by the remote, the track is sent
+// with by the binding using the given SSRC. The second return value is false if there is no such binding
+func (s *TrackLocalStaticRTP) BoundCodec(ssrc SSRC) (RTPCodecParameters, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, b := range s.bindings {
+		if b.ssrc == ssrc {
+			return b.codec, true
+		}
+	}
+
+	return RTPCodecParameters{}, false
+}
+
+// SetCodec switches the codec the track is sent with, without renegotiation. The codec must be one of
+// the codecs of the track, and have been negotiated by every PeerConnection the track is bound to,
+// otherwise ErrUnsupportedCodec is returned and the codec isn't changed.
+// Packets written after SetCodec returns must be of the new codec.
+func (s *TrackLocalStaticRTP) SetCodec(c RTPCodecCapability) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.setCodec(c)
+}
+
+// setCodec switches every binding to c. s.mu must be held
+func (s *TrackLocalStaticRTP) setCodec(c RTPCodecCapability) error {
+	declared := make([]RTPCodecParameters, 0, len(s.codecs))
+	for _, codec := range s.codecs {
+		declared = append(declared, RTPCodecParameters{RTPCodecCapability: codec})
+	}
+
+	codec, err := codecParametersFuzzySearch(RTPCodecParameters{RTPCodecCapability: c}, declared)
+	if err != nil {
+		return ErrUnsupportedCodec
+	}
+
+	negotiated := make([]RTPCodecParameters, len(s.bindings))
+	for i := range s.bindings {
+		if negotiated[i], err = codecCapabilityFuzzySearch(codec.RTPCodecCapability, s.bindings[i].codecs); err != nil {
+			return ErrUnsupportedCodec
+		}
+	}
+
+	for i := range s.bindings {
+		s.bindings[i].codec = negotiated[i]
+	}
+	s.codec = codec.RTPCodecCapability
+
+	return nil
+}
+
 // ID is the unique identifier for this Track. This should be unique for the
 // stream, but doesn't have to globally unique. A common example would be 'audio' or 'video'
 // and StreamID would be 'desktop' or 'webcam'
@@ -88,29 +200,57 @@ func (s *TrackLocalStaticRTP) StreamID() string { return s.streamID }
 
 // Kind controls if this TrackLocal is audio or video
 func (s *TrackLocalStaticRTP) Kind() RTPCodecType {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return codecKind(s.codec)
+}
+
+func codecKind(c RTPCodecCapability) RTPCodecType {
 	switch {
-	case strings.HasPrefix(s.codec.MimeType, "audio/"):
+	case strings.HasPrefix(c.MimeType, "audio/"):
 		return RTPCodecTypeAudio
-	case strings.HasPrefix(s.codec.MimeType, "video/"):
+	case strings.HasPrefix(c.MimeType, "video/"):
 		return RTPCodecTypeVideo
 	default:
 		return RTPCodecType(0)
 	}
 }
 
-// WriteRTP writes a RTP Packet to the TrackLocalStaticRTP
+// WriteRTP writes a RTP Packet to the TrackLocalStaticRTP, p isn't modified
 // If one PeerConnection fails the packets will still be sent to
 // all PeerConnections. The error message will contain the ID of the failed
 // PeerConnections so you can remove them
 func (s *TrackLocalStaticRTP) WriteRTP(p *rtp.Packet) error {
+	return s.writeRTP(p, nil, func(trackBinding) []byte { return p.Payload })
+}
+
+// WriteRTPWithHeaderExtensions writes a RTP Packet to the TrackLocalStaticRTP, carrying the given
+// header extensions. They are set with the IDs negotiated by each PeerConnection, and left out
+// for the PeerConnections that didn't negotiate them. p isn't modified
+func (s *TrackLocalStaticRTP) WriteRTPWithHeaderExtensions(p *rtp.Packet, extensions ...headerextension.Extension) error {
+	return s.writeRTP(p, extensions, func(trackBinding) []byte { return p.Payload })
+}
+
+// writeRTP writes p to every binding, carrying extensions, with the payload returned by payload for the binding
+func (s *TrackLocalStaticRTP) writeRTP(p *rtp.Packet, extensions []headerextension.Extension, payload func(trackBinding) []byte) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	writeErrs := []error{}
 	for _, b := range s.bindings {
-		p.Header.SSRC = uint32(b.ssrc)
-		p.Header.PayloadType = uint8(b.payloadType)
-		if _, err := b.writeStream.WriteRTP(&p.Header, p.Payload); err != nil {
+		// The SSRC and PayloadType differ between bindings, they are set on a copy so p isn't modified
+		header := p.Header
+		header.SSRC = uint32(b.ssrc)
+		header.PayloadType = uint8(b.codec.PayloadType)
+
+		withExtensions, err := b.headerWithExtensions(&header, extensions)
+		if err != nil {
+			writeErrs = append(writeErrs, err)
+			continue
+		}
+
+		if _, err = b.writeStream.WriteRTP(withExtensions, payload(b)); err != nil {
 			writeErrs = append(writeErrs, err)
 		}
 	}
@@ -118,6 +258,23 @@ func (s *TrackLocalStaticRTP) WriteRTP(p *rtp.Packet) error {
 	return util.FlattenErrs(writeErrs)
 }
 
+// headerWithExtensions returns a copy of header carrying the extensions negotiated by the binding
+func (b *trackBinding) headerWithExtensions(header *rtp.Header, extensions []headerextension.Extension) (*rtp.Header, error) {
+	if len(extensions) == 0 {
+		return header, nil
+	}
+
+	h := *header
+	h.Extensions = append([]rtp.Extension{}, header.Extensions...)
+	for _, extension := range extensions {
+		if err := setHeaderExtension(&h, b.headerExtensions, extension); err != nil && !errors.Is(err, ErrHeaderExtensionNotNegotiated) {
+			return nil, err
+		}
+	}
+
+	return &h, nil
+}
+
 // Write writes a RTP Packet as a buffer to the TrackLocalStaticRTP
 // If one PeerConnection fails the packets will still be sent to
 // all PeerConnections. The error message will contain the ID of the failed
@@ -134,20 +291,38 @@ func (s *TrackLocalStaticRTP) Write(b []byte) (n int, err error) {
 // TrackLocalStaticSample is a TrackLocal that has a pre-set codec and accepts Samples.
 // If you wish to send a RTP Packet use TrackLocalStaticRTP
 type TrackLocalStaticSample struct {
-	packetizer rtp.Packetizer
-	rtpTrack   *TrackLocalStaticRTP
-	clockRate  float64
+	packetizer      rtp.Packetizer
+	packetizerCodec RTPCodecCapability
+	sequencer       rtp.Sequencer
+	rtpTrack        *TrackLocalStaticRTP
+	clockRate       float64
+
+	// timestamp is the timestamp of the next sample. It is kept by the track rather than
+	// the packetizer so it carries on when SetCodec replaces the packetizer
+	timestamp uint32
+
+	// redundancy holds the previous packets, sent as redundant blocks when sending RED
+	redundancy   []*rtp.Packet
+	redundancyMu sync.Mutex
 }
 
 // NewTrackLocalStaticSample returns a TrackLocalStaticSample
 func NewTrackLocalStaticSample(c RTPCodecCapability, id, streamID string) (*TrackLocalStaticSample, error) {
-	rtpTrack, err := NewTrackLocalStaticRTP(c, id, streamID)
+	return NewTrackLocalStaticSampleWithCodecs([]RTPCodecCapability{c}, id, streamID)
+}
+
+// NewTrackLocalStaticSampleWithCodecs returns a TrackLocalStaticSample that can be sent with any of the given codecs.
+// The codecs are in order of preference and must all be of the same kind. When the track is first bound
+// it is sent with the first codec accepted by the remote, Codec returns which one that is.
+func NewTrackLocalStaticSampleWithCodecs(codecs []RTPCodecCapability, id, streamID string) (*TrackLocalStaticSample, error) {
+	rtpTrack, err := NewTrackLocalStaticRTPWithCodecs(codecs, id, streamID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TrackLocalStaticSample{
-		rtpTrack: rtpTrack,
+		rtpTrack:  rtpTrack,
+		timestamp: randutil.NewMathRandomGenerator().Uint32(),
 	}, nil
 }
 
@@ -162,6 +337,18 @@ func (s *TrackLocalStaticSample) StreamID() string { return s.rtpTrack.StreamID(
 // Kind controls if this TrackLocal is audio or video
 func (s *TrackLocalStaticSample) Kind() RTPCodecType { return s.rtpTrack.Kind() }
 
+// Codec returns the codec the track is currently sent with. Written samples must be of this codec
+func (s *TrackLocalStaticSample) Codec() RTPCodecCapability { return s.rtpTrack.Codec() }
+
+// Codecs returns the codecs the track can be sent with, in order of preference
+func (s *TrackLocalStaticSample) Codecs() []RTPCodecCapability { return s.rtpTrack.Codecs() }
+
+// BoundCodec returns the codec, with the payload type negotiated by the remote, the track is sent
+// with by the binding using the given SSRC. The second return value is false if there is no such binding
+func (s *TrackLocalStaticSample) BoundCodec(ssrc SSRC) (RTPCodecParameters, bool) {
+	return s.rtpTrack.BoundCodec(ssrc)
+}
+
 // Bind is called by the PeerConnection after negotiation is complete
 // This asserts that the code requested is supported by the remote peer.
 // If so it setups all the state (SSRC and PayloadType) to have a call
@@ -174,14 +361,53 @@ func (s *TrackLocalStaticSample) Bind(t TrackLocalContext) (RTPCodecParameters,
 	s.rtpTrack.mu.Lock()
 	defer s.rtpTrack.mu.Unlock()
 
-	// We only need one packetizer
-	if s.packetizer != nil {
-		return codec, nil
+	return codec, s.updatePacketizer()
+}
+
+// Unbind implements the teardown logic when the track is no longer needed. This happens
+// because a track has been stopped.
+func (s *TrackLocalStaticSample) Unbind(t TrackLocalContext) error {
+	return s.rtpTrack.Unbind(t)
+}
+
+// SetCodec switches the codec the track is sent with, without renegotiation. The codec must be one of
+// the codecs of the track, and have been negotiated by every PeerConnection the track is bound to,
+// otherwise ErrUnsupportedCodec is returned and the codec isn't changed.
+// Samples written after SetCodec returns must be of the new codec.
+func (s *TrackLocalStaticSample) SetCodec(c RTPCodecCapability) error {
+	if _, err := payloaderForCodec(c); err != nil {
+		return err
 	}
 
-	payloader, err := payloaderForCodec(codec.RTPCodecCapability)
+	s.rtpTrack.mu.Lock()
+	defer s.rtpTrack.mu.Unlock()
+
+	if err := s.rtpTrack.setCodec(c); err != nil {
+		return err
+	}
+
+	// The packetizer is created once the track is bound
+	if s.packetizer == nil {
+		return nil
+	}
+	return s.updatePacketizer()
+}
+
+// updatePacketizer creates a packetizer for the codec of the track if it doesn't have one for it yet.
+// The sequence numbers and timestamps carry on from the previous packetizer. s.rtpTrack.mu must be held
+func (s *TrackLocalStaticSample) updatePacketizer() error {
+	codec := s.rtpTrack.codec
+	if s.packetizer != nil && strings.EqualFold(s.packetizerCodec.MimeType, codec.MimeType) && s.packetizerCodec.ClockRate == codec.ClockRate {
+		return nil
+	}
+
+	payloader, err := payloaderForCodec(codec)
 	if err != nil {
-		return codec, err
+		return err
+	}
+
+	if s.sequencer == nil {
+		s.sequencer = rtp.NewRandomSequencer()
 	}
 
 	s.packetizer = rtp.NewPacketizer(
@@ -189,17 +415,12 @@ func (s *TrackLocalStaticSample) Bind(t TrackLocalContext) (RTPCodecParameters,
 		0, // Value is handled when writing
 		0, // Value is handled when writing
 		payloader,
-		rtp.NewRandomSequencer(),
+		s.sequencer,
 		codec.ClockRate,
 	)
-	s.clockRate = float64(codec.RTPCodecCapability.ClockRate)
-	return codec, nil
-}
-
-// Unbind implements the teardown logic when the track is no longer needed. This happens
-// because a track has been stopped.
-func (s *TrackLocalStaticSample) Unbind(t TrackLocalContext) error {
-	return s.rtpTrack.Unbind(t)
+	s.packetizerCodec = codec
+	s.clockRate = float64(codec.ClockRate)
+	return nil
 }
 
 // WriteSample writes a Sample to the TrackLocalStaticSample
@@ -207,24 +428,86 @@ func (s *TrackLocalStaticSample) Unbind(t TrackLocalContext) error {
 // all PeerConnections. The error message will contain the ID of the failed
 // PeerConnections so you can remove them
 func (s *TrackLocalStaticSample) WriteSample(sample media.Sample) error {
+	return s.writeSample(sample, nil)
+}
+
+// WriteSampleWithHeaderExtensions writes a Sample to the TrackLocalStaticSample, every packet of the
+// sample carries the given header extensions. They are set with the IDs negotiated by each
+// PeerConnection, and left out for the PeerConnections that didn't negotiate them
+func (s *TrackLocalStaticSample) WriteSampleWithHeaderExtensions(sample media.Sample, extensions ...headerextension.Extension) error {
+	return s.writeSample(sample, extensions)
+}
+
+func (s *TrackLocalStaticSample) writeSample(sample media.Sample, extensions []headerextension.Extension) error {
 	s.rtpTrack.mu.RLock()
 	p := s.packetizer
 	clockRate := s.clockRate
+	isRED := strings.EqualFold(s.packetizerCodec.MimeType, mimeTypeRED)
 	s.rtpTrack.mu.RUnlock()
 
 	if p == nil {
 		return nil
 	}
 
-	samples := sample.Duration.Seconds() * clockRate
-	packets := p.(rtp.Packetizer).Packetize(sample.Data, uint32(samples))
+	samples := uint32(sample.Duration.Seconds() * clockRate)
+	timestamp := atomic.AddUint32(&s.timestamp, samples) - samples
+	packets := p.(rtp.Packetizer).Packetize(sample.Data, samples)
+	for _, p := range packets {
+		p.Timestamp = timestamp
+	}
 
 	writeErrs := []error{}
 	for _, p := range packets {
-		if err := s.rtpTrack.WriteRTP(p); err != nil {
+		var err error
+		if isRED {
+			err = s.writeRED(p, extensions)
+		} else {
+			err = s.rtpTrack.writeRTP(p, extensions, func(trackBinding) []byte { return p.Payload })
+		}
+		if err != nil {
 			writeErrs = append(writeErrs, err)
 		}
 	}
 
 	return util.FlattenErrs(writeErrs)
 }
+
+// writeRED writes p in the RED payload format, with the previous packets as redundant blocks.
+// Only the packets right before p are added, so the remote can tell their sequence numbers
+func (s *TrackLocalStaticSample) writeRED(p *rtp.Packet, extensions []headerextension.Extension) error {
+	s.redundancyMu.Lock()
+	redundancy := s.redundancy
+	s.redundancy = append([]*rtp.Packet{}, redundancy...)
+	if len(s.redundancy) == redDistance {
+		s.redundancy = s.redundancy[1:]
+	}
+	s.redundancy = append(s.redundancy, p)
+	s.redundancyMu.Unlock()
+
+	for i := len(redundancy) - 1; i >= 0; i-- {
+		previous := redundancy[i]
+		if previous.SequenceNumber+uint16(len(redundancy)-i) != p.SequenceNumber ||
+			p.Timestamp-previous.Timestamp > red.MaxTimestampOffset || len(previous.Payload) > red.MaxBlockLength {
+			redundancy = redundancy[i+1:]
+			break
+		}
+	}
+
+	return s.rtpTrack.writeRTP(p, extensions, func(b trackBinding) []byte {
+		primary, _ := redPrimaryPayloadType(b.codec.RTPCodecCapability)
+
+		blocks := make([]red.Block, 0, len(redundancy)+1)
+		for _, previous := range redundancy {
+			blocks = append(blocks, red.Block{
+				PayloadType:     uint8(primary),
+				TimestampOffset: uint16(p.Timestamp - previous.Timestamp),
+				Payload:         previous.Payload,
+			})
+		}
+		blocks = append(blocks, red.Block{PayloadType: uint8(primary), Payload: p.Payload})
+
+		// The blocks can't be too large, they were checked above
+		payload, _ := red.Marshal(blocks)
+		return payload
+	})
+}
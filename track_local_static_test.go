@@ -0,0 +1,221 @@
+// +build !js
+
+package webrtc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/codecs/red"
+	"github.com/pion/webrtc/v3/pkg/headerextension"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTrackLocalWriter struct {
+	headers  []rtp.Header
+	payloads [][]byte
+}
+
+func (r *recordingTrackLocalWriter) WriteRTP(header *rtp.Header, payload []byte) (int, error) {
+	r.headers = append(r.headers, *header)
+	r.payloads = append(r.payloads, append([]byte{}, payload...))
+	return len(payload), nil
+}
+
+func (r *recordingTrackLocalWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func TestNewTrackLocalStaticRTPWithCodecs(t *testing.T) {
+	_, err := NewTrackLocalStaticRTPWithCodecs(nil, "video", "pion")
+	assert.Equal(t, errTrackLocalStaticNoCodecs, err)
+
+	_, err = NewTrackLocalStaticRTPWithCodecs([]RTPCodecCapability{{MimeType: mimeTypeVP8}, {MimeType: mimeTypeOpus}}, "video", "pion")
+	assert.Equal(t, errTrackLocalStaticCodecKindMismatch, err)
+
+	track, err := NewTrackLocalStaticRTPWithCodecs([]RTPCodecCapability{{MimeType: mimeTypeH264}, {MimeType: mimeTypeVP8}}, "video", "pion")
+	assert.NoError(t, err)
+	assert.Equal(t, RTPCodecTypeVideo, track.Kind())
+	assert.Equal(t, mimeTypeH264, track.Codec().MimeType)
+	assert.Equal(t, []RTPCodecCapability{{MimeType: mimeTypeH264}, {MimeType: mimeTypeVP8}}, track.Codecs())
+}
+
+func TestTrackLocalStaticRTP_BindH264WithoutFmtp(t *testing.T) {
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+
+	track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: mimeTypeH264, ClockRate: 90000}, "video", "pion")
+	assert.NoError(t, err)
+
+	// The H264 payloader sends FU-A and STAP-A packets, so the track is bound with
+	// packetization-mode=1 rather than the packetization-mode=0 an empty fmtp stands for
+	codec, err := track.Bind(TrackLocalContext{id: "a", ssrc: 5000, codecs: m.getCodecsByKind(RTPCodecTypeVideo), writeStream: &recordingTrackLocalWriter{}})
+	assert.NoError(t, err)
+	assert.Equal(t, PayloadType(102), codec.PayloadType)
+}
+
+func TestTrackLocalStaticSample_SetCodec(t *testing.T) {
+	vp8 := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeVP8, ClockRate: 90000}, PayloadType: 96}
+	h264 := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeH264, ClockRate: 90000, SDPFmtpLine: "packetization-mode=1"}, PayloadType: 102}
+
+	track, err := NewTrackLocalStaticSampleWithCodecs([]RTPCodecCapability{
+		{MimeType: mimeTypeH264, ClockRate: 90000},
+		{MimeType: mimeTypeVP8, ClockRate: 90000},
+		{MimeType: mimeTypeVP9, ClockRate: 90000},
+	}, "video", "pion")
+	assert.NoError(t, err)
+
+	writer := &recordingTrackLocalWriter{}
+	codec, err := track.Bind(TrackLocalContext{id: "a", ssrc: 5000, codecs: []RTPCodecParameters{vp8, h264}, writeStream: writer})
+	assert.NoError(t, err)
+	assert.Equal(t, h264, codec)
+	assert.Equal(t, mimeTypeH264, track.Codec().MimeType)
+
+	bound, ok := track.BoundCodec(5000)
+	assert.True(t, ok)
+	assert.Equal(t, h264, bound)
+
+	_, ok = track.BoundCodec(5001)
+	assert.False(t, ok)
+
+	// Once bound other PeerConnections must accept the codec the track is sent with
+	_, err = track.Bind(TrackLocalContext{id: "b", ssrc: 5001, codecs: []RTPCodecParameters{vp8}, writeStream: writer})
+	assert.True(t, errors.Is(err, ErrUnsupportedCodec))
+
+	assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0x65, 0x00}, Duration: time.Second}))
+
+	// Not negotiated, or not a codec of the track
+	assert.True(t, errors.Is(track.SetCodec(RTPCodecCapability{MimeType: mimeTypeVP9, ClockRate: 90000}), ErrUnsupportedCodec))
+	assert.True(t, errors.Is(track.SetCodec(RTPCodecCapability{MimeType: mimeTypeAV1, ClockRate: 90000}), ErrUnsupportedCodec))
+	assert.Equal(t, mimeTypeH264, track.Codec().MimeType)
+
+	assert.NoError(t, track.SetCodec(RTPCodecCapability{MimeType: mimeTypeVP8, ClockRate: 90000}))
+	assert.Equal(t, mimeTypeVP8, track.Codec().MimeType)
+
+	bound, ok = track.BoundCodec(5000)
+	assert.True(t, ok)
+	assert.Equal(t, vp8, bound)
+
+	assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0x00, 0x01}, Duration: time.Second}))
+
+	assert.Equal(t, 2, len(writer.headers))
+	assert.Equal(t, uint8(102), writer.headers[0].PayloadType)
+	assert.Equal(t, uint8(96), writer.headers[1].PayloadType)
+	assert.Equal(t, uint32(5000), writer.headers[1].SSRC)
+	assert.Equal(t, writer.headers[0].SequenceNumber+1, writer.headers[1].SequenceNumber)
+
+	// The first sample lasted a second, the timestamps carry on across the codec switch
+	assert.Equal(t, writer.headers[0].Timestamp+90000, writer.headers[1].Timestamp)
+
+	// Once unbound the track is bound with the codec preferred again
+	assert.NoError(t, track.Unbind(TrackLocalContext{id: "a"}))
+	codec, err = track.Bind(TrackLocalContext{id: "c", ssrc: 5002, codecs: []RTPCodecParameters{vp8, h264}, writeStream: writer})
+	assert.NoError(t, err)
+	assert.Equal(t, h264, codec)
+}
+
+func TestTrackLocalStaticSample_RED(t *testing.T) {
+	opus := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeOpus, ClockRate: 48000, Channels: 2}, PayloadType: 109}
+	redCodec := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeRED, ClockRate: 48000, Channels: 2, SDPFmtpLine: "109/109"}, PayloadType: 63}
+
+	track, err := NewTrackLocalStaticSampleWithCodecs([]RTPCodecCapability{
+		{MimeType: mimeTypeRED, ClockRate: 48000, Channels: 2},
+		{MimeType: mimeTypeOpus, ClockRate: 48000, Channels: 2},
+	}, "audio", "pion")
+	assert.NoError(t, err)
+
+	writer := &recordingTrackLocalWriter{}
+	codec, err := track.Bind(TrackLocalContext{id: "a", ssrc: 5000, codecs: []RTPCodecParameters{opus, redCodec}, writeStream: writer})
+	assert.NoError(t, err)
+	assert.Equal(t, redCodec, codec)
+
+	for _, frame := range [][]byte{{0x01}, {0x02, 0x02}, {0x03}, {0x04}} {
+		assert.NoError(t, track.WriteSample(media.Sample{Data: frame, Duration: 20 * time.Millisecond}))
+	}
+
+	assert.Equal(t, 4, len(writer.payloads))
+	for i, expected := range [][]red.Block{
+		{{PayloadType: 109, Payload: []byte{0x01}}},
+		{{PayloadType: 109, TimestampOffset: 960, Payload: []byte{0x01}}, {PayloadType: 109, Payload: []byte{0x02, 0x02}}},
+		{{PayloadType: 109, TimestampOffset: 1920, Payload: []byte{0x01}}, {PayloadType: 109, TimestampOffset: 960, Payload: []byte{0x02, 0x02}}, {PayloadType: 109, Payload: []byte{0x03}}},
+		{{PayloadType: 109, TimestampOffset: 1920, Payload: []byte{0x02, 0x02}}, {PayloadType: 109, TimestampOffset: 960, Payload: []byte{0x03}}, {PayloadType: 109, Payload: []byte{0x04}}},
+	} {
+		assert.Equal(t, uint8(63), writer.headers[i].PayloadType)
+
+		blocks, err := red.Unmarshal(writer.payloads[i])
+		assert.NoError(t, err)
+		assert.Equal(t, expected, blocks)
+	}
+
+	// Without RED negotiated Opus is sent
+	assert.NoError(t, track.Unbind(TrackLocalContext{id: "a"}))
+	codec, err = track.Bind(TrackLocalContext{id: "b", ssrc: 5001, codecs: []RTPCodecParameters{opus}, writeStream: writer})
+	assert.NoError(t, err)
+	assert.Equal(t, opus, codec)
+
+	assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{0x05}, Duration: 20 * time.Millisecond}))
+	assert.Equal(t, uint8(109), writer.headers[4].PayloadType)
+	assert.Equal(t, []byte{0x05}, writer.payloads[4])
+}
+
+func TestTrackLocalStaticRTP_HeaderExtensions(t *testing.T) {
+	vp8 := RTPCodecParameters{RTPCodecCapability: RTPCodecCapability{MimeType: mimeTypeVP8, ClockRate: 90000}, PayloadType: 96}
+
+	track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: mimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	writerA, writerB := &recordingTrackLocalWriter{}, &recordingTrackLocalWriter{}
+	_, err = track.Bind(TrackLocalContext{id: "a", ssrc: 5000, codecs: []RTPCodecParameters{vp8}, writeStream: writerA, headerExtensions: []RTPHeaderExtensionParameter{
+		{URI: headerextension.VideoOrientationURI, ID: 3},
+		{URI: headerextension.ColorSpaceURI, ID: 4},
+	}})
+	assert.NoError(t, err)
+	_, err = track.Bind(TrackLocalContext{id: "b", ssrc: 5001, codecs: []RTPCodecParameters{vp8}, writeStream: writerB, headerExtensions: []RTPHeaderExtensionParameter{
+		{URI: headerextension.VideoOrientationURI, ID: 7},
+	}})
+	assert.NoError(t, err)
+
+	orientation := &headerextension.VideoOrientation{Rotation: 90}
+	colorSpace := &headerextension.ColorSpace{Primaries: 1, HDRMetadata: &headerextension.HDRMetadata{LuminanceMax: 1000}}
+
+	packet := &rtp.Packet{Header: rtp.Header{Version: 2, SequenceNumber: 1}, Payload: []byte{0x00}}
+	assert.NoError(t, track.WriteRTPWithHeaderExtensions(packet, orientation))
+	assert.NoError(t, track.WriteRTPWithHeaderExtensions(packet, orientation, colorSpace))
+	assert.Equal(t, rtp.Header{Version: 2, SequenceNumber: 1}, packet.Header)
+
+	// Each binding is written its own SSRC and PayloadType
+	assert.Equal(t, uint32(5000), writerA.headers[0].SSRC)
+	assert.Equal(t, uint32(5001), writerB.headers[0].SSRC)
+	assert.Equal(t, uint8(96), writerB.headers[0].PayloadType)
+
+	_, err = track.Bind(TrackLocalContext{id: "c", ssrc: 5002, codecs: []RTPCodecParameters{vp8}, writeStream: &recordingTrackLocalWriter{}, headerExtensions: []RTPHeaderExtensionParameter{
+		{URI: headerextension.VideoOrientationURI, ID: 8},
+	}})
+	assert.NoError(t, err)
+	assert.Error(t, track.WriteRTPWithHeaderExtensions(packet, &headerextension.VideoOrientation{Rotation: 45}))
+
+	// The one-byte header is used unless an extension doesn't fit it
+	assert.Equal(t, uint16(rtpExtensionProfileOneByte), writerA.headers[0].ExtensionProfile)
+	assert.Equal(t, []byte{0x01}, writerA.headers[0].GetExtension(3))
+	assert.Equal(t, uint16(rtpExtensionProfileTwoByte), writerA.headers[1].ExtensionProfile)
+	assert.Equal(t, []byte{0x01}, writerA.headers[1].GetExtension(3))
+
+	parsed := &headerextension.ColorSpace{}
+	ok, err := getHeaderExtension(&writerA.headers[1], []RTPHeaderExtensionParameter{{URI: headerextension.ColorSpaceURI, ID: 4}}, parsed)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, colorSpace, parsed)
+
+	// The color space wasn't negotiated by b
+	for _, header := range writerB.headers {
+		assert.Equal(t, uint16(rtpExtensionProfileOneByte), header.ExtensionProfile)
+		assert.Equal(t, []byte{0x01}, header.GetExtension(7))
+		assert.Nil(t, header.GetExtension(4))
+	}
+
+	_, err = getHeaderExtension(&writerB.headers[0], nil, parsed)
+	assert.Equal(t, ErrHeaderExtensionNotNegotiated, err)
+}
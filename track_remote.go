@@ -3,9 +3,14 @@
 package webrtc
 
 import (
+	"io"
+	"strings"
 	"sync"
 
 	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/codecs/red"
+	"github.com/pion/webrtc/v3/pkg/codecs/telephoneevent"
+	"github.com/pion/webrtc/v3/pkg/headerextension"
 )
 
 // TrackRemote represents a single inbound source of media
@@ -21,8 +26,26 @@ type TrackRemote struct {
 	codec       RTPCodecParameters
 	rid         string
 
-	receiver *RTPReceiver
-	peeked   []byte
+	receiver         *RTPReceiver
+	peeked           []byte
+	headerExtensions []RTPHeaderExtensionParameter
+
+	// Packets sent with RED are unwrapped, redPending holds the packets read but not returned yet
+	hasRED                bool
+	redPayloadType        PayloadType
+	redPending            [][]byte
+	redStarted            bool
+	redLastSequenceNumber uint16
+
+	// Telephone events aren't returned by Read, they are passed to onTelephoneEventHandler.
+	// telephoneEventPayloadTypes maps their payload types to their clock rate
+	telephoneEventPayloadTypes map[PayloadType]uint32
+	onTelephoneEventHandler    func(TelephoneEvent)
+	telephoneEventStarted      bool
+	telephoneEventEnded        bool
+	telephoneEvent             uint8
+	telephoneEventStart        uint32
+	telephoneEventTimestamp    uint32
 }
 
 // ID is the unique identifier for this Track. This should be unique for the
@@ -84,8 +107,52 @@ func (t *TrackRemote) Codec() RTPCodecParameters {
 	return t.codec
 }
 
+// HeaderExtensions returns the negotiated RTPHeaderExtensionParameters, the header extensions
+// the packets of the track may carry
+func (t *TrackRemote) HeaderExtensions() []RTPHeaderExtensionParameter {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.headerExtensions
+}
+
+// GetHeaderExtension parses extension from the header of a packet of the track, with the ID negotiated
+// for its URI. It returns false if the packet doesn't carry the extension, and
+// ErrHeaderExtensionNotNegotiated if the extension wasn't negotiated
+func (t *TrackRemote) GetHeaderExtension(header *rtp.Header, extension headerextension.Extension) (bool, error) {
+	return getHeaderExtension(header, t.HeaderExtensions(), extension)
+}
+
+// OnTelephoneEvent sets an event handler which is invoked when a telephone event, like a DTMF tone,
+// starts and when it ends. The packets of telephone events aren't returned by Read, the handler
+// is invoked while Read is called
+func (t *TrackRemote) OnTelephoneEvent(f func(TelephoneEvent)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onTelephoneEventHandler = f
+}
+
 // Read reads data from the track.
+// If the remote sends RED, the packets of the primary encoding are returned, along with the ones
+// recovered from the redundant encodings
 func (t *TrackRemote) Read(b []byte) (n int, err error) {
+	for {
+		t.mu.RLock()
+		hasRED := t.hasRED
+		t.mu.RUnlock()
+
+		if hasRED {
+			n, err = t.readRED(b)
+		} else {
+			n, err = t.read(b)
+		}
+
+		if err != nil || !t.readTelephoneEvent(b[:n]) {
+			return n, err
+		}
+	}
+}
+
+func (t *TrackRemote) read(b []byte) (n int, err error) {
 	t.mu.RLock()
 	r := t.receiver
 	peeked := t.peeked != nil
@@ -107,6 +174,208 @@ func (t *TrackRemote) Read(b []byte) (n int, err error) {
 	return r.readRTP(b, t)
 }
 
+// readRED reads a packet and unwraps it if it was sent with RED. The redundant encodings are only
+// returned for the packets that were lost, before the primary encoding. Malformed RED packets are dropped
+func (t *TrackRemote) readRED(b []byte) (int, error) {
+	t.mu.Lock()
+	if len(t.redPending) != 0 {
+		defer t.mu.Unlock()
+		return t.popREDPending(b)
+	}
+	t.mu.Unlock()
+
+	for {
+		n, err := t.read(b)
+		if err != nil {
+			return n, err
+		}
+
+		packet := &rtp.Packet{}
+		if err = packet.Unmarshal(b[:n]); err != nil {
+			continue
+		}
+
+		if packets, ok := t.unwrapRED(packet); !ok {
+			return n, nil
+		} else if len(packets) != 0 {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			t.redPending = append(t.redPending, packets...)
+			return t.popREDPending(b)
+		}
+	}
+}
+
+// popREDPending copies the first pending packet into b. It is kept if b is too short
+func (t *TrackRemote) popREDPending(b []byte) (int, error) {
+	data := t.redPending[0]
+	if len(b) < len(data) {
+		return 0, io.ErrShortBuffer
+	}
+
+	t.redPending = t.redPending[1:]
+	return copy(b, data), nil
+}
+
+// unwrapRED returns the marshaled packets to read from packet, the redundant encodings of the lost
+// packets and the primary encoding. ok is false if packet isn't sent with RED, and it is read as is.
+// No packet is returned if packet is a malformed RED packet
+func (t *TrackRemote) unwrapRED(packet *rtp.Packet) (packets [][]byte, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lost := func(sequenceNumber uint16) bool {
+		return t.redStarted && int16(sequenceNumber-t.redLastSequenceNumber) > 0
+	}
+	received := func(sequenceNumber uint16) {
+		if !t.redStarted || lost(sequenceNumber) {
+			t.redStarted = true
+			t.redLastSequenceNumber = sequenceNumber
+		}
+	}
+
+	if PayloadType(packet.PayloadType) != t.redPayloadType {
+		received(packet.SequenceNumber)
+		return nil, false
+	}
+
+	payload := packet.Payload
+	if packet.Padding {
+		if len(payload) == 0 || payload[len(payload)-1] == 0 || int(payload[len(payload)-1]) > len(payload) {
+			return nil, true
+		}
+		payload = payload[:len(payload)-int(payload[len(payload)-1])]
+	}
+
+	blocks, err := red.Unmarshal(payload)
+	if err != nil || len(blocks) == 0 {
+		return nil, true
+	}
+
+	packets = make([][]byte, 0, len(blocks))
+	for i, block := range blocks {
+		sequenceNumber := packet.SequenceNumber - uint16(len(blocks)-1-i)
+		if i != len(blocks)-1 && !lost(sequenceNumber) {
+			continue
+		}
+
+		// The padding of the RED packet doesn't belong to the encodings it carries
+		header := packet.Header
+		header.Padding = false
+		header.PayloadType = block.PayloadType
+		header.SequenceNumber = sequenceNumber
+		header.Timestamp = packet.Timestamp - uint32(block.TimestampOffset)
+
+		raw, err := (&rtp.Packet{Header: header, Payload: block.Payload}).Marshal()
+		if err != nil {
+			continue
+		}
+		packets = append(packets, raw)
+	}
+	received(packet.SequenceNumber)
+
+	return packets, true
+}
+
+// readTelephoneEvent returns false if packet isn't a telephone event. Otherwise it invokes the
+// onTelephoneEventHandler if the event started or ended, the following packets of an event only
+// update its duration
+// https://tools.ietf.org/html/rfc4733#section-2.5.2
+func (t *TrackRemote) readTelephoneEvent(packet []byte) bool {
+	if len(packet) < 2 {
+		return false
+	}
+
+	t.mu.Lock()
+	clockRate, ok := t.telephoneEventPayloadTypes[PayloadType(packet[1]&0x7f)]
+	if !ok {
+		t.mu.Unlock()
+		return false
+	}
+
+	p := &rtp.Packet{}
+	e := telephoneevent.Event{}
+	if err := p.Unmarshal(packet); err != nil || e.Unmarshal(p.Payload) != nil {
+		t.mu.Unlock()
+		return true
+	}
+
+	notify := false
+	switch {
+	case t.telephoneEventStarted && int32(p.Timestamp-t.telephoneEventTimestamp) < 0:
+		// A late packet of an earlier event
+	case !t.telephoneEventStarted || p.Timestamp != t.telephoneEventTimestamp:
+		// Long events continue with a new timestamp, without the marker bit
+		continued := t.telephoneEventStarted && !t.telephoneEventEnded && !p.Marker && e.Event == t.telephoneEvent
+		if !continued {
+			t.telephoneEventStart = p.Timestamp
+		}
+		t.telephoneEventStarted, t.telephoneEventEnded = true, false
+		t.telephoneEvent, t.telephoneEventTimestamp = e.Event, p.Timestamp
+		notify = !continued && !e.EndOfEvent
+	}
+
+	if p.Timestamp == t.telephoneEventTimestamp && e.EndOfEvent && !t.telephoneEventEnded {
+		t.telephoneEventEnded = true
+		notify = true
+	}
+
+	handler := t.onTelephoneEventHandler
+	duration := uint64(p.Timestamp-t.telephoneEventStart) + uint64(e.Duration)
+	t.mu.Unlock()
+
+	if notify && handler != nil {
+		handler(TelephoneEvent{
+			Event:      e.Event,
+			Tone:       dtmfEventTone(e.Event),
+			EndOfEvent: e.EndOfEvent,
+			Volume:     e.Volume,
+			Duration:   telephoneEventDuration(duration, clockRate),
+		})
+	}
+	return true
+}
+
+// setCodec sets the codec of the track, and the header extensions negotiated for its kind.
+// If the codec is RED, the track is read as its primary encoding
+func (t *TrackRemote) setCodec(codec RTPCodecParameters, mediaEngine *MediaEngine) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.headerExtensions = headerExtensionParameters(mediaEngine.negotiatedHeaderExtensionsForType(t.kind))
+
+	if primary, ok := redPrimaryPayloadType(codec.RTPCodecCapability); ok {
+		primaryCodec, err := mediaEngine.getCodecByPayload(primary)
+		if err != nil {
+			t.codec = codec
+			return
+		}
+
+		t.hasRED, t.redPayloadType = true, codec.PayloadType
+		t.payloadType, codec = primary, primaryCodec
+	} else {
+		// The remote may send RED later on
+		for _, c := range mediaEngine.getCodecsByKind(t.kind) {
+			if primary, ok := redPrimaryPayloadType(c.RTPCodecCapability); ok && primary == codec.PayloadType {
+				t.hasRED, t.redPayloadType = true, c.PayloadType
+				break
+			}
+		}
+	}
+
+	if !strings.EqualFold(codec.MimeType, mimeTypeTelephoneEvent) {
+		t.telephoneEventPayloadTypes = map[PayloadType]uint32{}
+		for _, c := range mediaEngine.getCodecsByKind(t.kind) {
+			if strings.EqualFold(c.MimeType, mimeTypeTelephoneEvent) {
+				t.telephoneEventPayloadTypes[c.PayloadType] = c.ClockRate
+			}
+		}
+	}
+
+	t.codec = codec
+}
+
 // peek is like Read, but it doesn't discard the packet read
 func (t *TrackRemote) peek(b []byte) (n int, err error) {
 	n, err = t.Read(b)
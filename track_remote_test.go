@@ -0,0 +1,198 @@
+// +build !js
+
+package webrtc
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/codecs/red"
+	"github.com/pion/webrtc/v3/pkg/codecs/telephoneevent"
+	"github.com/pion/webrtc/v3/pkg/interceptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackRemote_RED(t *testing.T) {
+	track := &TrackRemote{hasRED: true, redPayloadType: 63}
+
+	marshal := func(p *rtp.Packet) []byte {
+		raw, err := p.Marshal()
+		assert.NoError(t, err)
+		return raw
+	}
+	redPacket := func(sequenceNumber uint16, timestamp uint32, blocks []red.Block) []byte {
+		payload, err := red.Marshal(blocks)
+		assert.NoError(t, err)
+		return marshal(&rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 63, SequenceNumber: sequenceNumber, Timestamp: timestamp, SSRC: 5000}, Payload: payload})
+	}
+	opusPacket := func(sequenceNumber uint16, timestamp uint32, payload []byte) *rtp.Packet {
+		return &rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 111, SequenceNumber: sequenceNumber, Timestamp: timestamp, SSRC: 5000}, Payload: payload}
+	}
+	read := func() *rtp.Packet {
+		b := make([]byte, receiveMTU)
+		n, err := track.Read(b)
+		assert.NoError(t, err)
+
+		p := &rtp.Packet{}
+		assert.NoError(t, p.Unmarshal(b[:n]))
+		assert.Equal(t, uint8(111), p.PayloadType)
+		assert.Equal(t, uint32(5000), p.SSRC)
+		return opusPacket(p.SequenceNumber, p.Timestamp, p.Payload)
+	}
+
+	// The redundant encodings of the first packet are older than the track
+	track.peeked = redPacket(10, 9600, []red.Block{
+		{PayloadType: 111, TimestampOffset: 960, Payload: []byte{0x09}},
+		{PayloadType: 111, Payload: []byte{0x0A}},
+	})
+	assert.Equal(t, opusPacket(10, 9600, []byte{0x0A}), read())
+
+	// 11 and 12 were lost, and are recovered from 13
+	track.peeked = redPacket(13, 12480, []red.Block{
+		{PayloadType: 111, TimestampOffset: 1920, Payload: []byte{0x0B}},
+		{PayloadType: 111, TimestampOffset: 960, Payload: []byte{0x0C}},
+		{PayloadType: 111, Payload: []byte{0x0D}},
+	})
+	assert.Equal(t, opusPacket(11, 10560, []byte{0x0B}), read())
+	assert.Equal(t, opusPacket(12, 11520, []byte{0x0C}), read())
+	assert.Equal(t, opusPacket(13, 12480, []byte{0x0D}), read())
+
+	// 13 was received, only the primary encoding is returned
+	track.peeked = redPacket(14, 13440, []red.Block{
+		{PayloadType: 111, TimestampOffset: 960, Payload: []byte{0x0D}},
+		{PayloadType: 111, Payload: []byte{0x0E}},
+	})
+	assert.Equal(t, opusPacket(14, 13440, []byte{0x0E}), read())
+
+	// Packets not sent with RED are returned as is
+	track.peeked = marshal(opusPacket(15, 14400, []byte{0x0F}))
+	assert.Equal(t, opusPacket(15, 14400, []byte{0x0F}), read())
+}
+
+func TestTrackRemote_REDInvalid(t *testing.T) {
+	track := &TrackRemote{hasRED: true, redPayloadType: 63}
+
+	queue := [][]byte{}
+	receiver := &RTPReceiver{received: make(chan interface{})}
+	close(receiver.received)
+	receiver.tracks = []trackStreams{{
+		track: track,
+		rtpInterceptor: interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			if len(queue) == 0 {
+				return 0, a, io.EOF
+			}
+
+			n := copy(b, queue[0])
+			queue = queue[1:]
+			return n, a, nil
+		}),
+	}}
+	track.receiver = receiver
+
+	redPacket := func(sequenceNumber uint16, padding bool, payload []byte) []byte {
+		raw, err := (&rtp.Packet{Header: rtp.Header{Version: 2, Padding: padding, PayloadType: 63, SequenceNumber: sequenceNumber, SSRC: 5000}, Payload: payload}).Marshal()
+		assert.NoError(t, err)
+		return raw
+	}
+	blocks := func(b ...red.Block) []byte {
+		payload, err := red.Marshal(b)
+		assert.NoError(t, err)
+		return payload
+	}
+
+	queue = [][]byte{
+		// The header of the redundant encoding is cut, the packet is dropped
+		redPacket(10, false, []byte{0x80 | 111, 0x00}),
+		// Padded RED packet, the padding doesn't belong to the primary encoding
+		redPacket(11, true, append(blocks(red.Block{PayloadType: 111, Payload: []byte{0x0B}}), 0x00, 0x02)),
+		redPacket(14, false, blocks(
+			red.Block{PayloadType: 111, TimestampOffset: 1920, Payload: []byte{0x0C}},
+			red.Block{PayloadType: 111, TimestampOffset: 960, Payload: []byte{0x0D}},
+			red.Block{PayloadType: 111, Payload: []byte{0x0E}},
+		)),
+	}
+
+	b := make([]byte, receiveMTU)
+	n, err := track.Read(b)
+	assert.NoError(t, err)
+
+	p := &rtp.Packet{}
+	assert.NoError(t, p.Unmarshal(b[:n]))
+	assert.Equal(t, uint16(11), p.SequenceNumber)
+	assert.False(t, p.Padding)
+	assert.Equal(t, []byte{0x0B}, p.Payload)
+
+	for _, sequenceNumber := range []uint16{12, 13, 14} {
+		// Packets that don't fit are kept until they are read with a large enough buffer
+		if sequenceNumber != 12 {
+			_, err = track.Read(make([]byte, 1))
+			assert.Equal(t, io.ErrShortBuffer, err)
+		}
+
+		n, err = track.Read(b)
+		assert.NoError(t, err)
+		assert.NoError(t, p.Unmarshal(b[:n]))
+		assert.Equal(t, sequenceNumber, p.SequenceNumber)
+	}
+
+	_, err = track.Read(b)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTrackRemote_TelephoneEvent(t *testing.T) {
+	track := &TrackRemote{telephoneEventPayloadTypes: map[PayloadType]uint32{126: 8000}}
+
+	events := []TelephoneEvent{}
+	track.OnTelephoneEvent(func(e TelephoneEvent) {
+		events = append(events, e)
+	})
+
+	packet := func(marker bool, timestamp uint32, event telephoneevent.Event) []byte {
+		payload, err := event.Marshal()
+		assert.NoError(t, err)
+
+		raw, err := (&rtp.Packet{Header: rtp.Header{Version: 2, Marker: marker, PayloadType: 126, Timestamp: timestamp, SSRC: 5000}, Payload: payload}).Marshal()
+		assert.NoError(t, err)
+		return raw
+	}
+
+	// Other packets are left to Read
+	opus, err := (&rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 111, SSRC: 5000}, Payload: []byte{0x01}}).Marshal()
+	assert.NoError(t, err)
+	assert.False(t, track.readTelephoneEvent(opus))
+
+	// The handler is invoked when the event starts and when it ends, the end is sent three times
+	for _, p := range [][]byte{
+		packet(true, 1000, telephoneevent.Event{Event: 5, Volume: 10, Duration: 400}),
+		packet(false, 1000, telephoneevent.Event{Event: 5, Volume: 10, Duration: 800}),
+		packet(false, 1000, telephoneevent.Event{Event: 5, EndOfEvent: true, Volume: 10, Duration: 1200}),
+		packet(false, 1000, telephoneevent.Event{Event: 5, EndOfEvent: true, Volume: 10, Duration: 1200}),
+		packet(false, 1000, telephoneevent.Event{Event: 5, EndOfEvent: true, Volume: 10, Duration: 1200}),
+	} {
+		assert.True(t, track.readTelephoneEvent(p))
+	}
+	assert.Equal(t, []TelephoneEvent{
+		{Event: 5, Tone: "5", Volume: 10, Duration: 50 * time.Millisecond},
+		{Event: 5, Tone: "5", EndOfEvent: true, Volume: 10, Duration: 150 * time.Millisecond},
+	}, events)
+
+	// Late packets of an earlier event are dropped
+	events = nil
+	assert.True(t, track.readTelephoneEvent(packet(true, 500, telephoneevent.Event{Event: 4, Duration: 400})))
+	assert.Empty(t, events)
+
+	// A long event is split into segments, the duration covers all of them
+	for _, p := range [][]byte{
+		packet(true, 20000, telephoneevent.Event{Event: 11, Duration: 65535}),
+		packet(false, 20000+65535, telephoneevent.Event{Event: 11, Duration: 800}),
+		packet(false, 20000+65535, telephoneevent.Event{Event: 11, EndOfEvent: true, Duration: 1665}),
+	} {
+		assert.True(t, track.readTelephoneEvent(p))
+	}
+	assert.Equal(t, []TelephoneEvent{
+		{Event: 11, Tone: "#", Duration: 65535 * time.Second / 8000},
+		{Event: 11, Tone: "#", EndOfEvent: true, Duration: 8400 * time.Millisecond},
+	}, events)
+}